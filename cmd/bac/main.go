@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	goflag "flag"
 	"fmt"
 	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli"
@@ -9,6 +10,8 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/klog/v2"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
 var hiddenLogFlags = []string{
@@ -29,16 +32,25 @@ var hiddenLogFlags = []string{
 
 func main() {
 	if err := initGoFlags(); err != nil {
-		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(int(util.ExitUsage))
 	}
 	initPFlags()
 
+	// the first Ctrl-C cancels the context given to the commands so requests in flight are aborted cleanly; stopping
+	// the notifications restores the default handling, so a second Ctrl-C exits immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	rootCmd := cli.NewCmd()
-	if err := rootCmd.Execute(); err != nil {
-		klog.Errorf("ERROR: %v\n", err)
-		os.Exit(1)
-	}
+	// cobra has already printed the error to stderr, so we only need to map it to the exit code
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	klog.Flush()
+	os.Exit(util.GetExitCode(err))
 }
 
 // initGoFlags initializes the flag sets for klog.
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// TokenMode sends the token provided by flag, environment variable, or Secret as is
+	TokenMode = "token"
+	// StaticMode sends a Backstage external access static key, provided like the token for TokenMode
+	StaticMode = "static"
+	// JWTMode sends short-lived JWTs signed with a local private key, for Backstage external access of type jwks
+	JWTMode = "jwt"
+	// OIDCMode sends the access token cached by 'login', refreshing it as needed
+	OIDCMode = "oidc"
+
+	DEFAULT_JWT_ISSUER   = util.ApplicationName
+	DEFAULT_JWT_SUBJECT  = util.ApplicationName
+	DEFAULT_JWT_AUDIENCE = "backstage"
+	DEFAULT_JWT_LIFETIME = 5 * time.Minute
+
+	// Backstage rejects static external access keys shorter than this
+	minStaticKeyLength = 8
+)
+
+var (
+	Modes              = []string{TokenMode, StaticMode, JWTMode, OIDCMode}
+	DEFAULT_OIDC_SCOPE = []string{"openid", "profile", "email", "offline_access"}
+)
+
+// Options are the settings for each of the ways of authenticating with Backstage
+type Options struct {
+	Mode string
+
+	// JWT related
+	JWTKeyFile  string
+	JWTKeyID    string
+	JWTIssuer   string
+	JWTSubject  string
+	JWTAudience string
+	JWTLifetime time.Duration
+
+	// OIDC related
+	OIDCIssuer   string
+	OIDCClientID string
+	OIDCScopes   []string
+	// CacheDir is where 'login' stores tokens; defaults to the 'bac' directory under the user's config directory
+	CacheDir string
+	// HTTPClient is used to reach the identity provider; defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Mode:        TokenMode,
+		JWTIssuer:   DEFAULT_JWT_ISSUER,
+		JWTSubject:  DEFAULT_JWT_SUBJECT,
+		JWTAudience: DEFAULT_JWT_AUDIENCE,
+		JWTLifetime: DEFAULT_JWT_LIFETIME,
+		OIDCScopes:  DEFAULT_OIDC_SCOPE,
+	}
+}
+
+// NewTokenSource returns the source of the bearer tokens for requests to Backstage for the mode in opts, where token is
+// the token provided by flag, environment variable, or Secret.  Problems with the settings, like an unreadable key,
+// are reported here, while a missing or expired login is reported by the first call to Token.
+func NewTokenSource(ctx context.Context, token string, opts *Options) (oauth2.TokenSource, error) {
+	switch strings.ToLower(opts.Mode) {
+	case TokenMode, "":
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), nil
+	case StaticMode:
+		if len(token) < minStaticKeyLength || strings.IndexFunc(token, unicode.IsSpace) >= 0 {
+			return nil, util.NewUsageError("a Backstage static key must be at least %d characters without whitespace; set it with --backstage-token or --backstage-token-secret", minStaticKeyLength)
+		}
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), nil
+	case JWTMode:
+		return newJWTTokenSource(opts)
+	case OIDCMode:
+		return newOIDCTokenSource(ctx, opts)
+	default:
+		return nil, util.NewUsageError("unsupported Backstage auth mode %q; the supported modes are %s", opts.Mode, strings.Join(Modes, ", "))
+	}
+}
+
+func (o *Options) cacheDir() (string, error) {
+	if len(o.CacheDir) > 0 {
+		return o.CacheDir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, util.ApplicationName), nil
+}
+
+func (o *Options) httpClient() *http.Client {
+	if o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+)
+
+func TestNewTokenSource(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "not-pem.key")
+	if err := os.WriteFile(notPEM, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name     string
+		token    string
+		opts     Options
+		expected string
+		exitCode util.ExitCode
+	}{
+		{name: "default mode sends the token", token: "my-token", expected: "my-token"},
+		{name: "static key", token: "my-static-key", opts: Options{Mode: StaticMode}, expected: "my-static-key"},
+		{name: "static key too short", token: "short", opts: Options{Mode: StaticMode}, exitCode: util.ExitUsage},
+		{name: "static key with whitespace", token: "my static key", opts: Options{Mode: StaticMode}, exitCode: util.ExitUsage},
+		{name: "unknown mode", opts: Options{Mode: "basic"}, exitCode: util.ExitUsage},
+		{name: "jwt without a key", opts: Options{Mode: JWTMode}, exitCode: util.ExitUsage},
+		{name: "jwt key missing", opts: Options{Mode: JWTMode, JWTKeyFile: filepath.Join(dir, "missing.key")}, exitCode: util.ExitValidation},
+		{name: "jwt key not pem", opts: Options{Mode: JWTMode, JWTKeyFile: notPEM}, exitCode: util.ExitValidation},
+		{name: "oidc without an issuer", opts: Options{Mode: OIDCMode, OIDCClientID: "bac"}, exitCode: util.ExitUsage},
+		{name: "oidc not logged in", opts: Options{Mode: OIDCMode, OIDCIssuer: "https://idp.example.com", OIDCClientID: "bac", CacheDir: dir}, exitCode: util.ExitAuth},
+	} {
+		ts, err := NewTokenSource(context.Background(), tc.token, &tc.opts)
+		if err == nil {
+			var token string
+			if tok, tokErr := ts.Token(); tokErr == nil {
+				token = tok.AccessToken
+			} else {
+				err = tokErr
+			}
+			if err == nil {
+				common.AssertEqual(t, tc.expected, token)
+			}
+		}
+		if util.GetExitCode(err) != int(tc.exitCode) {
+			t.Errorf("%s: expected exit code %d but got %d: %v", tc.name, tc.exitCode, util.GetExitCode(err), err)
+		}
+	}
+}
+
+func TestJWTTokenSource(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		block   *pem.Block
+		key     crypto.Signer
+		alg     jose.SignatureAlgorithm
+		keyID   string
+		subject string
+	}{
+		{name: "rsa pkcs8", block: &pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}, key: rsaKey, alg: jose.RS256},
+		{name: "ec sec1 with key id and subject", block: &pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}, key: ecKey, alg: jose.ES256, keyID: "my-key", subject: "ci-pipeline"},
+	} {
+		file := filepath.Join(dir, strings.ReplaceAll(tc.name, " ", "-")+".pem")
+		if err = os.WriteFile(file, pem.EncodeToMemory(tc.block), 0600); err != nil {
+			t.Fatal(err)
+		}
+		opts := NewOptions()
+		opts.Mode = JWTMode
+		opts.JWTKeyFile = file
+		opts.JWTKeyID = tc.keyID
+		if len(tc.subject) > 0 {
+			opts.JWTSubject = tc.subject
+		}
+		ts, err := NewTokenSource(context.Background(), "", opts)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err.Error())
+			continue
+		}
+		tok, err := ts.Token()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err.Error())
+			continue
+		}
+		again, _ := ts.Token()
+		if again == nil || again.AccessToken != tok.AccessToken {
+			t.Errorf("%s: the JWT should be reused until it is about to expire", tc.name)
+		}
+
+		jws, err := jose.ParseSigned(tok.AccessToken, []jose.SignatureAlgorithm{tc.alg})
+		if err != nil {
+			t.Errorf("%s: unexpected error parsing the JWT: %s", tc.name, err.Error())
+			continue
+		}
+		payload, err := jws.Verify(tc.key.Public())
+		if err != nil {
+			t.Errorf("%s: the JWT signature did not verify: %s", tc.name, err.Error())
+			continue
+		}
+		expectedKeyID := tc.keyID
+		if len(expectedKeyID) == 0 {
+			expectedKeyID, _ = KeyID(tc.key)
+		}
+		common.AssertEqual(t, expectedKeyID, jws.Signatures[0].Header.KeyID)
+		claims := map[string]interface{}{}
+		_ = json.Unmarshal(payload, &claims)
+		common.AssertEqual(t, DEFAULT_JWT_ISSUER, claims["iss"])
+		common.AssertEqual(t, opts.JWTSubject, claims["sub"])
+		common.AssertEqual(t, DEFAULT_JWT_AUDIENCE, claims["aud"])
+		common.AssertEqual(t, DEFAULT_JWT_LIFETIME.Seconds(), claims["exp"].(float64)-claims["iat"].(float64))
+	}
+}
+
+func TestLoginAndOIDCTokenSource(t *testing.T) {
+	var issuer string
+	ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case WELL_KNOWN_OIDC_URI:
+			_, _ = w.Write([]byte(`{"issuer":"` + issuer + `","token_endpoint":"` + issuer + `/token","device_authorization_endpoint":"` + issuer + `/device"}`))
+		case "/device":
+			_, _ = w.Write([]byte(`{"device_code":"device-1","user_code":"ABCD-EFGH","verification_uri":"` + issuer + `/activate","interval":1,"expires_in":60}`))
+		case "/token":
+			_ = r.ParseForm()
+			switch {
+			case r.Form.Get("device_code") == "device-1":
+				_, _ = w.Write([]byte(`{"access_token":"access-1","refresh_token":"refresh-1","token_type":"Bearer","expires_in":3600}`))
+			case r.Form.Get("refresh_token") == "refresh-1":
+				_, _ = w.Write([]byte(`{"access_token":"access-2","refresh_token":"refresh-2","token_type":"Bearer","expires_in":3600}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	issuer = ts.URL
+
+	opts := NewOptions()
+	opts.Mode = OIDCMode
+	opts.OIDCIssuer = issuer
+	opts.OIDCClientID = "bac"
+	opts.CacheDir = t.TempDir()
+	out := &strings.Builder{}
+	err := Login(context.Background(), opts, out)
+	if err != nil {
+		t.Fatalf("unexpected login error: %s", err.Error())
+	}
+	path, _ := cachePath(opts)
+	common.AssertEqual(t, "To log in, open "+issuer+"/activate and enter the code ABCD-EFGH\nLogged in to "+issuer+"; tokens cached in "+path+"\n", out.String())
+
+	src, err := NewTokenSource(context.Background(), "", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, err := src.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	common.AssertEqual(t, "access-1", tok.AccessToken)
+
+	// expire the cached token so the next use refreshes it and updates the cache
+	cache, _ := loadCache(path)
+	cache.Token.Expiry = time.Now().Add(-time.Minute)
+	if err = cache.save(path); err != nil {
+		t.Fatal(err)
+	}
+	src, _ = NewTokenSource(context.Background(), "", opts)
+	tok, err = src.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	common.AssertEqual(t, "access-2", tok.AccessToken)
+	cache, _ = loadCache(path)
+	common.AssertEqual(t, "refresh-2", cache.Token.RefreshToken)
+
+	// a refresh token the identity provider no longer accepts requires logging in again
+	cache.Token.Expiry = time.Now().Add(-time.Minute)
+	if err = cache.save(path); err != nil {
+		t.Fatal(err)
+	}
+	src, _ = NewTokenSource(context.Background(), "", opts)
+	_, err = src.Token()
+	common.AssertEqual(t, int(util.ExitAuth), util.GetExitCode(err))
+
+	// the cache is per issuer and client ID
+	opts.OIDCClientID = "other"
+	src, _ = NewTokenSource(context.Background(), "", opts)
+	_, err = src.Token()
+	common.AssertEqual(t, int(util.ExitAuth), util.GetExitCode(err))
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"golang.org/x/oauth2"
+)
+
+// jwtTokenSource signs a new JWT each time Token is called; it is wrapped in a ReuseTokenSource so a JWT is reused
+// until shortly before it expires
+type jwtTokenSource struct {
+	signer jose.Signer
+	opts   *Options
+	now    func() time.Time
+}
+
+func newJWTTokenSource(opts *Options) (oauth2.TokenSource, error) {
+	if len(opts.JWTKeyFile) == 0 {
+		return nil, util.NewUsageError("--backstage-jwt-key-file is required for the %s auth mode", JWTMode)
+	}
+	key, err := LoadPrivateKey(opts.JWTKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	alg, err := signatureAlgorithm(key)
+	if err != nil {
+		return nil, err
+	}
+	kid := opts.JWTKeyID
+	if len(kid) == 0 {
+		kid, err = KeyID(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	signerOpts := (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", kid)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, signerOpts)
+	if err != nil {
+		return nil, util.NewValidationError("unable to sign with the key in %s: %s", opts.JWTKeyFile, err.Error())
+	}
+	return oauth2.ReuseTokenSource(nil, &jwtTokenSource{signer: signer, opts: opts, now: time.Now}), nil
+}
+
+func (s *jwtTokenSource) Token() (*oauth2.Token, error) {
+	now := s.now()
+	lifetime := s.opts.JWTLifetime
+	if lifetime <= 0 {
+		lifetime = DEFAULT_JWT_LIFETIME
+	}
+	expiry := now.Add(lifetime)
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return nil, err
+	}
+	claims := map[string]interface{}{
+		"iss": s.opts.JWTIssuer,
+		"sub": s.opts.JWTSubject,
+		"aud": s.opts.JWTAudience,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": expiry.Unix(),
+		"jti": base64.RawURLEncoding.EncodeToString(jti),
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	jws, err := s.signer.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+	token, err := jws.CompactSerialize()
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: token, TokenType: "Bearer", Expiry: expiry}, nil
+}
+
+// LoadPrivateKey reads a PEM encoded PKCS#8, PKCS#1 RSA, or SEC 1 EC private key from file
+func LoadPrivateKey(file string) (crypto.Signer, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return nil, util.NewValidationError("unable to read the private key file %s: %s", file, err.Error())
+	}
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		return nil, util.NewValidationError("no PEM encoded private key found in %s", file)
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, util.NewValidationError("%s does not contain a supported RSA, ECDSA, or Ed25519 private key", file)
+}
+
+// KeyID returns the RFC 7638 SHA-256 thumbprint of the public key of key, which is the key ID used in the JWT header
+// when one is not provided, so it can be matched in the JWKS Backstage is configured with
+func KeyID(key crypto.Signer) (string, error) {
+	thumbprint, err := (&jose.JSONWebKey{Key: key.Public()}).Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
+
+func signatureAlgorithm(key crypto.Signer) (jose.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jose.RS256, nil
+	case ed25519.PrivateKey:
+		return jose.EdDSA, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+		return "", util.NewValidationError("unsupported ECDSA curve %s", k.Curve.Params().Name)
+	default:
+		return "", util.NewValidationError("unsupported private key type %T", key)
+	}
+}
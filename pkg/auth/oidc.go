@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"golang.org/x/oauth2"
+)
+
+const WELL_KNOWN_OIDC_URI = "/.well-known/openid-configuration"
+
+// providerMetadata is the subset of the OpenID Provider Metadata needed for the device authorization grant
+type providerMetadata struct {
+	Issuer                      string `json:"issuer"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+// tokenCache is what 'login' stores for an issuer and client ID, including the token endpoint so refreshing does not
+// require repeating discovery
+type tokenCache struct {
+	Issuer        string        `json:"issuer"`
+	ClientID      string        `json:"clientID"`
+	TokenEndpoint string        `json:"tokenEndpoint"`
+	Token         *oauth2.Token `json:"token"`
+}
+
+// Login runs the OIDC device authorization grant against the issuer in opts, writing the verification URL and code the
+// user needs to out, and caches the resulting tokens for use by the oidc auth mode
+func Login(ctx context.Context, opts *Options, out io.Writer) error {
+	err := validateOIDC(opts)
+	if err != nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, opts.httpClient())
+	md, err := discover(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if len(md.DeviceAuthorizationEndpoint) == 0 {
+		return util.NewValidationError("%s does not support the device authorization grant", opts.OIDCIssuer)
+	}
+	cfg := &oauth2.Config{
+		ClientID: opts.OIDCClientID,
+		Scopes:   opts.OIDCScopes,
+		Endpoint: oauth2.Endpoint{DeviceAuthURL: md.DeviceAuthorizationEndpoint, TokenURL: md.TokenEndpoint},
+	}
+	da, err := cfg.DeviceAuth(ctx)
+	if err != nil {
+		return oauthError(err)
+	}
+	fmt.Fprintf(out, "To log in, open %s and enter the code %s\n", da.VerificationURI, da.UserCode)
+	if len(da.VerificationURIComplete) > 0 {
+		fmt.Fprintf(out, "or open %s\n", da.VerificationURIComplete)
+	}
+	token, err := cfg.DeviceAccessToken(ctx, da)
+	if err != nil {
+		return oauthError(err)
+	}
+	cache := &tokenCache{Issuer: opts.OIDCIssuer, ClientID: opts.OIDCClientID, TokenEndpoint: md.TokenEndpoint, Token: token}
+	path, err := cachePath(opts)
+	if err != nil {
+		return err
+	}
+	err = cache.save(path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Logged in to %s; tokens cached in %s\n", opts.OIDCIssuer, path)
+	return nil
+}
+
+// oidcTokenSource returns the cached access token, refreshing it, and updating the cache, once it expires
+type oidcTokenSource struct {
+	ctx   context.Context
+	opts  *Options
+	lock  sync.Mutex
+	path  string
+	cache *tokenCache
+	src   oauth2.TokenSource
+}
+
+func newOIDCTokenSource(ctx context.Context, opts *Options) (oauth2.TokenSource, error) {
+	err := validateOIDC(opts)
+	if err != nil {
+		return nil, err
+	}
+	path, err := cachePath(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &oidcTokenSource{ctx: context.WithValue(ctx, oauth2.HTTPClient, opts.httpClient()), opts: opts, path: path}, nil
+}
+
+func (s *oidcTokenSource) Token() (*oauth2.Token, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.src == nil {
+		cache, err := loadCache(s.path)
+		if err != nil {
+			return nil, err
+		}
+		if cache == nil || cache.Token == nil || cache.Issuer != s.opts.OIDCIssuer || cache.ClientID != s.opts.OIDCClientID {
+			return nil, util.NewError(util.ExitAuth, fmt.Errorf("not logged in to %s; run '%s login'", s.opts.OIDCIssuer, util.ApplicationName))
+		}
+		cfg := &oauth2.Config{ClientID: cache.ClientID, Scopes: s.opts.OIDCScopes, Endpoint: oauth2.Endpoint{TokenURL: cache.TokenEndpoint}}
+		s.cache = cache
+		s.src = cfg.TokenSource(s.ctx, cache.Token)
+	}
+	token, err := s.src.Token()
+	if err != nil {
+		return nil, util.NewError(util.ExitAuth, fmt.Errorf("the login to %s has expired; run '%s login': %w", s.opts.OIDCIssuer, util.ApplicationName, err))
+	}
+	if token.AccessToken != s.cache.Token.AccessToken {
+		s.cache.Token = token
+		err = s.cache.save(s.path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+func validateOIDC(opts *Options) error {
+	if len(opts.OIDCIssuer) == 0 || len(opts.OIDCClientID) == 0 {
+		return util.NewUsageError("--backstage-oidc-issuer and --backstage-oidc-client-id are required for the %s auth mode", OIDCMode)
+	}
+	return nil
+}
+
+func discover(ctx context.Context, opts *Options) (*providerMetadata, error) {
+	u := strings.TrimSuffix(opts.OIDCIssuer, "/") + WELL_KNOWN_OIDC_URI
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, util.NewUsageError("invalid OIDC issuer %s: %s", opts.OIDCIssuer, err.Error())
+	}
+	resp, err := opts.httpClient().Do(req)
+	if err != nil {
+		return nil, util.NewTransportError(err)
+	}
+	defer resp.Body.Close()
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, util.NewTransportError(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, util.NewHTTPError(resp.StatusCode, fmt.Errorf("get for %s rc %d body %s", u, resp.StatusCode, string(buf)))
+	}
+	md := &providerMetadata{}
+	err = json.Unmarshal(buf, md)
+	if err != nil {
+		return nil, util.NewValidationError("invalid OpenID Provider Metadata from %s: %s", u, err.Error())
+	}
+	if len(md.TokenEndpoint) == 0 {
+		return nil, util.NewValidationError("the OpenID Provider Metadata from %s does not have a token endpoint", u)
+	}
+	return md, nil
+}
+
+// oauthError maps errors returned by the identity provider, like a denied or expired authorization, to ExitAuth
+func oauthError(err error) error {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		return util.NewError(util.ExitAuth, err)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return util.NewError(util.ExitAuth, fmt.Errorf("the login was not completed in time: %w", err))
+	}
+	return util.NewTransportError(err)
+}
+
+// cachePath returns a path per issuer and client ID, so logins to more than one Backstage instance can coexist
+func cachePath(opts *Options) (string, error) {
+	dir, err := opts.cacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(opts.OIDCIssuer + " " + opts.OIDCClientID))
+	return filepath.Join(dir, "oidc-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func loadCache(path string) (*tokenCache, error) {
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cache := &tokenCache{}
+	err = json.Unmarshal(buf, cache)
+	if err != nil {
+		return nil, util.NewValidationError("invalid token cache %s; run '%s login': %s", path, util.ApplicationName, err.Error())
+	}
+	return cache, nil
+}
+
+// save writes the cache readable only by the current user, as it contains the refresh token
+func (c *tokenCache) save(path string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(c, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf, 0600)
+}
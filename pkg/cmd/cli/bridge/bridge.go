@@ -0,0 +1,486 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	brdgutil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"github.com/spf13/cobra"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	bridgeExamples = `
+# Deploy the bridge, check that it is healthy, and list the catalog-info.yaml files it serves
+$ %s bridge start
+$ %s bridge status
+$ %s bridge content list
+
+# Import the catalog-info.yaml the bridge serves for version v1 of my-model into Backstage
+$ %s import-model $(%s bridge url)/my-model/v1/catalog-info.yaml
+
+# Print, and then remove, the catalog-info.yaml served under the 'my-model_v1' key
+$ %s bridge content get my-model_v1
+$ %s bridge content remove my-model_v1
+
+# Tear the bridge down, along with the catalog-info.yaml files it serves
+$ %s bridge stop --remove-content
+`
+	contentAddExample = `
+# Serve the catalog-info.yaml file of version v1 of my-model under the 'my-model_v1' key
+$ %s bridge content add my-model v1 ./catalog-info.yaml
+
+# Serve the catalog-info.yaml printed by new-model
+$ %s new-model kserve my-team development my-isvc | %s bridge content add my-isvc v1 -
+
+# Serve each <model-source>_<model-version>.yaml file of a directory
+$ %s bridge content add ./catalog
+`
+)
+
+// Status is the machine-readable health of the bridge
+type Status struct {
+	Namespace         string `json:"namespace"`
+	Replicas          int32  `json:"replicas"`
+	AvailableReplicas int32  `json:"availableReplicas"`
+	// ExposedBy is the kind of object the bridge is reached through, or none
+	ExposedBy string `json:"exposedBy"`
+	// Admitted is whether the Route was admitted by a router, or the Ingress was given an address
+	Admitted    bool   `json:"admitted"`
+	URL         string `json:"url,omitempty"`
+	ContentKeys int    `json:"contentKeys"`
+	Healthy     bool   `json:"healthy"`
+}
+
+// NewCmd returns the bridge command group
+func NewCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "bridge",
+		Short:   "Manage the bridge",
+		Long:    "Deploy, inspect, and tear down the bridge, and manage the catalog-info.yaml files it serves to Backstage.",
+		Example: strings.ReplaceAll(bridgeExamples, "%s", util.ApplicationName),
+	}
+	cmd.AddCommand(NewStartCmd(cfg))
+	cmd.AddCommand(newStopCmd(cfg))
+	cmd.AddCommand(newStatusCmd(cfg))
+	cmd.AddCommand(newURLCmd(cfg))
+	cmd.AddCommand(newContentCmd(cfg))
+	return cmd
+}
+
+// NewLegacyCmds returns the start-bridge and add-bridge-content commands from before the bridge command group; they
+// are hidden from the help but still work, along with their short aliases, and start-bridge still waits for the bridge
+// unless --wait=false is given
+func NewLegacyCmds(cfg *config.Config) []*cobra.Command {
+	start := newStartCmd(cfg, true)
+	start.Use = "start-bridge"
+	start.Aliases = []string{"sb"}
+	start.Hidden = true
+	add := newContentAddCmd(cfg)
+	add.Use = "add-bridge-content"
+	add.Aliases = []string{"abc"}
+	add.Hidden = true
+	return []*cobra.Command{start, add}
+}
+
+func newStopCmd(cfg *config.Config) *cobra.Command {
+	removeContent := false
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Tear down the bridge",
+		Long:  "stop removes the bridge's Deployment, Service, and Route or Ingress.  The catalog-info.yaml files it serves are kept, for the next start, unless --remove-content is set.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return Delete(cmd.Context(), cfg, removeContent)
+		},
+	}
+	cmd.Flags().BoolVar(&removeContent, "remove-content", false, "Also remove the ConfigMap with the catalog-info.yaml files the bridge serves.")
+	return cmd
+}
+
+func newStatusCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Report the health of the bridge",
+		Long:  "status reports, as JSON, the availability of the bridge's Deployment, whether its Route or Ingress is admitted, the URL it is served at, and the number of catalog-info.yaml files it serves.  It exits with a non-zero return code when the bridge is not healthy.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := GetStatus(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
+			buf, err := json.MarshalIndent(status, "", "    ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(buf))
+			if !status.Healthy {
+				return util.NewError(util.ExitUnavailable, fmt.Errorf("the bridge in %s is not healthy", cfg.Namespace))
+			}
+			return nil
+		},
+	}
+}
+
+func newURLCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "url",
+		Short: "Print the URL the bridge is served at",
+		Long:  "url prints the URL of the bridge's admitted Route or Ingress, for use in other commands like import-model.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := GetStatus(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
+			if status.ExposedBy == ExposeNone {
+				return util.NewNotFoundError("the bridge in %s is not exposed outside the cluster; run '%s bridge start' with --expose", cfg.Namespace, util.ApplicationName)
+			}
+			if len(status.URL) == 0 || !status.Admitted {
+				return util.NewError(util.ExitUnavailable, fmt.Errorf("the %s of the bridge in %s is not admitted yet", status.ExposedBy, cfg.Namespace))
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), status.URL)
+			return nil
+		},
+	}
+}
+
+func newContentCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "content",
+		Short: "Manage the catalog-info.yaml files the bridge serves",
+		Long:  "Add, list, print, and remove the catalog-info.yaml files the bridge serves, which are kept under keys in the " + CONTENT_CONFIGMAP_NAME + " ConfigMap.",
+	}
+	cmd.AddCommand(newContentAddCmd(cfg))
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the keys of the catalog-info.yaml files the bridge serves",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			content, err := ListContent(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
+			keys := make([]string, 0, len(content))
+			for key := range content {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			for _, key := range keys {
+				fmt.Fprintln(cmd.OutOrStdout(), key)
+			}
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the catalog-info.yaml the bridge serves under a key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return util.NewUsageError("need the key of the content to print")
+			}
+			content, err := ListContent(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
+			value, ok := content[args[0]]
+			if !ok {
+				return util.NewNotFoundError("the bridge in %s has no content under the key %s", cfg.Namespace, args[0])
+			}
+			_, err = cmd.OutOrStdout().Write(value)
+			return err
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "remove <key>...",
+		Aliases: []string{"rm"},
+		Short:   "Stop the bridge from serving the catalog-info.yaml under each key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return util.NewUsageError("need the keys of the content to remove")
+			}
+			content, err := ListContent(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
+			for _, key := range args {
+				if _, ok := content[key]; !ok {
+					return util.NewNotFoundError("the bridge in %s has no content under the key %s", cfg.Namespace, key)
+				}
+			}
+			for _, key := range args {
+				err = RemoveContent(cmd.Context(), cfg, key)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+	return cmd
+}
+
+func newContentAddCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add {<model-source> <model-version> <catalog-info.yaml location> | <directory>}",
+		Short: "Add catalog-info.yaml files for the bridge to serve",
+		Long: `add updates the set of catalog-info.yaml files the bridge's REST API will return.
+
+The catalog-info.yaml file is served under the <model-source>_<model-version> key, with the characters ConfigMap keys
+cannot contain removed as the bridge does.  Its location is a local file, a file:// URL, or '-' for stdin.  Given a
+directory instead, each of its <model-source>_<model-version>.yaml files, like the ones 'new-model kserve --watch
+--output-dir' writes, is added.
+
+Content is spread over up to ` + strconv.Itoa(CONTENT_SHARDS) + ` ConfigMaps, each holding at most 1 MiB, as the content ConfigMap fills up.`,
+		Example: strings.ReplaceAll(contentAddExample, "%s", util.ApplicationName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			content := map[string][]byte{}
+			switch len(args) {
+			case 1:
+				err := readContentDir(args[0], content)
+				if err != nil {
+					return err
+				}
+			case 3:
+				key, err := ContentKey(args[0], args[1])
+				if err != nil {
+					return err
+				}
+				content[key], err = readContent(cmd, args[2])
+				if err != nil {
+					return err
+				}
+			default:
+				return util.NewUsageError("need 'model-source string parameter, model-version string parameter, and local catalog-info.yaml file parameter, or a directory of catalog-info.yaml files")
+			}
+
+			// everything is checked before anything is added, so a bad file does not leave the content half updated
+			keys := make([]string, 0, len(content))
+			for key, value := range content {
+				if len(key)+len(value) > MAX_CONTENT_SIZE {
+					return util.NewValidationError("the content for %s is %d bytes, more than the %d bytes a ConfigMap can hold; split it into smaller catalog-info.yaml files",
+						key, len(key)+len(value), MAX_CONTENT_SIZE)
+				}
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			for _, key := range keys {
+				err := AddContent(cmd.Context(), cfg, key, content[key])
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// ContentKey returns the key the bridge serves the catalog-info.yaml of modelVersion of modelSource under, sanitized
+// as the bridge does for the models it imports itself
+func ContentKey(modelSource, modelVersion string) (string, error) {
+	seg1, seg2 := brdgutil.SanitizeName(modelSource), brdgutil.SanitizeModelVersion(modelVersion)
+	if len(seg1) == 0 || len(seg2) == 0 {
+		return "", util.NewValidationError("no key can be made from the model source %q and model version %q; they need alphanumeric characters", modelSource, modelVersion)
+	}
+	key, _ := brdgutil.BuildImportKeyAndURI(seg1, seg2, types.CatalogInfoYamlFormat)
+	return key, ValidateContentKey(key)
+}
+
+// ContentKeyPrefix returns the start of the keys ContentKey makes for the model versions of modelSource
+func ContentKeyPrefix(modelSource string) string {
+	key, _ := brdgutil.BuildImportKeyAndURI(brdgutil.SanitizeName(modelSource), "", types.CatalogInfoYamlFormat)
+	return key
+}
+
+// readContent reads the catalog-info.yaml at location, which is a path, a file URL, or - for stdin
+func readContent(cmd *cobra.Command, location string) ([]byte, error) {
+	if location == "-" {
+		content, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return nil, util.NewValidationError("%s problem reading stdin: %s", cmd.Name(), err.Error())
+		}
+		return content, nil
+	}
+	u, uerr := url.Parse(location)
+	if uerr != nil {
+		return nil, util.NewUsageError("%s given invalid catalog-info.yaml file location URL: %s", cmd.Name(), uerr.Error())
+	}
+	filePath := u.Path
+	content, fileErr := os.ReadFile(filePath)
+	if fileErr != nil {
+		return nil, util.NewValidationError("%s problem reading file %s: %s", cmd.Name(), filePath, fileErr.Error())
+	}
+	return content, nil
+}
+
+// readContentDir reads the <model-source>_<model-version>.yaml files of dir into content, by key
+func readContentDir(dir string, content map[string][]byte) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return util.NewValidationError("problem reading directory %s: %s", dir, err.Error())
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		modelSource, modelVersion, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ext), "_")
+		if !ok {
+			return util.NewValidationError("the name of %s is not of the form <model-source>_<model-version>%s", filepath.Join(dir, entry.Name()), ext)
+		}
+		key, err := ContentKey(modelSource, modelVersion)
+		if err != nil {
+			return err
+		}
+		if _, dup := content[key]; dup {
+			return util.NewValidationError("more than one file in %s is added under the key %s", dir, key)
+		}
+		content[key], err = os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return util.NewValidationError("problem reading file %s: %s", filepath.Join(dir, entry.Name()), err.Error())
+		}
+	}
+	if len(content) == 0 {
+		return util.NewValidationError("there are no .yaml files in %s", dir)
+	}
+	return nil
+}
+
+// Delete mirrors the bridge's Artifacts.Delete, removing the Deployment, Service, and Route, but also removes the Ingress
+// start can expose the bridge with, and the content ConfigMap and its shards when removeContent is set, with the CLI's clients
+func Delete(ctx context.Context, cfg *config.Config, removeContent bool) error {
+	coreClient, err := cfg.GetCoreClient()
+	if err != nil {
+		return util.NewKubeError(err)
+	}
+	appsClient, err := cfg.GetAppsClient()
+	if err != nil {
+		return util.NewKubeError(err)
+	}
+	routeClient, err := cfg.GetRouteClient()
+	if err != nil {
+		return util.NewKubeError(err)
+	}
+	networkingClient, err := cfg.GetNetworkingClient()
+	if err != nil {
+		return util.NewKubeError(err)
+	}
+
+	type deletion struct {
+		kind   string
+		name   string
+		delete func(context.Context, string, metav1.DeleteOptions) error
+	}
+	deletes := []deletion{
+		{"Deployment", NAME, appsClient.Deployments(cfg.Namespace).Delete},
+		{"Service", NAME, coreClient.Services(cfg.Namespace).Delete},
+		{"Route", NAME, routeClient.Routes(cfg.Namespace).Delete},
+		{"Ingress", NAME, networkingClient.Ingresses(cfg.Namespace).Delete},
+	}
+	if removeContent {
+		for _, shard := range ContentShardNames() {
+			deletes = append(deletes, deletion{"ConfigMap", shard, coreClient.ConfigMaps(cfg.Namespace).Delete})
+		}
+	}
+	for _, d := range deletes {
+		err = d.delete(ctx, d.name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return util.NewKubeError(fmt.Errorf("removing %s %s/%s: %w", d.kind, cfg.Namespace, d.name, err))
+		}
+	}
+	return nil
+}
+
+// GetStatus returns the health of the bridge; it is healthy when all of its replicas are available and, when it is
+// exposed outside the cluster, its Route is admitted or its Ingress has an address
+func GetStatus(ctx context.Context, cfg *config.Config) (*Status, error) {
+	appsClient, err := cfg.GetAppsClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	routeClient, err := cfg.GetRouteClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	networkingClient, err := cfg.GetNetworkingClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+
+	status := &Status{Namespace: cfg.Namespace, ExposedBy: ExposeNone}
+	dpm, err := appsClient.Deployments(cfg.Namespace).Get(ctx, NAME, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, util.NewNotFoundError("the bridge is not deployed in %s; run '%s bridge start'", cfg.Namespace, util.ApplicationName)
+	}
+	if err != nil {
+		return nil, util.NewKubeError(fmt.Errorf("getting Deployment %s/%s: %w", cfg.Namespace, NAME, err))
+	}
+	status.Replicas = 1
+	if dpm.Spec.Replicas != nil {
+		status.Replicas = *dpm.Spec.Replicas
+	}
+	status.AvailableReplicas = dpm.Status.AvailableReplicas
+
+	route, err := routeClient.Routes(cfg.Namespace).Get(ctx, NAME, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		status.ExposedBy = ExposeRoute
+		status.URL, status.Admitted = RouteURL(route)
+	case !errors.IsNotFound(err):
+		return nil, util.NewKubeError(fmt.Errorf("getting Route %s/%s: %w", cfg.Namespace, NAME, err))
+	default:
+		ingress, err := networkingClient.Ingresses(cfg.Namespace).Get(ctx, NAME, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			status.ExposedBy = ExposeIngress
+			status.URL, status.Admitted = IngressURL(ingress)
+		case !errors.IsNotFound(err):
+			return nil, util.NewKubeError(fmt.Errorf("getting Ingress %s/%s: %w", cfg.Namespace, NAME, err))
+		}
+	}
+
+	content, err := ListContent(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	status.ContentKeys = len(content)
+	status.Healthy = status.AvailableReplicas >= status.Replicas && status.Replicas > 0 && (status.ExposedBy == ExposeNone || status.Admitted)
+	return status, nil
+}
+
+// IngressURL returns the URL of ingress, with https when it has TLS, and whether the ingress controller gave it an
+// address; the host of its rule is used when it has one, and otherwise the address
+func IngressURL(ingress *networkingv1.Ingress) (string, bool) {
+	host := ""
+	if len(ingress.Spec.Rules) > 0 {
+		host = ingress.Spec.Rules[0].Host
+	}
+	admitted := false
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		if len(host) == 0 {
+			host = lb.Hostname
+		}
+		if len(host) == 0 {
+			host = lb.IP
+		}
+		admitted = true
+	}
+	if len(host) == 0 {
+		return "", admitted
+	}
+	scheme := "http"
+	if len(ingress.Spec.TLS) > 0 {
+		scheme = "https"
+	}
+	return scheme + "://" + host, admitted
+}
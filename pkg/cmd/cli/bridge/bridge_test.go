@@ -0,0 +1,209 @@
+package bridge
+
+import (
+	"encoding/base64"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	cobra2 "github.com/redhat-ai-dev/model-catalog-bridge/test/cobra"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	networkingv1client "k8s.io/client-go/kubernetes/typed/networking/v1"
+	"k8s.io/client-go/rest"
+)
+
+// setupBridgeAPIServer returns a stand in for the Kubernetes API server with the bridge deployed and exposed with a
+// Route, where available is the number of available replicas, and the requests other than GETs recorded
+func setupBridgeAPIServer(t *testing.T, available string, requests *[]string) *config.Config {
+	lock := sync.Mutex{}
+	content := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"` + NAME + `","namespace":"bridge"},"data":{"b_v1":"b"},"binaryData":{"a_v1":"` + base64.StdEncoding.EncodeToString([]byte("kind: Component\n")) + `"}}`
+	apiServer := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			*requests = append(*requests, r.Method+" "+r.URL.Path)
+			if r.Method == http.MethodPut {
+				content = `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"` + NAME + `","namespace":"bridge"},"data":{"b_v1":"b"}}`
+			}
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/apis/apps/v1/namespaces/bridge/deployments/" + NAME:
+			_, _ = w.Write([]byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"` + NAME + `"},"spec":{"replicas":1},"status":{"availableReplicas":` + available + `}}`))
+		case "/apis/route.openshift.io/v1/namespaces/bridge/routes/" + NAME:
+			_, _ = w.Write([]byte(`{"apiVersion":"route.openshift.io/v1","kind":"Route","metadata":{"name":"` + NAME + `"},"spec":{"tls":{"termination":"edge"}},"status":{"ingress":[{"host":"bridge.apps.example.com","conditions":[{"type":"Admitted","status":"True"}]}]}}`))
+		case "/api/v1/namespaces/bridge/configmaps/" + NAME:
+			_, _ = w.Write([]byte(content))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"NotFound","code":404}`))
+		}
+	})
+	t.Cleanup(apiServer.Close)
+	restCfg := &rest.Config{Host: apiServer.URL, ContentConfig: rest.ContentConfig{ContentType: "application/json"}}
+	cfg := config.NewConfig()
+	cfg.Namespace = "bridge"
+	cfg.CoreClient, _ = corev1client.NewForConfig(restCfg)
+	cfg.AppsClient, _ = appsv1client.NewForConfig(restCfg)
+	cfg.RouteClient, _ = routev1client.NewForConfig(restCfg)
+	cfg.NetworkingClient, _ = networkingv1client.NewForConfig(restCfg)
+	return cfg
+}
+
+func TestStatus(t *testing.T) {
+	requests := []string{}
+	cfg := setupBridgeAPIServer(t, "1", &requests)
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "status")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, `{
+    "namespace": "bridge",
+    "replicas": 1,
+    "availableReplicas": 1,
+    "exposedBy": "route",
+    "admitted": true,
+    "url": "https://bridge.apps.example.com",
+    "contentKeys": 2,
+    "healthy": true
+}
+`, stdout)
+
+	cfg = setupBridgeAPIServer(t, "0", &requests)
+	_, stdout, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "status")
+	common.AssertEqual(t, int(util.ExitUnavailable), util.GetExitCode(err))
+	common.AssertEqual(t, true, strings.Contains(stdout, `"healthy": false`))
+
+	cfg.Namespace = "other"
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "status")
+	common.AssertEqual(t, int(util.ExitNotFound), util.GetExitCode(err))
+}
+
+func TestURL(t *testing.T) {
+	requests := []string{}
+	cfg := setupBridgeAPIServer(t, "0", &requests)
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "url")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, "https://bridge.apps.example.com\n", stdout)
+
+	cfg.Namespace = "other"
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "url")
+	common.AssertEqual(t, int(util.ExitNotFound), util.GetExitCode(err))
+}
+
+func TestContent(t *testing.T) {
+	requests := []string{}
+	cfg := setupBridgeAPIServer(t, "1", &requests)
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "content", "list")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, "a_v1\nb_v1\n", stdout)
+
+	_, stdout, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "content", "get", "a_v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, "kind: Component\n", stdout)
+
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "content", "get", "c_v1")
+	common.AssertEqual(t, int(util.ExitNotFound), util.GetExitCode(err))
+
+	// nothing is removed when any of the keys is not there
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "content", "remove", "a_v1", "c_v1")
+	common.AssertEqual(t, int(util.ExitNotFound), util.GetExitCode(err))
+	common.AssertEqual(t, 0, len(requests))
+
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "content", "remove", "a_v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, []string{"PUT /api/v1/namespaces/bridge/configmaps/" + NAME}, requests)
+	_, stdout, _, _ = cobra2.ExecuteCommandC(NewCmd(cfg), "content", "list")
+	common.AssertEqual(t, "b_v1\n", stdout)
+}
+
+func TestStop(t *testing.T) {
+	requests := []string{}
+	cfg := setupBridgeAPIServer(t, "1", &requests)
+	_, _, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "stop")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	expected := []string{
+		"DELETE /apis/apps/v1/namespaces/bridge/deployments/" + NAME,
+		"DELETE /api/v1/namespaces/bridge/services/" + NAME,
+		"DELETE /apis/route.openshift.io/v1/namespaces/bridge/routes/" + NAME,
+		"DELETE /apis/networking.k8s.io/v1/namespaces/bridge/ingresses/" + NAME,
+	}
+	common.AssertEqual(t, expected, requests)
+
+	requests = requests[:0]
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "stop", "--remove-content")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	for _, shard := range ContentShardNames() {
+		expected = append(expected, "DELETE /api/v1/namespaces/bridge/configmaps/"+shard)
+	}
+	common.AssertEqual(t, expected, requests)
+}
+
+func TestContentAdd(t *testing.T) {
+	cms := map[string]*corev1.ConfigMap{}
+	cfg := setupConfigMapStore(t, cms)
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "default_model-1.yaml"), []byte("model 1"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "default_Model 2.yml"), []byte("model 2"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "README.md"), []byte("not content"), 0644)
+
+	_, _, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "content", "add", dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, map[string][]byte{"default_model-1": []byte("model 1"), "default_model-2": []byte("model 2")}, cms[NAME].BinaryData)
+
+	cmd := NewCmd(cfg)
+	cmd.SetIn(strings.NewReader("model 3"))
+	_, _, _, err = cobra2.ExecuteCommandC(cmd, "content", "add", "My Source", "V1.0", "-")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, []byte("model 3"), cms[NAME].BinaryData["MySource_v10"])
+
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "content", "add", "my-source", "v1")
+	common.AssertEqual(t, int(util.ExitUsage), util.GetExitCode(err))
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "content", "add", "...", "v1", "-")
+	common.AssertEqual(t, int(util.ExitValidation), util.GetExitCode(err))
+
+	// nothing is added when any of the files of a directory cannot be
+	_ = os.WriteFile(filepath.Join(dir, "model-3.yaml"), []byte("model 3"), 0644)
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "content", "add", dir)
+	common.AssertEqual(t, int(util.ExitValidation), util.GetExitCode(err))
+	common.AssertEqual(t, 3, len(cms[NAME].BinaryData))
+}
+
+func TestContentKey(t *testing.T) {
+	// the key of an InferenceService watched by new-model kserve is the same as the one content add makes for it
+	key, err := ContentKey("default", "InferSvc-1")
+	common.AssertError(t, err)
+	common.AssertEqual(t, "default_infersvc-1", key)
+	common.AssertEqual(t, true, strings.HasPrefix(key, ContentKeyPrefix("default")))
+	common.AssertEqual(t, "MySource_", ContentKeyPrefix("My Source"))
+
+	_, err = ContentKey("default", "...")
+	common.AssertEqual(t, int(util.ExitValidation), util.GetExitCode(err))
+}
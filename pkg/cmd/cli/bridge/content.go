@@ -0,0 +1,323 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// CONTENT_CONFIGMAP_NAME is the ConfigMap, named like the rest of the bridge's artifacts, whose keys are the
+	// catalog-info.yaml files the bridge's REST API returns
+	CONTENT_CONFIGMAP_NAME = "bac-import-model"
+
+	// CONTENT_SHARDS is the number of ConfigMaps, the content ConfigMap included, the content is spread over once it no
+	// longer fits in the content ConfigMap; the bridge mounts all of them into the same directory
+	CONTENT_SHARDS = 8
+
+	// CONTENT_INDEX_ANNOTATION is the annotation of the content ConfigMap with, as JSON, the name of the ConfigMap each
+	// key is stored in when that is not the content ConfigMap itself
+	CONTENT_INDEX_ANNOTATION = "bac.redhat-ai-dev.io/content-index"
+
+	// MAX_CONTENT_SIZE is the most a ConfigMap can hold, counting both its keys and their values
+	MAX_CONTENT_SIZE = corev1.MaxSecretSize
+
+	// the number of times an update of the ConfigMap is attempted when it is changed by someone else in the meantime
+	conflictRetries = 5
+)
+
+// ContentShardNames returns the names of the ConfigMaps the content can be stored in, in the order they are filled
+func ContentShardNames() []string {
+	names := []string{CONTENT_CONFIGMAP_NAME}
+	for i := 1; i < CONTENT_SHARDS; i++ {
+		names = append(names, CONTENT_CONFIGMAP_NAME+"-"+strconv.Itoa(i))
+	}
+	return names
+}
+
+// ValidateContentKey returns a validation error when key cannot be used as a ConfigMap key, and so as the name of a
+// file served by the bridge
+func ValidateContentKey(key string) error {
+	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+		return util.NewValidationError("%q cannot be used as the key of bridge content: %s", key, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// AddContent mirrors the bridge's Artifacts.AddContent, storing content under key in the content ConfigMap of the bridge
+// in the namespace of cfg, but uses the Kubernetes client of the CLI and retries when the ConfigMap is updated
+// concurrently.  Once the content ConfigMap is full, the content is stored in the first of the other shards with room
+// for it, and the shard is recorded in the index of the content ConfigMap.
+func AddContent(ctx context.Context, cfg *config.Config, key string, content []byte) error {
+	err := ValidateContentKey(key)
+	if err != nil {
+		return err
+	}
+	if len(key)+len(content) > MAX_CONTENT_SIZE {
+		return util.NewValidationError("the content for %s is %d bytes, more than the %d bytes a ConfigMap can hold; split it into smaller catalog-info.yaml files",
+			key, len(key)+len(content), MAX_CONTENT_SIZE)
+	}
+	index, err := getContentIndex(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	current := shardOf(index, key)
+
+	put := func(cm *corev1.ConfigMap) bool {
+		existing := contentOf(cm, key)
+		size := contentSize(cm) + len(key) + len(content)
+		if existing != nil {
+			size -= len(key) + len(existing)
+		}
+		if size > MAX_CONTENT_SIZE {
+			return false
+		}
+		delete(cm.Data, key)
+		if cm.BinaryData == nil {
+			cm.BinaryData = map[string][]byte{}
+		}
+		cm.BinaryData[key] = content
+		return true
+	}
+	for _, shard := range append([]string{current}, ContentShardNames()...) {
+		stored, err := updateContent(ctx, cfg, shard, put)
+		if err != nil {
+			return err
+		}
+		if !stored {
+			continue
+		}
+		if shard == current {
+			return nil
+		}
+		// the key is moved to the new shard before it is removed from the old one, so it is always served
+		err = updateContentIndex(ctx, cfg, key, shard)
+		if err != nil {
+			return err
+		}
+		_, err = updateContent(ctx, cfg, current, removeKey(key))
+		return err
+	}
+	return util.NewError(util.ExitConflict, fmt.Errorf("the %d ConfigMaps of the bridge in %s have no room for the %d bytes of %s; remove content with '%s bridge content remove'",
+		CONTENT_SHARDS, cfg.Namespace, len(content), key, util.ApplicationName))
+}
+
+// RemoveContent removes key from the content ConfigMap, or the shard it is stored in, of the bridge; removing a key
+// that is not there is not an error
+func RemoveContent(ctx context.Context, cfg *config.Config, key string) error {
+	index, err := getContentIndex(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	shard := shardOf(index, key)
+	_, err = updateContent(ctx, cfg, shard, removeKey(key))
+	if err != nil || shard == CONTENT_CONFIGMAP_NAME {
+		return err
+	}
+	return updateContentIndex(ctx, cfg, key, CONTENT_CONFIGMAP_NAME)
+}
+
+// GetContent returns the content of key from the content ConfigMap, or the shard it is stored in, of the bridge; there
+// is no content when the key is not there
+func GetContent(ctx context.Context, cfg *config.Config, key string) ([]byte, error) {
+	index, err := getContentIndex(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	cm, err := getContentConfigMap(ctx, cfg, shardOf(index, key))
+	if err != nil || cm == nil {
+		return nil, err
+	}
+	return contentOf(cm, key), nil
+}
+
+// ListContent returns the content of the bridge by key, from both the binary and string data of the content ConfigMap
+// and the shards in its index; there is no content when the ConfigMap does not exist
+func ListContent(ctx context.Context, cfg *config.Config) (map[string][]byte, error) {
+	content := map[string][]byte{}
+	cm, err := getContentConfigMap(ctx, cfg, CONTENT_CONFIGMAP_NAME)
+	if err != nil || cm == nil {
+		return content, err
+	}
+	index, err := parseContentIndex(cm)
+	if err != nil {
+		return nil, err
+	}
+	cms := []*corev1.ConfigMap{cm}
+	seen := map[string]bool{CONTENT_CONFIGMAP_NAME: true}
+	for _, shard := range index {
+		if seen[shard] {
+			continue
+		}
+		seen[shard] = true
+		cm, err = getContentConfigMap(ctx, cfg, shard)
+		if err != nil {
+			return nil, err
+		}
+		if cm != nil {
+			cms = append(cms, cm)
+		}
+	}
+	for _, cm := range cms {
+		for key, value := range cm.Data {
+			content[key] = []byte(value)
+		}
+		for key, value := range cm.BinaryData {
+			content[key] = value
+		}
+	}
+	return content, nil
+}
+
+// getContentConfigMap returns the ConfigMap with name, or nil when it does not exist
+func getContentConfigMap(ctx context.Context, cfg *config.Config, name string) (*corev1.ConfigMap, error) {
+	coreClient, err := cfg.GetCoreClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	cm, err := coreClient.ConfigMaps(cfg.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, util.NewKubeError(fmt.Errorf("getting ConfigMap %s/%s: %w", cfg.Namespace, name, err))
+	}
+	return cm, nil
+}
+
+// getContentIndex returns the index of the content ConfigMap, which is empty when all of the content is stored in it
+func getContentIndex(ctx context.Context, cfg *config.Config) (map[string]string, error) {
+	cm, err := getContentConfigMap(ctx, cfg, CONTENT_CONFIGMAP_NAME)
+	if err != nil || cm == nil {
+		return map[string]string{}, err
+	}
+	return parseContentIndex(cm)
+}
+
+func parseContentIndex(cm *corev1.ConfigMap) (map[string]string, error) {
+	index := map[string]string{}
+	value, ok := cm.Annotations[CONTENT_INDEX_ANNOTATION]
+	if !ok {
+		return index, nil
+	}
+	err := json.Unmarshal([]byte(value), &index)
+	if err != nil {
+		return nil, util.NewValidationError("the %s annotation of ConfigMap %s/%s is not valid: %s", CONTENT_INDEX_ANNOTATION, cm.Namespace, cm.Name, err.Error())
+	}
+	return index, nil
+}
+
+// updateContentIndex records that key is stored in shard, where the content ConfigMap itself is not recorded
+func updateContentIndex(ctx context.Context, cfg *config.Config, key, shard string) error {
+	var parseErr error
+	_, err := updateContent(ctx, cfg, CONTENT_CONFIGMAP_NAME, func(cm *corev1.ConfigMap) bool {
+		index, err := parseContentIndex(cm)
+		if err != nil {
+			parseErr = err
+			return false
+		}
+		if shardOf(index, key) == shard {
+			return false
+		}
+		if shard == CONTENT_CONFIGMAP_NAME {
+			delete(index, key)
+		} else {
+			index[key] = shard
+		}
+		buf, _ := json.Marshal(index)
+		if cm.Annotations == nil {
+			cm.Annotations = map[string]string{}
+		}
+		cm.Annotations[CONTENT_INDEX_ANNOTATION] = string(buf)
+		if len(index) == 0 {
+			delete(cm.Annotations, CONTENT_INDEX_ANNOTATION)
+		}
+		return true
+	})
+	if parseErr != nil {
+		return parseErr
+	}
+	return err
+}
+
+func shardOf(index map[string]string, key string) string {
+	if shard, ok := index[key]; ok {
+		return shard
+	}
+	return CONTENT_CONFIGMAP_NAME
+}
+
+func contentOf(cm *corev1.ConfigMap, key string) []byte {
+	if value, ok := cm.BinaryData[key]; ok {
+		return value
+	}
+	if value, ok := cm.Data[key]; ok {
+		return []byte(value)
+	}
+	return nil
+}
+
+// contentSize returns the size of cm as counted against MAX_CONTENT_SIZE
+func contentSize(cm *corev1.ConfigMap) int {
+	size := 0
+	for key, value := range cm.Data {
+		size += len(key) + len(value)
+	}
+	for key, value := range cm.BinaryData {
+		size += len(key) + len(value)
+	}
+	return size
+}
+
+func removeKey(key string) func(cm *corev1.ConfigMap) bool {
+	return func(cm *corev1.ConfigMap) bool {
+		_, inBinary := cm.BinaryData[key]
+		_, inData := cm.Data[key]
+		delete(cm.BinaryData, key)
+		delete(cm.Data, key)
+		return inBinary || inData
+	}
+}
+
+// updateContent applies change to the ConfigMap with name, creating it when needed, and saves it when change reports
+// the ConfigMap was modified, which is also what it returns
+func updateContent(ctx context.Context, cfg *config.Config, name string, change func(cm *corev1.ConfigMap) bool) (bool, error) {
+	coreClient, err := cfg.GetCoreClient()
+	if err != nil {
+		return false, util.NewKubeError(err)
+	}
+	cms := coreClient.ConfigMaps(cfg.Namespace)
+	for i := 0; ; i++ {
+		cm, getErr := cms.Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case errors.IsNotFound(getErr):
+			cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: cfg.Namespace, Name: name}}
+			if !change(cm) {
+				return false, nil
+			}
+			_, err = cms.Create(ctx, cm, metav1.CreateOptions{})
+		case getErr != nil:
+			return false, util.NewKubeError(fmt.Errorf("getting ConfigMap %s/%s: %w", cfg.Namespace, name, getErr))
+		default:
+			if !change(cm) {
+				return false, nil
+			}
+			_, err = cms.Update(ctx, cm, metav1.UpdateOptions{})
+		}
+		if err == nil {
+			return true, nil
+		}
+		if (!errors.IsConflict(err) && !errors.IsAlreadyExists(err)) || i >= conflictRetries {
+			return false, util.NewKubeError(fmt.Errorf("saving ConfigMap %s/%s: %w", cfg.Namespace, name, err))
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestAddAndRemoveContent(t *testing.T) {
+	// a stand in for the Kubernetes API server that holds the content ConfigMap, and rejects the first update with a
+	// conflict as if someone else had changed it in the meantime
+	lock := sync.Mutex{}
+	var stored []byte
+	conflicted := false
+	apiServer := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		status := func(code int, reason string) {
+			w.WriteHeader(code)
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"` + reason + `","code":` + strconv.Itoa(code) + `}`))
+		}
+		switch {
+		case r.URL.Path == "/api/v1/namespaces/rhdh/configmaps/"+CONTENT_CONFIGMAP_NAME && r.Method == http.MethodGet:
+			if stored == nil {
+				status(http.StatusNotFound, "NotFound")
+				return
+			}
+			_, _ = w.Write(stored)
+		case r.URL.Path == "/api/v1/namespaces/rhdh/configmaps" && r.Method == http.MethodPost:
+			stored, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write(stored)
+		case r.URL.Path == "/api/v1/namespaces/rhdh/configmaps/"+CONTENT_CONFIGMAP_NAME && r.Method == http.MethodPut:
+			if !conflicted {
+				conflicted = true
+				status(http.StatusConflict, "Conflict")
+				return
+			}
+			stored, _ = io.ReadAll(r.Body)
+			_, _ = w.Write(stored)
+		default:
+			status(http.StatusForbidden, "Forbidden")
+		}
+	})
+	defer apiServer.Close()
+	coreClient, err := corev1client.NewForConfig(&rest.Config{Host: apiServer.URL, ContentConfig: rest.ContentConfig{ContentType: "application/json"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewConfig()
+	cfg.CoreClient = coreClient
+	cfg.Namespace = "rhdh"
+
+	content := func() map[string][]byte {
+		cm := corev1.ConfigMap{}
+		_ = json.Unmarshal(stored, &cm)
+		return cm.BinaryData
+	}
+
+	// the first add creates the ConfigMap, and the second is retried after the conflict
+	common.AssertError(t, AddContent(context.Background(), cfg, "default_model-1", []byte("model 1")))
+	common.AssertError(t, AddContent(context.Background(), cfg, "default_model-2", []byte("model 2")))
+	common.AssertEqual(t, true, conflicted)
+	common.AssertEqual(t, map[string][]byte{"default_model-1": []byte("model 1"), "default_model-2": []byte("model 2")}, content())
+
+	common.AssertError(t, RemoveContent(context.Background(), cfg, "default_model-1"))
+	common.AssertEqual(t, map[string][]byte{"default_model-2": []byte("model 2")}, content())
+	// removing a key that is not there does not update the ConfigMap
+	common.AssertError(t, RemoveContent(context.Background(), cfg, "default_model-1"))
+
+	cfg.Namespace = "other"
+	err = AddContent(context.Background(), cfg, "default_model-1", []byte("model 1"))
+	common.AssertEqual(t, int(util.ExitAuth), util.GetExitCode(err))
+}
+
+// setupConfigMapStore returns a stand in for the Kubernetes API server that keeps the ConfigMaps of the rhdh namespace
+// in cms
+func setupConfigMapStore(t *testing.T, cms map[string]*corev1.ConfigMap) *config.Config {
+	lock := sync.Mutex{}
+	apiServer := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		name := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/rhdh/configmaps/")
+		switch r.Method {
+		case http.MethodGet:
+			cm, ok := cms[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"NotFound","code":404}`))
+				return
+			}
+			buf, _ := json.Marshal(cm)
+			_, _ = w.Write(buf)
+		case http.MethodPost, http.MethodPut:
+			cm := &corev1.ConfigMap{}
+			buf, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(buf, cm)
+			cms[cm.Name] = cm
+			_, _ = w.Write(buf)
+		}
+	})
+	t.Cleanup(apiServer.Close)
+	// the client is not throttled, as sharding makes more requests than its default rate limit allows in a test
+	coreClient, err := corev1client.NewForConfig(&rest.Config{Host: apiServer.URL, QPS: 1000, Burst: 1000, ContentConfig: rest.ContentConfig{ContentType: "application/json"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewConfig()
+	cfg.CoreClient = coreClient
+	cfg.Namespace = "rhdh"
+	return cfg
+}
+
+func TestShardContent(t *testing.T) {
+	cms := map[string]*corev1.ConfigMap{}
+	cfg := setupConfigMapStore(t, cms)
+	ctx := context.Background()
+	shards := ContentShardNames()
+	large := func(size int, c byte) []byte { return bytes.Repeat([]byte{c}, size) }
+
+	// a and b fit in the content ConfigMap, and c goes to the next shard, which is recorded in the index
+	common.AssertError(t, AddContent(ctx, cfg, "a", large(400*1024, 'a')))
+	common.AssertError(t, AddContent(ctx, cfg, "b", large(400*1024, 'b')))
+	common.AssertError(t, AddContent(ctx, cfg, "c", large(400*1024, 'c')))
+	common.AssertEqual(t, 2, len(cms[shards[0]].BinaryData))
+	common.AssertEqual(t, 1, len(cms[shards[1]].BinaryData))
+	common.AssertEqual(t, `{"c":"`+shards[1]+`"}`, cms[shards[0]].Annotations[CONTENT_INDEX_ANNOTATION])
+
+	// a no longer fits with b, or with c, so it moves to the third shard
+	common.AssertError(t, AddContent(ctx, cfg, "a", large(700*1024, 'A')))
+	common.AssertEqual(t, 1, len(cms[shards[0]].BinaryData))
+	common.AssertEqual(t, 1, len(cms[shards[2]].BinaryData))
+	content, err := ListContent(ctx, cfg)
+	common.AssertError(t, err)
+	common.AssertEqual(t, 3, len(content))
+	common.AssertEqual(t, large(700*1024, 'A'), content["a"])
+
+	common.AssertError(t, RemoveContent(ctx, cfg, "c"))
+	common.AssertEqual(t, 0, len(cms[shards[1]].BinaryData))
+	common.AssertEqual(t, `{"a":"`+shards[2]+`"}`, cms[shards[0]].Annotations[CONTENT_INDEX_ANNOTATION])
+	common.AssertError(t, RemoveContent(ctx, cfg, "a"))
+	_, indexed := cms[shards[0]].Annotations[CONTENT_INDEX_ANNOTATION]
+	common.AssertEqual(t, false, indexed)
+
+	err = AddContent(ctx, cfg, "d", large(MAX_CONTENT_SIZE, 'd'))
+	common.AssertEqual(t, int(util.ExitValidation), util.GetExitCode(err))
+	err = AddContent(ctx, cfg, "my/model", []byte("model"))
+	common.AssertEqual(t, int(util.ExitValidation), util.GetExitCode(err))
+}
@@ -0,0 +1,405 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// NAME is the name of all of the bridge's objects, as the bridge itself, and the content ConfigMap it mounts, expect
+	NAME = CONTENT_CONFIGMAP_NAME
+
+	DEFAULT_IMAGE          = "quay.io/gabemontero/import-location"
+	DEFAULT_TAG            = "latest"
+	DEFAULT_CPU_REQUEST    = "5m"
+	DEFAULT_MEMORY_REQUEST = "64Mi"
+	DEFAULT_CPU_LIMIT      = "500m"
+	DEFAULT_MEMORY_LIMIT   = "384Mi"
+
+	// FIELD_MANAGER owns the fields of the bridge's objects set with server-side apply
+	FIELD_MANAGER = "bac"
+
+	ExposeRoute   = "route"
+	ExposeIngress = "ingress"
+	ExposeNone    = "none"
+
+	// the port the bridge listens on
+	port = 8080
+)
+
+var (
+	Exposures         = []string{ExposeRoute, ExposeIngress, ExposeNone}
+	RouteTerminations = []string{"", string(routev1.TLSTerminationEdge), string(routev1.TLSTerminationReencrypt), string(routev1.TLSTerminationPassthrough)}
+	PullPolicies      = []string{string(corev1.PullAlways), string(corev1.PullIfNotPresent), string(corev1.PullNever)}
+)
+
+// DeployOptions replace the settings the bridge's Artifacts hardcode for the bridge's Deployment, Service, and Route
+type DeployOptions struct {
+	Image          string
+	Tag            string
+	PullPolicy     string
+	Replicas       int32
+	CPURequest     string
+	MemoryRequest  string
+	CPULimit       string
+	MemoryLimit    string
+	ServiceAccount string
+	// Labels are added to every object, and to the pods of the Deployment
+	Labels map[string]string
+
+	// Expose is how the bridge is reached from outside the cluster; one of Exposures
+	Expose string
+	// RouteTermination is the TLS termination of the Route, with insecure traffic redirected, or no TLS when empty
+	RouteTermination string
+	// IngressHost, IngressClass, and IngressTLSSecret are the host, class, and TLS Secret of the Ingress, each optional
+	IngressHost      string
+	IngressClass     string
+	IngressTLSSecret string
+}
+
+func NewDeployOptions() *DeployOptions {
+	return &DeployOptions{
+		Image:         DEFAULT_IMAGE,
+		Tag:           DEFAULT_TAG,
+		PullPolicy:    string(corev1.PullAlways),
+		Replicas:      1,
+		CPURequest:    DEFAULT_CPU_REQUEST,
+		MemoryRequest: DEFAULT_MEMORY_REQUEST,
+		CPULimit:      DEFAULT_CPU_LIMIT,
+		MemoryLimit:   DEFAULT_MEMORY_LIMIT,
+		Labels:        map[string]string{},
+		Expose:        ExposeRoute,
+	}
+}
+
+// Validate checks the options which the API server would otherwise reject only after some of the objects are applied
+func (o *DeployOptions) Validate() error {
+	switch {
+	case len(o.Image) == 0 || len(o.Tag) == 0:
+		return util.NewUsageError("the bridge image and tag cannot be empty")
+	case !slices.Contains(PullPolicies, o.PullPolicy):
+		return util.NewUsageError("unsupported pull policy %q; the supported policies are %s", o.PullPolicy, strings.Join(PullPolicies, ", "))
+	case o.Replicas < 0:
+		return util.NewUsageError("the number of replicas cannot be negative")
+	case !slices.Contains(Exposures, o.Expose):
+		return util.NewUsageError("unsupported exposure %q; the supported ones are %s", o.Expose, strings.Join(Exposures, ", "))
+	case !slices.Contains(RouteTerminations, o.RouteTermination):
+		return util.NewUsageError("unsupported route termination %q; the supported ones are %s", o.RouteTermination, strings.Join(RouteTerminations[1:], ", "))
+	case len(o.RouteTermination) > 0 && o.Expose != ExposeRoute:
+		return util.NewUsageError("a route termination can only be set when exposing the bridge with a route")
+	case (len(o.IngressHost) > 0 || len(o.IngressClass) > 0 || len(o.IngressTLSSecret) > 0) && o.Expose != ExposeIngress:
+		return util.NewUsageError("the ingress host, class, and TLS secret can only be set when exposing the bridge with an ingress")
+	}
+	for name, value := range map[string]string{"CPU request": o.CPURequest, "memory request": o.MemoryRequest, "CPU limit": o.CPULimit, "memory limit": o.MemoryLimit} {
+		if len(value) == 0 {
+			continue
+		}
+		if _, err := resource.ParseQuantity(value); err != nil {
+			return util.NewUsageError("invalid %s %q: %s", name, value, err.Error())
+		}
+	}
+	return nil
+}
+
+// contentSources returns the content ConfigMap and its shards, which only exist once the content ConfigMap is full, for
+// the bridge to serve the keys of all of them from the same directory
+func contentSources() []corev1.VolumeProjection {
+	sources := []corev1.VolumeProjection{}
+	for i, shard := range ContentShardNames() {
+		optional := i > 0
+		sources = append(sources, corev1.VolumeProjection{ConfigMap: &corev1.ConfigMapProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: shard},
+			Optional:             &optional,
+		}})
+	}
+	return sources
+}
+
+// Manifests returns the objects of the bridge in namespace, in the order they are applied: the content ConfigMap, the
+// Service, the Deployment, and then the Route or Ingress, if any
+func (o *DeployOptions) Manifests(namespace string) []runtime.Object {
+	meta := func(labels map[string]string) metav1.ObjectMeta {
+		m := metav1.ObjectMeta{Namespace: namespace, Name: NAME, Labels: map[string]string{}}
+		for k, v := range o.Labels {
+			m.Labels[k] = v
+		}
+		for k, v := range labels {
+			m.Labels[k] = v
+		}
+		return m
+	}
+	selector := map[string]string{"app": NAME}
+
+	// only the metadata of the ConfigMap is applied, so the content added to it is left alone
+	cm := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: meta(nil),
+	}
+
+	svc := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: meta(selector),
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "location",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       port,
+					TargetPort: intstr.FromInt32(port),
+				},
+			},
+		},
+	}
+
+	readOnlyFSnonRoot := true
+	defaultMode := int32(420)
+	replicas := o.Replicas
+	dpm := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: meta(map[string]string{"app.kubernetes.io/name": NAME}),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: meta(selector).Labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: o.ServiceAccount,
+					Containers: []corev1.Container{
+						{
+							Name:            "location",
+							Image:           o.Image + ":" + o.Tag,
+							ImagePullPolicy: corev1.PullPolicy(o.PullPolicy),
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "location",
+									ContainerPort: port,
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Limits:   quantities(o.CPULimit, o.MemoryLimit),
+								Requests: quantities(o.CPURequest, o.MemoryRequest),
+							},
+							SecurityContext: &corev1.SecurityContext{ReadOnlyRootFilesystem: &readOnlyFSnonRoot},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "location",
+									MountPath: "/data",
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					SecurityContext: &corev1.PodSecurityContext{RunAsNonRoot: &readOnlyFSnonRoot},
+					Volumes: []corev1.Volume{
+						{
+							Name: "location",
+							VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+								Sources:     contentSources(),
+								DefaultMode: &defaultMode,
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	objs := []runtime.Object{cm, svc, dpm}
+	switch o.Expose {
+	case ExposeRoute:
+		route := &routev1.Route{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "route.openshift.io/v1", Kind: "Route"},
+			ObjectMeta: meta(nil),
+			Spec: routev1.RouteSpec{
+				To:   routev1.RouteTargetReference{Kind: "Service", Name: NAME},
+				Port: &routev1.RoutePort{TargetPort: intstr.FromString("location")},
+			},
+		}
+		if len(o.RouteTermination) > 0 {
+			route.Spec.TLS = &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationType(o.RouteTermination),
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+			}
+		}
+		objs = append(objs, route)
+	case ExposeIngress:
+		pathType := networkingv1.PathTypePrefix
+		ingress := &networkingv1.Ingress{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+			ObjectMeta: meta(nil),
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{
+					{
+						Host: o.IngressHost,
+						IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+										Name: NAME,
+										Port: networkingv1.ServiceBackendPort{Name: "location"},
+									}},
+								},
+							},
+						}},
+					},
+				},
+			},
+		}
+		if len(o.IngressClass) > 0 {
+			ingress.Spec.IngressClassName = &o.IngressClass
+		}
+		if len(o.IngressTLSSecret) > 0 {
+			tls := networkingv1.IngressTLS{SecretName: o.IngressTLSSecret}
+			if len(o.IngressHost) > 0 {
+				tls.Hosts = []string{o.IngressHost}
+			}
+			ingress.Spec.TLS = []networkingv1.IngressTLS{tls}
+		}
+		objs = append(objs, ingress)
+	}
+	return objs
+}
+
+func quantities(cpu, memory string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	if len(cpu) > 0 {
+		list[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if len(memory) > 0 {
+		list[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// PrintManifests writes the objects as a YAML stream, or as a JSON List, for --dry-run
+func PrintManifests(objs []runtime.Object, format string, writer io.Writer) error {
+	switch format {
+	case "json":
+		list := &corev1.List{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "List"}}
+		for _, obj := range objs {
+			list.Items = append(list.Items, runtime.RawExtension{Object: obj})
+		}
+		buf, err := json.MarshalIndent(list, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(writer, string(buf))
+		return err
+	default:
+		for i, obj := range objs {
+			buf, err := yaml.Marshal(obj)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				fmt.Fprintln(writer, "---")
+			}
+			_, err = writer.Write(buf)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Apply creates or updates the bridge's objects with server-side apply, so a restart changes only what differs and the
+// bridge keeps serving, rather than deleting and creating them again like the bridge's Artifacts.  A Route or Ingress
+// left from exposing the bridge another way is removed.
+func (o *DeployOptions) Apply(ctx context.Context, cfg *config.Config) error {
+	coreClient, err := cfg.GetCoreClient()
+	if err != nil {
+		return util.NewKubeError(err)
+	}
+	appsClient, err := cfg.GetAppsClient()
+	if err != nil {
+		return util.NewKubeError(err)
+	}
+	routeClient, err := cfg.GetRouteClient()
+	if err != nil {
+		return util.NewKubeError(err)
+	}
+	networkingClient, err := cfg.GetNetworkingClient()
+	if err != nil {
+		return util.NewKubeError(err)
+	}
+
+	opts := metav1.PatchOptions{FieldManager: FIELD_MANAGER, Force: &[]bool{true}[0]}
+	for _, obj := range o.Manifests(cfg.Namespace) {
+		buf, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		switch obj.(type) {
+		case *corev1.ConfigMap:
+			_, err = coreClient.ConfigMaps(cfg.Namespace).Patch(ctx, NAME, types.ApplyPatchType, buf, opts)
+		case *corev1.Service:
+			_, err = coreClient.Services(cfg.Namespace).Patch(ctx, NAME, types.ApplyPatchType, buf, opts)
+		case *appsv1.Deployment:
+			_, err = appsClient.Deployments(cfg.Namespace).Patch(ctx, NAME, types.ApplyPatchType, buf, opts)
+		case *routev1.Route:
+			_, err = routeClient.Routes(cfg.Namespace).Patch(ctx, NAME, types.ApplyPatchType, buf, opts)
+		case *networkingv1.Ingress:
+			_, err = networkingClient.Ingresses(cfg.Namespace).Patch(ctx, NAME, types.ApplyPatchType, buf, opts)
+		}
+		if err != nil {
+			return util.NewKubeError(fmt.Errorf("applying %s %s/%s: %w", kind, cfg.Namespace, NAME, err))
+		}
+	}
+
+	// the Route API is not there on clusters other than OpenShift, which also shows up as not found
+	if o.Expose != ExposeRoute {
+		err = routeClient.Routes(cfg.Namespace).Delete(ctx, NAME, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return util.NewKubeError(fmt.Errorf("removing Route %s/%s: %w", cfg.Namespace, NAME, err))
+		}
+	}
+	if o.Expose != ExposeIngress {
+		err = networkingClient.Ingresses(cfg.Namespace).Delete(ctx, NAME, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return util.NewKubeError(fmt.Errorf("removing Ingress %s/%s: %w", cfg.Namespace, NAME, err))
+		}
+	}
+	return nil
+}
+
+// RouteURL returns the URL of route, with https when it terminates TLS, and whether a router admitted it
+func RouteURL(route *routev1.Route) (string, bool) {
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionTrue {
+				scheme := "http"
+				if route.Spec.TLS != nil {
+					scheme = "https"
+				}
+				return scheme + "://" + ingress.Host, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,124 @@
+package bridge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	startExample = `
+# This will deploy the bridge, with its default image and resources, in the current namespace, and expose it with an
+# OpenShift Route.  Running it again updates the bridge in place.
+$ %s bridge start
+
+# This will deploy a pinned bridge image with two replicas, more memory, a service account, and a label on all its
+# objects, exposed with an edge terminated Route
+$ %s bridge start --tag=v0.1.0 --pull-policy=IfNotPresent --replicas=2 --memory-limit=512Mi --service-account=bridge --labels=team=ai --route-termination=edge
+
+# On clusters other than OpenShift the bridge can be exposed with an Ingress instead
+$ %s bridge start --expose=ingress --ingress-host=bridge.example.com --ingress-class=nginx --ingress-tls-secret=bridge-tls
+
+# This will print the manifests instead of applying them, for review or to commit to a GitOps repository
+$ %s bridge start --dry-run -o yaml
+
+# This will wait for up to five minutes for the bridge to be rolled out, exposed, and answering, and then import a model
+# from it into Backstage
+$ %s bridge start --wait --timeout=5m
+$ %s import-model $(%s bridge url)/my-model/v1/catalog-info.yaml
+`
+)
+
+func NewStartCmd(cfg *config.Config) *cobra.Command {
+	return newStartCmd(cfg, false)
+}
+
+// newStartCmd returns the start command, which waits for the bridge to be ready unless told otherwise when
+// waitByDefault is set, as start-bridge always has
+func newStartCmd(cfg *config.Config, waitByDefault bool) *cobra.Command {
+	opts := NewDeployOptions()
+	dryRun := false
+	output := ""
+	wait := waitByDefault
+	timeout := DEFAULT_WAIT_TIMEOUT
+	skipTLS := false
+	cmd := &cobra.Command{
+		Use:     "start",
+		Short:   "Deploy the bridge",
+		Long:    "start launches a REST API based service and K8s controller that serves as a normalization tier between Backstage and various AI model metadata systems.",
+		Example: strings.ReplaceAll(startExample, "%s", util.ApplicationName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(output) > 0 && !dryRun {
+				return util.NewUsageError("--output can only be used with --dry-run")
+			}
+			if len(output) > 0 && output != "yaml" && output != "json" {
+				return util.NewUsageError("unsupported output format %q; the supported formats are yaml, json", output)
+			}
+			if wait && dryRun {
+				if cmd.Flags().Changed("wait") {
+					return util.NewUsageError("--wait cannot be used with --dry-run")
+				}
+				wait = false
+			}
+			if !wait && (cmd.Flags().Changed("timeout") || skipTLS) {
+				return util.NewUsageError("--timeout and --skip-tls can only be used with --wait")
+			}
+			if timeout <= 0 {
+				return util.NewUsageError("--timeout must be positive")
+			}
+			err := opts.Validate()
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				return PrintManifests(opts.Manifests(cfg.Namespace), output, cmd.OutOrStdout())
+			}
+
+			err = opts.Apply(cmd.Context(), cfg)
+			if err != nil {
+				return fmt.Errorf("start: %w", err)
+			}
+			if !wait {
+				return nil
+			}
+			url, err := WaitForReady(cmd.Context(), cfg, opts.Expose, timeout, skipTLS, cmd.ErrOrStderr())
+			if err != nil {
+				return fmt.Errorf("start: %w", err)
+			}
+			if len(url) > 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), url)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Image, "image", DEFAULT_IMAGE, "The image of the bridge, without the tag.")
+	cmd.Flags().StringVar(&opts.Tag, "tag", DEFAULT_TAG, "The tag of the bridge image.")
+	cmd.Flags().StringVar(&opts.PullPolicy, "pull-policy", opts.PullPolicy,
+		"The pull policy of the bridge image; one of "+strings.Join(PullPolicies, ", ")+".")
+	cmd.Flags().Int32Var(&opts.Replicas, "replicas", opts.Replicas, "The number of replicas of the bridge.")
+	cmd.Flags().StringVar(&opts.CPURequest, "cpu-request", DEFAULT_CPU_REQUEST, "The CPU requested for the bridge; empty for none.")
+	cmd.Flags().StringVar(&opts.MemoryRequest, "memory-request", DEFAULT_MEMORY_REQUEST, "The memory requested for the bridge; empty for none.")
+	cmd.Flags().StringVar(&opts.CPULimit, "cpu-limit", DEFAULT_CPU_LIMIT, "The CPU limit of the bridge; empty for none.")
+	cmd.Flags().StringVar(&opts.MemoryLimit, "memory-limit", DEFAULT_MEMORY_LIMIT, "The memory limit of the bridge; empty for none.")
+	cmd.Flags().StringVar(&opts.ServiceAccount, "service-account", "", "The service account the bridge runs as; the namespace's default when empty.")
+	cmd.Flags().StringToStringVar(&opts.Labels, "labels", map[string]string{}, "Labels, as key=value pairs, added to all of the bridge's objects and pods.")
+	cmd.Flags().StringVar(&opts.Expose, "expose", ExposeRoute,
+		"How the bridge is reached from outside the cluster; one of "+strings.Join(Exposures, ", ")+".")
+	cmd.Flags().StringVar(&opts.RouteTermination, "route-termination", "",
+		"The TLS termination of the Route, with insecure traffic redirected; one of "+strings.Join(RouteTerminations[1:], ", ")+", or empty for no TLS.")
+	cmd.Flags().StringVar(&opts.IngressHost, "ingress-host", "", "The host of the Ingress, when exposing the bridge with an Ingress.")
+	cmd.Flags().StringVar(&opts.IngressClass, "ingress-class", "", "The class of the Ingress; the cluster's default when empty.")
+	cmd.Flags().StringVar(&opts.IngressTLSSecret, "ingress-tls-secret", "", "The Secret with the TLS certificate of the Ingress; no TLS when empty.")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the bridge's manifests instead of applying them.")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "With --dry-run, the format of the manifests; one of yaml, json.  Defaults to yaml.")
+	cmd.Flags().BoolVar(&wait, "wait", waitByDefault,
+		"Wait for the bridge to be rolled out, its Route or Ingress admitted, and its location service to answer, and print the URL it is served at.")
+	cmd.Flags().DurationVar(&timeout, "timeout", DEFAULT_WAIT_TIMEOUT, "With --wait, how long to wait for the bridge to be ready.")
+	cmd.Flags().BoolVar(&skipTLS, "skip-tls", false, "With --wait, do not verify the certificate of the bridge's Route or Ingress when checking that it answers.")
+
+	return cmd
+}
@@ -0,0 +1,174 @@
+package bridge
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	cobra2 "github.com/redhat-ai-dev/model-catalog-bridge/test/cobra"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	networkingv1client "k8s.io/client-go/kubernetes/typed/networking/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+func TestDeployOptionsValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		change func(o *DeployOptions)
+		valid  bool
+	}{
+		{name: "defaults", change: func(o *DeployOptions) {}, valid: true},
+		{name: "ingress with a host", change: func(o *DeployOptions) { o.Expose = ExposeIngress; o.IngressHost = "bridge.example.com" }, valid: true},
+		{name: "edge route", change: func(o *DeployOptions) { o.RouteTermination = "edge" }, valid: true},
+		{name: "no resources", change: func(o *DeployOptions) { o.CPULimit = ""; o.MemoryLimit = "" }, valid: true},
+		{name: "empty tag", change: func(o *DeployOptions) { o.Tag = "" }},
+		{name: "unknown pull policy", change: func(o *DeployOptions) { o.PullPolicy = "Sometimes" }},
+		{name: "negative replicas", change: func(o *DeployOptions) { o.Replicas = -1 }},
+		{name: "unknown exposure", change: func(o *DeployOptions) { o.Expose = "nodeport" }},
+		{name: "unknown termination", change: func(o *DeployOptions) { o.RouteTermination = "mtls" }},
+		{name: "termination without a route", change: func(o *DeployOptions) { o.Expose = ExposeNone; o.RouteTermination = "edge" }},
+		{name: "ingress host without an ingress", change: func(o *DeployOptions) { o.IngressHost = "bridge.example.com" }},
+		{name: "invalid memory", change: func(o *DeployOptions) { o.MemoryLimit = "lots" }},
+	} {
+		opts := NewDeployOptions()
+		tc.change(opts)
+		err := opts.Validate()
+		switch {
+		case tc.valid && err != nil:
+			t.Errorf("%s: unexpected error: %s", tc.name, err.Error())
+		case !tc.valid && util.GetExitCode(err) != int(util.ExitUsage):
+			t.Errorf("%s: expected a usage error but got %v", tc.name, err)
+		}
+	}
+}
+
+func TestStartDryRun(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Namespace = "bridge"
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewStartCmd(cfg), "--dry-run", "-o", "yaml", "--tag=v0.1.0", "--replicas=2",
+		"--labels=team=ai", "--service-account=bridge", "--cpu-limit=", "--route-termination=edge")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	docs := strings.Split(stdout, "\n---\n")
+	common.AssertEqual(t, 4, len(docs))
+
+	cm, svc, dpm, route := corev1.ConfigMap{}, corev1.Service{}, appsv1.Deployment{}, routev1.Route{}
+	for i, obj := range []interface{}{&cm, &svc, &dpm, &route} {
+		if err = yaml.Unmarshal([]byte(docs[i]), obj); err != nil {
+			t.Fatalf("unexpected error parsing %s: %s", docs[i], err.Error())
+		}
+	}
+	common.AssertEqual(t, "ConfigMap", cm.Kind)
+	common.AssertEqual(t, NAME, cm.Name)
+	common.AssertEqual(t, "bridge", cm.Namespace)
+	common.AssertEqual(t, "ai", cm.Labels["team"])
+	common.AssertEqual(t, 0, len(cm.BinaryData))
+	common.AssertEqual(t, "Service", svc.Kind)
+	common.AssertEqual(t, NAME, svc.Spec.Selector["app"])
+	common.AssertEqual(t, "Deployment", dpm.Kind)
+	common.AssertEqual(t, int32(2), *dpm.Spec.Replicas)
+	common.AssertEqual(t, "ai", dpm.Spec.Template.Labels["team"])
+	common.AssertEqual(t, NAME, dpm.Spec.Template.Labels["app"])
+	common.AssertEqual(t, "bridge", dpm.Spec.Template.Spec.ServiceAccountName)
+	container := dpm.Spec.Template.Spec.Containers[0]
+	common.AssertEqual(t, DEFAULT_IMAGE+":v0.1.0", container.Image)
+	common.AssertEqual(t, corev1.PullAlways, container.ImagePullPolicy)
+	common.AssertEqual(t, DEFAULT_MEMORY_LIMIT, container.Resources.Limits.Memory().String())
+	_, hasCPULimit := container.Resources.Limits[corev1.ResourceCPU]
+	common.AssertEqual(t, false, hasCPULimit)
+	common.AssertEqual(t, DEFAULT_CPU_REQUEST, container.Resources.Requests.Cpu().String())
+	common.AssertEqual(t, "Route", route.Kind)
+	common.AssertEqual(t, routev1.TLSTerminationEdge, route.Spec.TLS.Termination)
+	common.AssertEqual(t, routev1.InsecureEdgeTerminationPolicyRedirect, route.Spec.TLS.InsecureEdgeTerminationPolicy)
+
+	_, stdout, _, err = cobra2.ExecuteCommandC(NewStartCmd(cfg), "--dry-run", "-o", "json", "--expose=ingress", "--ingress-host=bridge.example.com", "--ingress-tls-secret=bridge-tls")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	list := struct {
+		Kind  string                 `json:"kind"`
+		Items []networkingv1.Ingress `json:"items"`
+	}{}
+	if err = yaml.Unmarshal([]byte(stdout), &list); err != nil {
+		t.Fatalf("unexpected error parsing %s: %s", stdout, err.Error())
+	}
+	common.AssertEqual(t, "List", list.Kind)
+	common.AssertEqual(t, 4, len(list.Items))
+	ingress := list.Items[3]
+	common.AssertEqual(t, "Ingress", ingress.Kind)
+	common.AssertEqual(t, "bridge.example.com", ingress.Spec.Rules[0].Host)
+	common.AssertEqual(t, NAME, ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name)
+	common.AssertEqual(t, []string{"bridge.example.com"}, ingress.Spec.TLS[0].Hosts)
+
+	for _, args := range [][]string{{"-o", "yaml"}, {"--dry-run", "-o", "table"}, {"--dry-run", "--expose=nodeport"}} {
+		_, _, _, err = cobra2.ExecuteCommandC(NewStartCmd(cfg), args...)
+		common.AssertEqual(t, int(util.ExitUsage), util.GetExitCode(err))
+	}
+
+	// start-bridge waits by default, except for a dry run, where only an explicit --wait is an error
+	legacy := func() *cobra.Command { return NewLegacyCmds(cfg)[0] }
+	common.AssertEqual(t, "true", legacy().Flags().Lookup("wait").DefValue)
+	common.AssertEqual(t, "false", NewStartCmd(cfg).Flags().Lookup("wait").DefValue)
+	_, stdout, _, err = cobra2.ExecuteCommandC(legacy(), "--dry-run")
+	common.AssertError(t, err)
+	common.AssertEqual(t, true, strings.Contains(stdout, "kind: Deployment"))
+	_, _, _, err = cobra2.ExecuteCommandC(legacy(), "--dry-run", "--wait")
+	common.AssertEqual(t, int(util.ExitUsage), util.GetExitCode(err))
+}
+
+func TestStartApply(t *testing.T) {
+	// a stand in for the Kubernetes API server that records the requests, and has no Route API, like clusters other
+	// than OpenShift
+	lock := sync.Mutex{}
+	requests := []string{}
+	apiServer := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		request := r.Method + " " + r.URL.Path
+		if r.Method == http.MethodPatch {
+			request += " " + r.Header.Get("Content-Type") + " " + r.URL.RawQuery
+		}
+		requests = append(requests, request)
+		if r.Method == http.MethodPatch {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"NotFound","code":404}`))
+	})
+	defer apiServer.Close()
+	restCfg := &rest.Config{Host: apiServer.URL, ContentConfig: rest.ContentConfig{ContentType: "application/json"}}
+	cfg := config.NewConfig()
+	cfg.Namespace = "bridge"
+	cfg.CoreClient, _ = corev1client.NewForConfig(restCfg)
+	cfg.AppsClient, _ = appsv1client.NewForConfig(restCfg)
+	cfg.RouteClient, _ = routev1client.NewForConfig(restCfg)
+	cfg.NetworkingClient, _ = networkingv1client.NewForConfig(restCfg)
+
+	_, _, _, err := cobra2.ExecuteCommandC(NewStartCmd(cfg), "--expose=ingress")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	apply := " application/apply-patch+yaml fieldManager=bac&force=true"
+	common.AssertEqual(t, []string{
+		"PATCH /api/v1/namespaces/bridge/configmaps/" + NAME + apply,
+		"PATCH /api/v1/namespaces/bridge/services/" + NAME + apply,
+		"PATCH /apis/apps/v1/namespaces/bridge/deployments/" + NAME + apply,
+		"PATCH /apis/networking.k8s.io/v1/namespaces/bridge/ingresses/" + NAME + apply,
+		"DELETE /apis/route.openshift.io/v1/namespaces/bridge/routes/" + NAME,
+	}, requests)
+}
@@ -0,0 +1,205 @@
+package bridge
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	brdgutil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DEFAULT_WAIT_TIMEOUT is how long start --wait waits for the bridge, as long as the bridge's Artifacts.Ready
+	// waits for the Route
+	DEFAULT_WAIT_TIMEOUT = 2 * time.Minute
+)
+
+var (
+	// readyPollInterval is how often the readiness of the bridge is checked
+	readyPollInterval = 2 * time.Second
+)
+
+// WaitForReady waits, for up to timeout, for all the replicas of the bridge's Deployment to be rolled out and available,
+// for its Route or Ingress, per expose, to be admitted, and for its location service to answer, and returns the URL it
+// is served at, which is empty when it is not exposed outside the cluster.  The location service is reached through
+// the URL, verifying its certificate unless skipTLS is set, or through the Kubernetes API's Service proxy when not
+// exposed.  When the bridge is not ready in time, the state and events of its pods are written to errOut.
+func WaitForReady(ctx context.Context, cfg *config.Config, expose string, timeout time.Duration, skipTLS bool, errOut io.Writer) (string, error) {
+	appsClient, err := cfg.GetAppsClient()
+	if err != nil {
+		return "", util.NewKubeError(err)
+	}
+	routeClient, err := cfg.GetRouteClient()
+	if err != nil {
+		return "", util.NewKubeError(err)
+	}
+	networkingClient, err := cfg.GetNetworkingClient()
+	if err != nil {
+		return "", util.NewKubeError(err)
+	}
+	coreClient, err := cfg.GetCoreClient()
+	if err != nil {
+		return "", util.NewKubeError(err)
+	}
+	httpClient := &http.Client{
+		Timeout:   cfg.Requests.Timeout,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: skipTLS}, Proxy: http.ProxyFromEnvironment},
+	}
+
+	url := ""
+	pending := ""
+	err = wait.PollUntilContextTimeout(ctx, readyPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		// only errors that waiting will not fix stop the wait, as requests fail while the API server is busy, and
+		// when they are cut short by the timeout
+		fail := func(err error) (bool, error) {
+			if errors.IsForbidden(err) || errors.IsUnauthorized(err) {
+				return false, util.NewKubeError(err)
+			}
+			pending = err.Error()
+			return false, nil
+		}
+		dpm, err := appsClient.Deployments(cfg.Namespace).Get(ctx, NAME, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			pending = fmt.Sprintf("Deployment %s/%s does not exist", cfg.Namespace, NAME)
+			return false, nil
+		}
+		if err != nil {
+			return fail(fmt.Errorf("getting Deployment %s/%s: %w", cfg.Namespace, NAME, err))
+		}
+		replicas := int32(1)
+		if dpm.Spec.Replicas != nil {
+			replicas = *dpm.Spec.Replicas
+		}
+		if dpm.Status.ObservedGeneration < dpm.Generation || dpm.Status.UpdatedReplicas < replicas || dpm.Status.AvailableReplicas < replicas {
+			pending = fmt.Sprintf("%d of %d replicas are updated and %d are available", dpm.Status.UpdatedReplicas, replicas, dpm.Status.AvailableReplicas)
+			return false, nil
+		}
+
+		admitted := true
+		switch expose {
+		case ExposeRoute:
+			route, err := routeClient.Routes(cfg.Namespace).Get(ctx, NAME, metav1.GetOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return fail(fmt.Errorf("getting Route %s/%s: %w", cfg.Namespace, NAME, err))
+			}
+			if err == nil {
+				url, admitted = RouteURL(route)
+			}
+			pending = fmt.Sprintf("Route %s/%s is not admitted", cfg.Namespace, NAME)
+		case ExposeIngress:
+			ingress, err := networkingClient.Ingresses(cfg.Namespace).Get(ctx, NAME, metav1.GetOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return fail(fmt.Errorf("getting Ingress %s/%s: %w", cfg.Namespace, NAME, err))
+			}
+			if err == nil {
+				url, admitted = IngressURL(ingress)
+			}
+			pending = fmt.Sprintf("Ingress %s/%s has no address", cfg.Namespace, NAME)
+		}
+		if !admitted || (expose != ExposeNone && len(url) == 0) {
+			return false, nil
+		}
+
+		if len(url) > 0 {
+			err = probe(ctx, httpClient, url+brdgutil.ListURI)
+		} else {
+			err = answered(coreClient.Services(cfg.Namespace).ProxyGet("http", NAME, strconv.Itoa(port), brdgutil.ListURI, nil).DoRaw(ctx))
+		}
+		if err != nil {
+			pending = fmt.Sprintf("the location service does not answer: %s", err.Error())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err == nil {
+		return url, nil
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if !wait.Interrupted(err) {
+		return "", err
+	}
+	reportPods(ctx, cfg, errOut)
+	return "", util.NewError(util.ExitUnavailable, fmt.Errorf("the bridge in %s was not ready within %s: %s", cfg.Namespace, timeout, pending))
+}
+
+// probe returns an error unless the server at url answers with anything other than a server error
+func probe(ctx context.Context, httpClient *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	rsp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	_, _ = io.Copy(io.Discard, rsp.Body)
+	if rsp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%s returned %s", url, rsp.Status)
+	}
+	return nil
+}
+
+// answered returns nil when the result of a request through the Service proxy came from the location service, even
+// if it is not a success, and the error otherwise; the proxy answers for a Service without ready pods with a 503
+func answered(_ []byte, err error) error {
+	if status, ok := err.(errors.APIStatus); ok && status.Status().Code < http.StatusInternalServerError {
+		return nil
+	}
+	return err
+}
+
+// reportPods writes the state of the containers of the bridge's pods that are not ready, and their events, to errOut
+func reportPods(ctx context.Context, cfg *config.Config, errOut io.Writer) {
+	coreClient, err := cfg.GetCoreClient()
+	if err != nil {
+		return
+	}
+	pods, err := coreClient.Pods(cfg.Namespace).List(ctx, metav1.ListOptions{LabelSelector: labels.SelectorFromSet(map[string]string{"app": NAME}).String()})
+	if err != nil {
+		fmt.Fprintf(errOut, "Error listing the pods of the bridge: %s\n", err.Error())
+		return
+	}
+	if len(pods.Items) == 0 {
+		fmt.Fprintf(errOut, "The bridge has no pods in %s\n", cfg.Namespace)
+	}
+	for _, pod := range pods.Items {
+		fmt.Fprintf(errOut, "Pod %s is %s\n", pod.Name, pod.Status.Phase)
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Waiting != nil {
+				fmt.Fprintf(errOut, "  container %s is waiting: %s %s\n", cs.Name, cs.State.Waiting.Reason, cs.State.Waiting.Message)
+			}
+			if cs.State.Terminated != nil {
+				fmt.Fprintf(errOut, "  container %s terminated: %s %s\n", cs.Name, cs.State.Terminated.Reason, cs.State.Terminated.Message)
+			}
+		}
+		events, err := coreClient.Events(cfg.Namespace).List(ctx, metav1.ListOptions{FieldSelector: fields.Set{
+			"involvedObject.kind": "Pod",
+			"involvedObject.name": pod.Name,
+		}.String()})
+		if err != nil {
+			fmt.Fprintf(errOut, "  Error listing the events of the pod: %s\n", err.Error())
+			continue
+		}
+		for _, event := range events.Items {
+			if event.Type == corev1.EventTypeNormal {
+				continue
+			}
+			fmt.Fprintf(errOut, "  %s %s: %s\n", event.Type, event.Reason, event.Message)
+		}
+	}
+}
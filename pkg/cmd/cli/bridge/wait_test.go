@@ -0,0 +1,82 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	networkingv1client "k8s.io/client-go/kubernetes/typed/networking/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestWaitForReady(t *testing.T) {
+	readyPollInterval = 10 * time.Millisecond
+	defer func() { readyPollInterval = 2 * time.Second }()
+
+	// the location service, which answers once the bridge's Route is admitted
+	probes := 0
+	location := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		probes++
+		common.AssertEqual(t, "/list", r.URL.Path)
+		_, _ = w.Write([]byte(`[]`))
+	})
+	defer location.Close()
+	host := strings.TrimPrefix(location.URL, "http://")
+
+	for _, tc := range []struct {
+		name      string
+		available string
+		url       string
+		exitCode  int
+		errOut    string
+	}{
+		{name: "ready", available: "2", url: location.URL},
+		{name: "not available", available: "1", exitCode: int(util.ExitUnavailable), errOut: "Pod bac-import-model-abc is Pending\n" +
+			"  container location is waiting: ImagePullBackOff Back-off pulling image\n" +
+			"  Warning Failed: Failed to pull image\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			apiServer := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/apis/apps/v1/namespaces/bridge/deployments/" + NAME:
+					_, _ = w.Write([]byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"` + NAME + `","generation":2},"spec":{"replicas":2},"status":{"observedGeneration":2,"updatedReplicas":2,"availableReplicas":` + tc.available + `}}`))
+				case "/apis/route.openshift.io/v1/namespaces/bridge/routes/" + NAME:
+					_, _ = w.Write([]byte(`{"apiVersion":"route.openshift.io/v1","kind":"Route","metadata":{"name":"` + NAME + `"},"status":{"ingress":[{"host":"` + host + `","conditions":[{"type":"Admitted","status":"True"}]}]}}`))
+				case "/api/v1/namespaces/bridge/pods":
+					common.AssertEqual(t, "app="+NAME, r.URL.Query().Get("labelSelector"))
+					_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"PodList","items":[{"metadata":{"name":"bac-import-model-abc"},"status":{"phase":"Pending","containerStatuses":[{"name":"location","state":{"waiting":{"reason":"ImagePullBackOff","message":"Back-off pulling image"}}}]}}]}`))
+				case "/api/v1/namespaces/bridge/events":
+					_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"EventList","items":[{"metadata":{"name":"a"},"type":"Normal","reason":"Pulling","message":"Pulling image"},{"metadata":{"name":"b"},"type":"Warning","reason":"Failed","message":"Failed to pull image"}]}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"NotFound","code":404}`))
+				}
+			})
+			defer apiServer.Close()
+			restCfg := &rest.Config{Host: apiServer.URL, QPS: 1000, Burst: 1000, ContentConfig: rest.ContentConfig{ContentType: "application/json"}}
+			cfg := config.NewConfig()
+			cfg.Namespace = "bridge"
+			cfg.CoreClient, _ = corev1client.NewForConfig(restCfg)
+			cfg.AppsClient, _ = appsv1client.NewForConfig(restCfg)
+			cfg.RouteClient, _ = routev1client.NewForConfig(restCfg)
+			cfg.NetworkingClient, _ = networkingv1client.NewForConfig(restCfg)
+
+			errOut := &bytes.Buffer{}
+			url, err := WaitForReady(context.Background(), cfg, ExposeRoute, 100*time.Millisecond, false, errOut)
+			common.AssertEqual(t, tc.exitCode, util.GetExitCode(err))
+			common.AssertEqual(t, tc.url, url)
+			common.AssertEqual(t, tc.errOut, errOut.String())
+		})
+	}
+	common.AssertEqual(t, 1, probes)
+}
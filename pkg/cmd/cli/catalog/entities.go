@@ -0,0 +1,58 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+)
+
+// ENTITY_URI is the Backstage Catalog REST API for retrieving an entity of any kind by its kind, namespace, and name
+const ENTITY_URI = "/entities/by-name/%s/%s/%s"
+
+// GetEntityByRef retrieves the entity for a 'kind:namespace/name' reference from the Backstage Catalog.  A nil entity
+// and nil error are returned when the entity does not exist.
+func (c *CatalogRESTClientWrapper) GetEntityByRef(ref string) (*backstage.Entity, error) {
+	kind, namespace, name := ParseEntityRef(ref, "component")
+	u := c.RootURL + fmt.Sprintf(ENTITY_URI, kind, namespace, name)
+	buf, err := c.fetch(u, nil)
+	if util.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entity := &backstage.Entity{}
+	err = json.Unmarshal(buf, entity)
+	if err != nil {
+		return nil, fmt.Errorf("json unmarshall error for %s: %s", string(buf), err.Error())
+	}
+	return entity, nil
+}
+
+// ListAIEntities retrieves the AI related Components, Resources, and APIs in the Backstage Catalog which match the
+// owner, lifecycle, and namespace settings of opts.
+func (c *CatalogRESTClientWrapper) ListAIEntities(opts *QueryOptions) ([]backstage.Entity, error) {
+	filterOpts := &QueryOptions{}
+	if opts != nil {
+		// the limit, fields, and ordering settings are meant for the listing commands and would leave us with an
+		// incomplete set of entities, or entities without their relations
+		filterOpts = &QueryOptions{Owner: opts.Owner, Lifecycle: opts.Lifecycle, Namespace: opts.Namespace, All: opts.All}
+	}
+	entities := []backstage.Entity{}
+	for _, kt := range [][2]string{{"component", backstage.COMPONENT_TYPE}, {"resource", backstage.RESOURCE_TYPE}, {"api", ""}} {
+		err := c.QueryEntities(kt[0], kt[1], nil, filterOpts, func(item json.RawMessage) (bool, error) {
+			entity := backstage.Entity{}
+			if err := json.Unmarshal(item, &entity); err != nil {
+				return false, fmt.Errorf("json unmarshall error for %s: %s", string(item), err.Error())
+			}
+			entities = append(entities, entity)
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entities, nil
+}
@@ -0,0 +1,107 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+)
+
+// ListEntities writes every entity in the Backstage Catalog, regardless of whether it is AI related, to writer as
+// indented JSON
+func (c *CatalogRESTClientWrapper) ListEntities(writer io.Writer) error {
+	buf, err := c.fetch(c.RootURL+rest.ENTITIES_URI, nil)
+	if err != nil {
+		return err
+	}
+	return writeIndented(writer, buf)
+}
+
+// GetLocations writes the Locations with the provided IDs, or all Locations if no IDs are provided, to writer as
+// indented JSON
+func (c *CatalogRESTClientWrapper) GetLocations(writer io.Writer, ids ...string) error {
+	if len(ids) == 0 {
+		ids = []string{""}
+	}
+	for _, id := range ids {
+		u := c.RootURL + rest.LOCATION_URI
+		if len(id) > 0 {
+			u = u + "/" + id
+		}
+		buf, err := c.fetch(u, nil)
+		if err != nil {
+			return err
+		}
+		err = writeIndented(writer, buf)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportLocation registers the URL target as a Location in the Backstage Catalog, returning the JSON response from
+// Backstage; a response which is not JSON, or has neither the Location nor its ID, is a validation error
+func (c *CatalogRESTClientWrapper) ImportLocation(target string) (map[string]any, error) {
+	// like the bridge, GitHub URLs are processed by the Backstage 'url' processor, and everything else is presumed
+	// to be served by the bridge's location service
+	body := map[string]interface{}{"target": target, "type": "rhdh-rhoai-bridge"}
+	if strings.Contains(target, "github") {
+		body["type"] = "url"
+	}
+	u := c.RootURL + rest.LOCATION_URI
+	req, err := c.request()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := req.SetBody(body).Post(u)
+	if err != nil {
+		return nil, util.NewTransportError(err)
+	}
+	var buf []byte
+	buf, err = c.processUpdate(resp, u, "post")
+	if err != nil {
+		return nil, err
+	}
+	retJSON := map[string]any{}
+	err = json.Unmarshal(buf, &retJSON)
+	if err != nil {
+		return nil, util.NewValidationError("json unmarshall error for %s: %s", string(buf), err.Error())
+	}
+	_, hasLocation := retJSON["location"]
+	_, hasID := retJSON["id"]
+	if !hasLocation && !hasID {
+		return nil, util.NewValidationError("post for %s returned no location: %s", u, string(buf))
+	}
+	return retJSON, nil
+}
+
+// DeleteLocation removes the Location with the provided ID, along with the entities it defined, from the Backstage
+// Catalog
+func (c *CatalogRESTClientWrapper) DeleteLocation(id string) error {
+	u := c.RootURL + rest.LOCATION_URI + "/" + id
+	req, err := c.request()
+	if err != nil {
+		return err
+	}
+	resp, err := req.Delete(u)
+	if err != nil {
+		return util.NewTransportError(err)
+	}
+	_, err = c.processUpdate(resp, u, "delete")
+	return err
+}
+
+func writeIndented(writer io.Writer, buf []byte) error {
+	buffer := &bytes.Buffer{}
+	err := json.Indent(buffer, buf, "", "    ")
+	if err != nil {
+		return err
+	}
+	buffer.WriteString("\n")
+	_, err = writer.Write(buffer.Bytes())
+	return err
+}
@@ -0,0 +1,52 @@
+package catalog
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+)
+
+func TestImportLocation(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		response string
+		errorStr string
+	}{
+		{
+			name:     "location",
+			response: `{"location":{"id":"1234","target":"https://github.com/foo/bar/catalog-info.yaml"},"entities":[]}`,
+		},
+		{
+			name:     "not json",
+			response: `<html>login</html>`,
+			errorStr: "json unmarshall error",
+		},
+		{
+			name:     "no location",
+			response: `{"entities":[]}`,
+			errorStr: "returned no location",
+		},
+	} {
+		ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(tc.response))
+		})
+		cfg := config.NewConfig()
+		cfg.BackstageURL = ts.URL
+		retJSON, err := SetupCatalogRESTClient(context.Background(), cfg).ImportLocation("https://github.com/foo/bar/catalog-info.yaml")
+		ts.Close()
+		if len(tc.errorStr) == 0 {
+			common.AssertError(t, err)
+			common.AssertEqual(t, true, retJSON["location"] != nil)
+			continue
+		}
+		if util.GetExitCode(err) != int(util.ExitValidation) || !strings.Contains(err.Error(), tc.errorStr) {
+			t.Errorf("%s: expected a validation error with %q, got %v", tc.name, tc.errorStr, err)
+		}
+	}
+}
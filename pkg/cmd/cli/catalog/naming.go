@@ -0,0 +1,420 @@
+package catalog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	brdgutil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+)
+
+const (
+	// CollisionError and CollisionSuffix are the --on-collision settings
+	CollisionError  = "error"
+	CollisionSuffix = "suffix"
+)
+
+// pulled from makeValidator.ts in the catalog-model package in core backstage
+var entityNamespaceRegexp = regexp.MustCompile("^[a-z0-9]+(-[a-z0-9]+)*$")
+
+// nameTemplateKinds are the kinds a --name-template can be set for
+var nameTemplateKinds = []string{"component", "resource", "api"}
+
+// EntityNameData is what a --name-template is executed with
+type EntityNameData struct {
+	// Namespace is the Kubernetes namespace of a KServe InferenceService, or the serving environment of a Kubeflow
+	// Model Registry inference service, which is empty when the model version is not deployed
+	Namespace string
+	// Name is the name of the InferenceService, or of the registered model
+	Name string
+	// Version is the name of the model version, which is empty for KServe
+	Version string
+	// Kind is the lower case kind of the entity being named
+	Kind string
+}
+
+// ModelEntities are the names of the Component, Resource, and API of a model, and the namespace and System they are in
+type ModelEntities struct {
+	Namespace string
+	System    string
+	Component string
+	Resource  string
+	API       string
+	// Renamed is set when a --name-template, or a suffix per --on-collision, named any of the entities, so the relations
+	// between them are built from
+	// the names rather than taken from the populators
+	Renamed bool
+	// DependsOn are the references of the entities outside the model the Component depends on, like its runtime
+	DependsOn []string
+}
+
+// SharedKeys are the keys of the entities of a model which it shares with other models, like the Component and API
+// the versions of a registered model share; an empty key is the key of the model itself
+type SharedKeys struct {
+	Component string
+	Resource  string
+	API       string
+}
+
+// Namer names the entities of each model per the --entity-namespace, --name-template, and --on-collision settings,
+// keeping track of the names given so two models do not get the same one
+type Namer struct {
+	namespace   string
+	onCollision string
+	templates   map[string]*template.Template
+	// settings is the naming settings as text, for the fingerprints of what was generated with them
+	settings string
+
+	lock sync.Mutex
+	// taken is who was given each lower case 'kind:name', as names are unique regardless of case
+	taken map[string]*nameClaim
+}
+
+// nameClaim is the entity key a name belongs to, and the models which were given it, so the name is released once
+// none of the models sharing the entity has it
+type nameClaim struct {
+	key    string
+	models map[string]bool
+}
+
+// NewNamer returns a usage error when the naming settings of opts are not valid
+func NewNamer(opts *config.EntityOptions) (*Namer, error) {
+	n := &Namer{namespace: opts.Namespace, onCollision: opts.OnCollision, templates: map[string]*template.Template{}, taken: map[string]*nameClaim{}}
+	if len(n.namespace) > 0 && (len(n.namespace) > 63 || !entityNamespaceRegexp.MatchString(n.namespace)) {
+		return nil, util.NewUsageError("%q is not a valid Backstage namespace for --entity-namespace; use up to 63 lower case letters and digits, separated by '-'", n.namespace)
+	}
+	switch n.onCollision {
+	case "":
+		n.onCollision = CollisionError
+	case CollisionError, CollisionSuffix:
+	default:
+		return nil, util.NewUsageError("--on-collision has to be %s or %s, not %q", CollisionError, CollisionSuffix, n.onCollision)
+	}
+	for kind, text := range opts.NameTemplates {
+		kind = strings.ToLower(kind)
+		found := false
+		for _, k := range nameTemplateKinds {
+			found = found || k == kind
+		}
+		if !found {
+			return nil, util.NewUsageError("--name-template can only be set for %s, not %q", strings.Join(nameTemplateKinds, ", "), kind)
+		}
+		tmpl, err := template.New(kind).Parse(text)
+		if err != nil {
+			return nil, util.NewUsageError("parsing the --name-template for %s: %s", kind, err.Error())
+		}
+		n.templates[kind] = tmpl
+	}
+	settings := []string{n.namespace, n.onCollision}
+	for _, kind := range nameTemplateKinds {
+		if tmpl, ok := n.templates[kind]; ok {
+			settings = append(settings, kind+"="+tmpl.Root.String())
+		}
+	}
+	n.settings = strings.Join(settings, "|")
+	return n, nil
+}
+
+// Name returns the entities of model, a key unique to each model, with the names from the templates executed with
+// data, and the names in defaults, which are the names from the populators, for the kinds without one.  The templated
+// names are sanitized like the bridge does, and a name, templated or not, another model already has is an error, or
+// gets a '-2', '-3', etc. suffix, per --on-collision.  The kinds without a name in defaults, like the Resource of an
+// InferenceGraph, are not named.
+func (n *Namer) Name(model string, data EntityNameData, defaults ModelEntities) (ModelEntities, error) {
+	return n.NameShared(model, SharedKeys{}, data, defaults)
+}
+
+// NameShared is Name for a model whose entities of some kinds are shared with other models, which are keyed by shared
+// rather than by model, so the models sharing an entity can give it the same name
+func (n *Namer) NameShared(model string, shared SharedKeys, data EntityNameData, defaults ModelEntities) (ModelEntities, error) {
+	me := defaults
+	me.Namespace = n.namespace
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, entity := range []struct {
+		kind string
+		key  string
+		name *string
+	}{{"component", shared.Component, &me.Component}, {"resource", shared.Resource, &me.Resource}, {"api", shared.API, &me.API}} {
+		if len(*entity.name) == 0 {
+			continue
+		}
+		name, templated, err := n.execute(entity.kind, data)
+		if err != nil {
+			return me, err
+		}
+		if !templated {
+			name = *entity.name
+		}
+		key := entity.key
+		if len(key) == 0 {
+			key = model
+		}
+		claimed, err := n.claim(model, key, entity.kind, name, templated)
+		if err != nil {
+			return me, err
+		}
+		me.Renamed = me.Renamed || templated || claimed != *entity.name
+		*entity.name = claimed
+	}
+	return me, nil
+}
+
+// Claim gives name, or a suffixed one per --on-collision, of kind to model, for the entities which are not the
+// Component, Resource, or API of a model, like the Resources of the runtimes models are served by; claiming the name
+// of the same model again returns the name it was given
+func (n *Namer) Claim(model, kind, name string) (string, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.claim(model, model, kind, name, false)
+}
+
+// Lookup returns the names Name gives, or gave, the entities of model, without claiming them, for the entities which
+// only refer to the model, like the Components of the InferenceGraphs whose steps target it
+func (n *Namer) Lookup(model string, data EntityNameData, defaults ModelEntities) (ModelEntities, error) {
+	me := defaults
+	me.Namespace = n.namespace
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, entity := range []struct {
+		kind string
+		name *string
+	}{{"component", &me.Component}, {"resource", &me.Resource}, {"api", &me.API}} {
+		if len(*entity.name) == 0 {
+			continue
+		}
+		name, templated, err := n.execute(entity.kind, data)
+		if err != nil {
+			return me, err
+		}
+		if !templated {
+			name = *entity.name
+		}
+		claimed := n.claimed(model, entity.kind, name)
+		me.Renamed = me.Renamed || templated || claimed != *entity.name
+		*entity.name = claimed
+	}
+	return me, nil
+}
+
+// execute returns the sanitized name the --name-template for kind gives data, and false when kind has no template
+func (n *Namer) execute(kind string, data EntityNameData) (string, bool, error) {
+	tmpl, ok := n.templates[kind]
+	if !ok {
+		return "", false, nil
+	}
+	data.Kind = kind
+	buf := &strings.Builder{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", false, util.NewValidationError("executing the --name-template for %s: %s", kind, err.Error())
+	}
+	name := brdgutil.SanitizeName(buf.String())
+	if len(name) == 0 {
+		return "", false, util.NewValidationError("the --name-template for %s gives %q, which is not a valid entity name", kind, buf.String())
+	}
+	return name, true, nil
+}
+
+// Namespace is the Backstage namespace of the entities, which is empty for the default one
+func (n *Namer) Namespace() string {
+	return n.namespace
+}
+
+// Settings returns the naming settings as text, so a change to them can be told from a fingerprint
+func (n *Namer) Settings() string {
+	return n.settings
+}
+
+// Release forgets the names of model, and of the models whose key starts with model and a '/', when they are removed;
+// the name of a shared entity is forgotten once none of the models sharing it has it
+func (n *Namer) Release(model string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for name, c := range n.taken {
+		for m := range c.models {
+			if m == model || strings.HasPrefix(m, model+"/") {
+				delete(c.models, m)
+			}
+		}
+		if len(c.models) == 0 {
+			delete(n.taken, name)
+		}
+	}
+}
+
+// claim gives name, or a suffixed one per --on-collision, of kind to model, whose entity of kind is keyed by key;
+// templated is whether name is from a --name-template, for the error
+func (n *Namer) claim(model, key, kind, name string, templated bool) (string, error) {
+	candidate := name
+	for i := 2; ; i++ {
+		taken := kind + ":" + strings.ToLower(candidate)
+		c, ok := n.taken[taken]
+		if !ok {
+			c = &nameClaim{key: key, models: map[string]bool{}}
+			n.taken[taken] = c
+		}
+		if c.key == key {
+			c.models[model] = true
+			return candidate, nil
+		}
+		switch {
+		case n.onCollision != CollisionError:
+		case templated:
+			return "", util.NewError(util.ExitConflict, fmt.Errorf("the --name-template for %s gives %s and %s the same name %q", kind, c.key, key, name))
+		default:
+			return "", util.NewError(util.ExitConflict, fmt.Errorf("%s and %s have the same %s name %q; name them apart with --name-template, or use --on-collision=%s", c.key, key, kind, name, CollisionSuffix))
+		}
+		candidate = suffixed(name, i)
+	}
+}
+
+// claimed returns the name, or the suffixed one per --on-collision, of kind which model was given, and name when model
+// was not given one
+func (n *Namer) claimed(model, kind, name string) string {
+	candidate := name
+	for i := 2; n.onCollision == CollisionSuffix; i++ {
+		c, ok := n.taken[kind+":"+strings.ToLower(candidate)]
+		if !ok {
+			return name
+		}
+		if c.models[model] {
+			return candidate
+		}
+		candidate = suffixed(name, i)
+	}
+	return name
+}
+
+// suffixed returns name with a '-i' suffix, cut so it is at most 63 characters
+func suffixed(name string, i int) string {
+	suffix := fmt.Sprintf("-%d", i)
+	return strings.TrimRight(name[:min(len(name), 63-len(suffix))], "-_.") + suffix
+}
+
+// NamespacePopulator is implemented by the populators of entities in a Backstage namespace other than the default;
+// PrintComponent, PrintResource, and PrintAPI set the metadata.namespace of the entity from it
+type NamespacePopulator interface {
+	GetNamespace() string
+}
+
+// ComponentAs, ResourceAs, and APIAs give the entity of pop the names, namespace, and System of me, and ComponentAs the
+// dependencies of me outside the model
+func ComponentAs(pop backstage.ComponentPopulator, me *ModelEntities) backstage.ComponentPopulator {
+	return &namedComponent{ComponentPopulator: pop, me: me}
+}
+
+func ResourceAs(pop backstage.ResourcePopulator, me *ModelEntities) backstage.ResourcePopulator {
+	return &namedResource{ResourcePopulator: pop, me: me}
+}
+
+func APIAs(pop backstage.APIPopulator, me *ModelEntities) backstage.APIPopulator {
+	return &namedAPI{APIPopulator: pop, me: me}
+}
+
+type namedComponent struct {
+	backstage.ComponentPopulator
+	me *ModelEntities
+}
+
+func (pop *namedComponent) GetName() string {
+	return nameOr(pop.me.Component, pop.ComponentPopulator.GetName())
+}
+
+func (pop *namedComponent) GetNamespace() string {
+	return pop.me.Namespace
+}
+
+func (pop *namedComponent) GetSystem() string {
+	return pop.me.System
+}
+
+func (pop *namedComponent) GetDependsOn() []string {
+	dependsOn := []string{"resource:" + pop.me.Resource, "api:" + pop.me.API}
+	if !pop.me.Renamed {
+		dependsOn = pop.ComponentPopulator.GetDependsOn()
+	}
+	return append(dependsOn, pop.me.DependsOn...)
+}
+
+func (pop *namedComponent) GetProvidedAPIs() []string {
+	return providedAPIs(pop.me, pop.ComponentPopulator.GetProvidedAPIs())
+}
+
+type namedResource struct {
+	backstage.ResourcePopulator
+	me *ModelEntities
+}
+
+func (pop *namedResource) GetName() string {
+	return nameOr(pop.me.Resource, pop.ResourcePopulator.GetName())
+}
+
+func (pop *namedResource) GetNamespace() string {
+	return pop.me.Namespace
+}
+
+func (pop *namedResource) GetSystem() string {
+	return pop.me.System
+}
+
+func (pop *namedResource) GetDependencyOf() []string {
+	if !pop.me.Renamed {
+		return pop.ResourcePopulator.GetDependencyOf()
+	}
+	return []string{"component:" + pop.me.Component}
+}
+
+func (pop *namedResource) GetProvidedAPIs() []string {
+	return providedAPIs(pop.me, pop.ResourcePopulator.GetProvidedAPIs())
+}
+
+type namedAPI struct {
+	backstage.APIPopulator
+	me *ModelEntities
+}
+
+func (pop *namedAPI) GetName() string {
+	return nameOr(pop.me.API, pop.APIPopulator.GetName())
+}
+
+func (pop *namedAPI) GetNamespace() string {
+	return pop.me.Namespace
+}
+
+func (pop *namedAPI) GetSystem() string {
+	return pop.me.System
+}
+
+func (pop *namedAPI) GetDependencyOf() []string {
+	if !pop.me.Renamed {
+		return pop.APIPopulator.GetDependencyOf()
+	}
+	return []string{"component:" + pop.me.Component}
+}
+
+func nameOr(name, populated string) string {
+	if len(name) == 0 {
+		return populated
+	}
+	return name
+}
+
+// providedAPIs refers to the API of me, when renamed, from the entities whose populator provides any
+func providedAPIs(me *ModelEntities, provided []string) []string {
+	if !me.Renamed || len(provided) == 0 {
+		return provided
+	}
+	return []string{me.API}
+}
+
+func namespaceOf(pop interface{}) string {
+	if np, ok := pop.(NamespacePopulator); ok {
+		return np.GetNamespace()
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+)
+
+func TestNamer(t *testing.T) {
+	for _, tc := range []struct {
+		opts     config.EntityOptions
+		errorStr string
+	}{
+		{opts: config.EntityOptions{Namespace: "AI"}, errorStr: `"AI" is not a valid Backstage namespace`},
+		{opts: config.EntityOptions{OnCollision: "ignore"}, errorStr: "--on-collision has to be error or suffix"},
+		{opts: config.EntityOptions{NameTemplates: map[string]string{"system": "{{.Name}}"}}, errorStr: `--name-template can only be set for component, resource, api, not "system"`},
+		{opts: config.EntityOptions{NameTemplates: map[string]string{"api": "{{.Name"}}, errorStr: "parsing the --name-template for api"},
+	} {
+		_, err := NewNamer(&tc.opts)
+		if util.GetExitCode(err) != int(util.ExitUsage) || !strings.Contains(err.Error(), tc.errorStr) {
+			t.Errorf("expected a usage error with %q, got %v", tc.errorStr, err)
+		}
+	}
+
+	defaults := ModelEntities{System: "ggmtest", Component: "mnist", Resource: "v1", API: "mnist"}
+	data := EntityNameData{Namespace: "ggmtest", Name: "mnist", Version: "v1"}
+	namer, err := NewNamer(&config.EntityOptions{Namespace: "ai"})
+	common.AssertError(t, err)
+	me, err := namer.Name("1/2", data, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, ModelEntities{Namespace: "ai", System: "ggmtest", Component: "mnist", Resource: "v1", API: "mnist"}, me)
+
+	opts := &config.EntityOptions{NameTemplates: map[string]string{"Resource": "{{.Namespace}}-{{.Name}}-{{.Version}}", "api": "{{.Name}} {{.Kind}}"}}
+	namer, err = NewNamer(opts)
+	common.AssertError(t, err)
+	me, err = namer.Name("1/2", data, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, ModelEntities{System: "ggmtest", Component: "mnist", Resource: "ggmtest-mnist-v1", API: "mnistapi", Renamed: true}, me)
+	// naming the same model again is not a collision
+	_, err = namer.Name("1/2", data, defaults)
+	common.AssertError(t, err)
+	_, err = namer.Name("1/3", data, ModelEntities{Component: "mnist-3", Resource: "v1", API: "mnist-3"})
+	if util.GetExitCode(err) != int(util.ExitConflict) || !strings.Contains(err.Error(), `gives 1/2 and 1/3 the same name "ggmtest-mnist-v1"`) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+	// the names from the populators are claimed too
+	_, err = namer.Name("1/3", data, defaults)
+	if util.GetExitCode(err) != int(util.ExitConflict) || !strings.Contains(err.Error(), `1/2 and 1/3 have the same component name "mnist"`) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+	namer.Release("1")
+	_, err = namer.Name("1/3", data, defaults)
+	common.AssertError(t, err)
+
+	opts.OnCollision = CollisionSuffix
+	namer, err = NewNamer(opts)
+	common.AssertError(t, err)
+	for i, expected := range []string{"ggmtest-mnist-v1", "ggmtest-mnist-v1-2", "ggmtest-mnist-v1-3"} {
+		me, err = namer.Name("1/"+string(rune('a'+i)), data, defaults)
+		common.AssertError(t, err)
+		common.AssertEqual(t, expected, me.Resource)
+	}
+	me, err = namer.Name("1/z", EntityNameData{Name: strings.Repeat("m", 70)}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, strings.Repeat("m", 62), me.Resource)
+	me, err = namer.Name("1/y", EntityNameData{Name: strings.Repeat("m", 70)}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, strings.Repeat("m", 61)+"-2", me.Resource)
+}
+
+func TestNamerShared(t *testing.T) {
+	defaults := ModelEntities{Component: "mnist", Resource: "v1", API: "mnist"}
+	opts := &config.EntityOptions{NameTemplates: map[string]string{"component": "{{.Namespace}}-{{.Name}}", "resource": "{{.Name}}-{{.Version}}", "api": "{{.Name}}"}}
+	namer, err := NewNamer(opts)
+	common.AssertError(t, err)
+	shared := func(mv string) SharedKeys {
+		return SharedKeys{Component: "1", Resource: "1/" + mv, API: "1"}
+	}
+
+	// the versions of a registered model, and their inference services, share its Component and API
+	me, err := namer.NameShared("1/2/10", shared("2"), EntityNameData{Namespace: "dev", Name: "mnist", Version: "v1"}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, ModelEntities{Component: "dev-mnist", Resource: "mnist-v1", API: "mnist", Renamed: true}, me)
+	me, err = namer.NameShared("1/3/11", shared("3"), EntityNameData{Namespace: "dev", Name: "mnist", Version: "v2"}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, ModelEntities{Component: "dev-mnist", Resource: "mnist-v2", API: "mnist", Renamed: true}, me)
+	_, err = namer.NameShared("1/3/12", shared("3"), EntityNameData{Namespace: "prod", Name: "mnist", Version: "v2"}, defaults)
+	common.AssertError(t, err)
+
+	// another registered model cannot have them, until every model sharing them is released
+	_, err = namer.NameShared("4/5", SharedKeys{Component: "4", Resource: "4/5", API: "4"}, EntityNameData{Namespace: "prod", Name: "other", Version: "v1"}, defaults)
+	common.AssertError(t, err)
+	_, err = namer.NameShared("4/6", SharedKeys{Component: "4", Resource: "4/6", API: "4"}, EntityNameData{Namespace: "prod", Name: "mnist", Version: "v3"}, defaults)
+	if util.GetExitCode(err) != int(util.ExitConflict) || !strings.Contains(err.Error(), `gives 1 and 4 the same name "prod-mnist"`) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+	namer.Release("1/3/12")
+	_, err = namer.NameShared("4/6", SharedKeys{Component: "4", Resource: "4/6", API: "4"}, EntityNameData{Namespace: "prod", Name: "mnist", Version: "v3"}, defaults)
+	if util.GetExitCode(err) != int(util.ExitConflict) || !strings.Contains(err.Error(), `the --name-template for api gives 1 and 4 the same name "mnist"`) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+	namer.Release("1/2")
+	namer.Release("1/3")
+	_, err = namer.NameShared("4/6", SharedKeys{Component: "4", Resource: "4/6", API: "4"}, EntityNameData{Namespace: "prod", Name: "mnist", Version: "v3"}, defaults)
+	common.AssertError(t, err)
+
+	// the settings tell namers apart
+	other, err := NewNamer(&config.EntityOptions{NameTemplates: map[string]string{"api": "{{.Name}}-api"}})
+	common.AssertError(t, err)
+	same, err := NewNamer(opts)
+	common.AssertError(t, err)
+	common.AssertEqual(t, namer.Settings(), same.Settings())
+	common.AssertEqual(t, false, namer.Settings() == other.Settings())
+}
+
+func TestNamerLookup(t *testing.T) {
+	defaults := ModelEntities{Component: "default_mnist", Resource: "default_mnist", API: "default_mnist"}
+	namer, err := NewNamer(&config.EntityOptions{OnCollision: CollisionSuffix, NameTemplates: map[string]string{"component": "{{.Name}}"}})
+	common.AssertError(t, err)
+
+	// a lookup gives the name without claiming it, so another model is given it after the lookup
+	me, err := namer.Lookup("default/mnist", EntityNameData{Namespace: "default", Name: "mnist"}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, ModelEntities{Component: "mnist", Resource: "default_mnist", API: "default_mnist", Renamed: true}, me)
+	me, err = namer.Name("prod/mnist", EntityNameData{Namespace: "prod", Name: "mnist"}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, "mnist", me.Component)
+
+	// and a model which was given a suffixed name is looked up by it
+	me, err = namer.Name("default/mnist", EntityNameData{Namespace: "default", Name: "mnist"}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, "mnist-2", me.Component)
+	me, err = namer.Lookup("default/mnist", EntityNameData{Namespace: "default", Name: "mnist"}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, "mnist-2", me.Component)
+	me, err = namer.Lookup("prod/mnist", EntityNameData{Namespace: "prod", Name: "mnist"}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, "mnist", me.Component)
+}
+
+func TestNamerDefaults(t *testing.T) {
+	// registered models named "a b" and "ab" get the same names from the populators, which sanitize them
+	defaults := ModelEntities{Component: "ab", Resource: "v1", API: "ab"}
+	namer, err := NewNamer(&config.EntityOptions{})
+	common.AssertError(t, err)
+	me, err := namer.NameShared("1/2", SharedKeys{Component: "1", Resource: "1/2", API: "1"}, EntityNameData{Name: "a b", Version: "v1"}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, ModelEntities{Component: "ab", Resource: "v1", API: "ab"}, me)
+	_, err = namer.NameShared("3/4", SharedKeys{Component: "3", Resource: "3/4", API: "3"}, EntityNameData{Name: "ab", Version: "v2"}, ModelEntities{Component: "ab", Resource: "v2", API: "ab"})
+	if util.GetExitCode(err) != int(util.ExitConflict) || !strings.Contains(err.Error(), `1 and 3 have the same component name "ab"`) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+
+	// with --on-collision=suffix the second one is renamed, along with the relations between its entities
+	namer, err = NewNamer(&config.EntityOptions{OnCollision: CollisionSuffix})
+	common.AssertError(t, err)
+	_, err = namer.NameShared("1/2", SharedKeys{Component: "1", Resource: "1/2", API: "1"}, EntityNameData{Name: "a b", Version: "v1"}, defaults)
+	common.AssertError(t, err)
+	me, err = namer.NameShared("3/4", SharedKeys{Component: "3", Resource: "3/4", API: "3"}, EntityNameData{Name: "ab", Version: "v1"}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, ModelEntities{Component: "ab-2", Resource: "v1-2", API: "ab-2", Renamed: true}, me)
+	// and is looked up by the suffixed names
+	me, err = namer.Lookup("3/4", EntityNameData{Name: "ab", Version: "v1"}, defaults)
+	common.AssertError(t, err)
+	common.AssertEqual(t, ModelEntities{Component: "ab-2", Resource: "v1-2", API: "ab-2", Renamed: true}, me)
+}
@@ -0,0 +1,121 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+)
+
+// OwnerRef returns the entity reference set as the owner of the entities we generate; owner is either an entity
+// reference with a kind, like 'group:default/ml-platform', or, as it has always been, the name of a user
+func OwnerRef(owner string) string {
+	if strings.Contains(owner, ":") {
+		return owner
+	}
+	return "user:" + owner
+}
+
+// ValidateOwner returns an error unless owner, with the kind defaulting to defaultKind, refers to a User or Group with
+// a valid namespace and name
+func ValidateOwner(owner, defaultKind string) error {
+	kind, namespace, name := ParseEntityRef(owner, defaultKind)
+	switch strings.ToLower(kind) {
+	case "user", "group":
+	default:
+		return util.NewUsageError("the owner %q has to be a user or group, not a %s", owner, kind)
+	}
+	for _, n := range []string{namespace, name} {
+		if len(n) == 0 || len(n) > 63 || !entityNameRegexp.MatchString(n) {
+			return util.NewUsageError("the owner %q is not a valid entity reference; use [user:|group:][namespace/]name", owner)
+		}
+	}
+	return nil
+}
+
+// Owners resolves the owner of the entities of each model: the owner given on the command line, unless the model's
+// namespace label or custom property, per the config.EntityOptions, names another one.  When verification is on,
+// each owner is looked up in the Backstage catalog once.
+type Owners struct {
+	// Default is the owner of the entities of models whose owner is not mapped
+	Default string
+	opts    *config.EntityOptions
+	client  *CatalogRESTClientWrapper
+
+	lock     sync.Mutex
+	verified map[string]error
+}
+
+// NewOwners validates owner and the --owner-map, and, with --verify-owner, that owner is in the Backstage catalog
+func NewOwners(ctx context.Context, cfg *config.Config, owner string) (*Owners, error) {
+	if err := ValidateOwner(owner, "user"); err != nil {
+		return nil, err
+	}
+	if len(cfg.Entities.OwnerMap) > 0 && len(cfg.Entities.OwnerLabel) == 0 && len(cfg.Entities.OwnerProperty) == 0 {
+		return nil, util.NewUsageError("--owner-map can only be used with --owner-label or --owner-property")
+	}
+	for value, mapped := range cfg.Entities.OwnerMap {
+		if err := ValidateOwner(mapped, "group"); err != nil {
+			return nil, util.NewUsageError("--owner-map %s=%s: %s", value, mapped, err.Error())
+		}
+	}
+	o := &Owners{Default: owner, opts: &cfg.Entities, verified: map[string]error{}}
+	if cfg.Entities.VerifyOwner {
+		o.client = SetupCatalogRESTClient(ctx, cfg)
+	}
+	if err := o.verify(OwnerRef(owner)); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Resolve returns the owner of the entities of a model whose namespace label or custom property has value, which
+// is the Default when value is empty
+func (o *Owners) Resolve(value string) (string, error) {
+	if len(value) == 0 {
+		return o.Default, nil
+	}
+	owner, ok := o.opts.OwnerMap[value]
+	if !ok {
+		if err := ValidateOwner(value, "group"); err != nil {
+			return "", util.NewValidationError("the mapped owner %q is not valid: %s", value, err.Error())
+		}
+		owner = value
+		if !strings.Contains(owner, ":") {
+			owner = "group:" + owner
+		}
+	}
+	return owner, o.verify(owner)
+}
+
+// Label and Property return the namespace label and registered model custom property which name the owner of the
+// entities of a model, which are empty when the owner is not mapped
+func (o *Owners) Label() string {
+	return o.opts.OwnerLabel
+}
+
+func (o *Owners) Property() string {
+	return o.opts.OwnerProperty
+}
+
+func (o *Owners) verify(ref string) error {
+	if o.client == nil {
+		return nil
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if err, ok := o.verified[ref]; ok {
+		return err
+	}
+	entity, err := o.client.GetEntityByRef(ref)
+	if err == nil && entity == nil {
+		err = util.NewNotFoundError("the owner %s is not in the Backstage catalog", ref)
+	}
+	// failures to reach the catalog are not remembered, so a watch or poll tries again with the next model
+	if err == nil || util.IsNotFound(err) {
+		o.verified[ref] = err
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package catalog
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+)
+
+func TestOwners(t *testing.T) {
+	requests := []string{}
+	ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"kind":"Group","metadata":{"name":"found"}}`))
+	})
+	defer ts.Close()
+
+	common.AssertEqual(t, "user:jdoe", OwnerRef("jdoe"))
+	common.AssertEqual(t, "group:default/ml-platform", OwnerRef("group:default/ml-platform"))
+	for _, owner := range []string{"jdoe", "user:jdoe", "Group:ai/ml-platform"} {
+		common.AssertError(t, ValidateOwner(owner, "user"))
+	}
+	for _, owner := range []string{"component:jdoe", "group:", "user:j doe", "a/b/c"} {
+		if util.GetExitCode(ValidateOwner(owner, "user")) != int(util.ExitUsage) {
+			t.Errorf("expected a usage error for %q", owner)
+		}
+	}
+
+	cfg := config.NewConfig()
+	cfg.BackstageURL = ts.URL
+	cfg.Entities.OwnerMap = map[string]string{"ds": "group:default/data-science"}
+	_, err := NewOwners(context.Background(), cfg, "Owner")
+	if util.GetExitCode(err) != int(util.ExitUsage) {
+		t.Errorf("expected a usage error for --owner-map without --owner-label or --owner-property, got %v", err)
+	}
+
+	cfg.Entities.OwnerLabel = "team"
+	owners, err := NewOwners(context.Background(), cfg, "Owner")
+	common.AssertError(t, err)
+	for value, expected := range map[string]string{"": "Owner", "ds": "group:default/data-science", "found": "group:found", "user:found": "user:found"} {
+		owner, err := owners.Resolve(value)
+		common.AssertError(t, err)
+		common.AssertEqual(t, expected, owner)
+	}
+	_, err = owners.Resolve("not valid")
+	if util.GetExitCode(err) != int(util.ExitValidation) {
+		t.Errorf("expected a validation error for an invalid mapped owner, got %v", err)
+	}
+	common.AssertEqual(t, 0, len(requests))
+
+	cfg.Entities.VerifyOwner = true
+	_, err = NewOwners(context.Background(), cfg, "group:missing")
+	if util.GetExitCode(err) != int(util.ExitNotFound) {
+		t.Errorf("expected a not found error for a missing owner, got %v", err)
+	}
+	owners, err = NewOwners(context.Background(), cfg, "found")
+	common.AssertError(t, err)
+	for _, value := range []string{"found", "found", "missing"} {
+		_, err = owners.Resolve(value)
+		if (value == "missing") != util.IsNotFound(err) {
+			t.Errorf("unexpected verification result for %q: %v", value, err)
+		}
+	}
+	// each owner is looked up once
+	common.AssertEqual(t, []string{
+		"/api/catalog/entities/by-name/group/default/missing",
+		"/api/catalog/entities/by-name/user/default/found",
+		"/api/catalog/entities/by-name/group/default/found",
+		"/api/catalog/entities/by-name/group/default/missing",
+	}, requests)
+}
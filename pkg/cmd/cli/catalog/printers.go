@@ -0,0 +1,195 @@
+package catalog
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// API_TYPE_LABEL marks the API entities we generate as AI related; unlike Components and Resources, the spec.type
+	// of an API has to be one of the formats Backstage supports (openapi, grpc, etc.), so we cannot use it to
+	// distinguish our APIs from the rest of the catalog
+	API_TYPE_LABEL         = "rhdh.modelcatalog.io/api-type"
+	MODEL_SERVICE_API_TYPE = "model-service-api"
+)
+
+// TypePopulator is implemented by the populators of Components and Resources whose spec.type is not the bridge's
+// model-server or ai-model, like the runtimes models are served by, and of APIs whose spec.type cannot be told from
+// their definition
+type TypePopulator interface {
+	GetType() string
+}
+
+// AnnotationPopulator is implemented by the populators of entities with annotations beyond the techdocs reference
+type AnnotationPopulator interface {
+	GetAnnotations() map[string]string
+}
+
+// PrintComponent mirrors the bridge's backstage.PrintComponent, but sorts the lists of the entity so the output is the
+// same from one run to the next
+func PrintComponent(pop backstage.ComponentPopulator, writer io.Writer) error {
+	component := &backstage.ComponentEntityV1alpha1{
+		Kind:       "Component",
+		ApiVersion: backstage.VERSION,
+		Entity:     buildEntity("Component", pop),
+	}
+	component.Entity.Metadata.Annotations = annotationsOf(pop)
+	component.Metadata = component.Entity.Metadata
+	component.Spec = &backstage.ComponentEntityV1alpha1Spec{
+		Type:         typeOf(pop, backstage.COMPONENT_TYPE),
+		Lifecycle:    pop.GetLifecycle(),
+		Owner:        OwnerRef(pop.GetOwner()),
+		ProvidesApis: sortStrings(pop.GetProvidedAPIs()),
+		DependsOn:    sortStrings(pop.GetDependsOn()),
+		System:       systemOf(pop),
+		Profile:      backstage.Profile{DisplayName: pop.GetDisplayName()},
+	}
+	err := util.PrintYaml(component, true, writer)
+	if err != nil {
+		klog.Errorf("ERROR: converting component to yaml and printing: %s, %#v", err.Error(), component)
+		return err
+	}
+	return nil
+}
+
+// PrintResource mirrors the bridge's backstage.PrintResource, but sorts the lists of the entity so the output is the
+// same from one run to the next
+func PrintResource(pop backstage.ResourcePopulator, writer io.Writer) error {
+	resource := &backstage.ResourceEntityV1alpha1{
+		Kind:       "Resource",
+		ApiVersion: backstage.VERSION,
+		Entity:     buildEntity("Resource", pop),
+	}
+	resource.Entity.Metadata.Annotations = annotationsOf(pop)
+	resource.Metadata = resource.Entity.Metadata
+	resource.Spec = &backstage.ResourceEntityV1alpha1Spec{
+		Type:         typeOf(pop, backstage.RESOURCE_TYPE),
+		Owner:        OwnerRef(pop.GetOwner()),
+		Lifecycle:    pop.GetLifecycle(),
+		ProvidesApis: sortStrings(pop.GetProvidedAPIs()),
+		DependencyOf: sortStrings(pop.GetDependencyOf()),
+		System:       systemOf(pop),
+		Profile:      backstage.Profile{DisplayName: pop.GetDisplayName()},
+	}
+	err := util.PrintYaml(resource, true, writer)
+	if err != nil {
+		klog.Errorf("ERROR: converting resource to yaml and printing: %s, %#v", err.Error(), resource)
+		return err
+	}
+	return nil
+}
+
+// PrintAPI mirrors the bridge's backstage.PrintAPI, but adds the API_TYPE_LABEL so 'get apis' can filter for
+// AI related APIs in the Backstage Catalog, and sorts the lists of the entity like PrintComponent
+func PrintAPI(pop backstage.APIPopulator, writer io.Writer) error {
+	api := &backstage.ApiEntityV1alpha1{
+		Kind:       "API",
+		ApiVersion: backstage.VERSION,
+		Entity:     buildEntity("API", pop),
+	}
+	api.Entity.Metadata.Annotations = annotationsOf(pop)
+	api.Entity.Metadata.Labels = map[string]string{API_TYPE_LABEL: MODEL_SERVICE_API_TYPE}
+	api.Metadata = api.Entity.Metadata
+	api.Spec = &backstage.ApiEntityV1alpha1Spec{
+		Lifecycle:    pop.GetLifecycle(),
+		Owner:        OwnerRef(pop.GetOwner()),
+		Definition:   pop.GetDefinition(),
+		DependencyOf: sortStrings(pop.GetDependencyOf()),
+		System:       systemOf(pop),
+		Profile:      backstage.Profile{DisplayName: pop.GetDisplayName()},
+	}
+	api.Spec.Type = typeOf(pop, apiType(api.Spec.Definition))
+
+	err := util.PrintYaml(api, false, writer)
+	if err != nil {
+		klog.Errorf("ERROR: converting api to yaml and printing: %s, %#v", err.Error(), api)
+		return err
+	}
+	return nil
+}
+
+// PrintDocumentSeparator writes the '---' that separates the API printed by PrintAPI, which unlike PrintComponent and
+// PrintResource does not end with one, from the entities printed after it
+func PrintDocumentSeparator(writer io.Writer) {
+	fmt.Fprintln(writer, "---")
+}
+
+func apiType(definition string) string {
+	switch {
+	case strings.Contains(definition, backstage.OPENAPI_API_TYPE):
+		return backstage.OPENAPI_API_TYPE
+	case strings.Contains(definition, backstage.ASYNCAPI_API_TYPE):
+		return backstage.ASYNCAPI_API_TYPE
+	case strings.Contains(definition, backstage.GRAPHQL_API_TYPE):
+		return backstage.GRAPHQL_API_TYPE
+	case strings.Contains(definition, backstage.TRPC_API_TYPE):
+		return backstage.TRPC_API_TYPE
+	case strings.Contains(definition, "proto"):
+		return backstage.GRPC_API_TYPE
+	default:
+		return backstage.UNKNOWN_API_TYPE
+	}
+}
+
+func typeOf(pop interface{}, defaultType string) string {
+	if tp, ok := pop.(TypePopulator); ok {
+		return tp.GetType()
+	}
+	return defaultType
+}
+
+func annotationsOf(pop backstage.CommonPopulator) map[string]string {
+	annotations := map[string]string{}
+	if ap, ok := pop.(AnnotationPopulator); ok {
+		for key, value := range ap.GetAnnotations() {
+			annotations[key] = value
+		}
+	}
+	annotations[backstage.TECHDOC_REFS] = pop.GetTechdocRef()
+	return annotations
+}
+
+func buildEntity(kind string, pop backstage.CommonPopulator) backstage.Entity {
+	return backstage.Entity{
+		Kind:       kind,
+		ApiVersion: backstage.VERSION,
+		Metadata: backstage.EntityMeta{
+			Name:        pop.GetName(),
+			Namespace:   namespaceOf(pop),
+			Description: pop.GetDescription(),
+			Tags:        sortStrings(pop.GetTags()),
+			Links:       sortLinks(pop.GetLinks()),
+		},
+	}
+}
+
+// sortStrings sorts values and drops duplicates, as the populators build lists like tags from maps, whose iteration
+// order changes from one run to the next; the annotations and labels are maps, which are already marshaled with sorted
+// keys
+func sortStrings(values []string) []string {
+	slices.Sort(values)
+	return slices.Compact(values)
+}
+
+// sortLinks sorts links by title, then URL, dropping duplicates
+func sortLinks(links []backstage.EntityLink) []backstage.EntityLink {
+	slices.SortFunc(links, func(a, b backstage.EntityLink) int {
+		if c := strings.Compare(a.Title, b.Title); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.URL, b.URL); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Icon, b.Icon)
+	})
+	return slices.Compact(links)
+}
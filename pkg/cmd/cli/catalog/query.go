@@ -0,0 +1,289 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultPageSize is the number of entities requested from the Backstage Catalog per page
+	DefaultPageSize = 100
+
+	tagsField = "metadata.tags"
+)
+
+// QueryOptions are the settings for the 'get' commands that are pushed to the Backstage Catalog 'by-query' REST API
+type QueryOptions struct {
+	Owner       string
+	Lifecycle   string
+	Namespace   string
+	Limit       int
+	Fields      []string
+	OrderFields []string
+	FullText    string
+	// All disables the filtering of APIs on the API_TYPE_LABEL, returning every API in the Backstage Catalog
+	All bool
+}
+
+type queryPage struct {
+	Items      []json.RawMessage `json:"items"`
+	TotalItems int               `json:"totalItems"`
+	PageInfo   struct {
+		NextCursor string `json:"nextCursor,omitempty"`
+	} `json:"pageInfo"`
+}
+
+type taggedEntity struct {
+	Metadata struct {
+		Tags []string `json:"tags"`
+	} `json:"metadata"`
+}
+
+// ListComponents streams the AI related Components in the Backstage Catalog to writer as a JSON array
+func (c *CatalogRESTClientWrapper) ListComponents(writer io.Writer, opts *QueryOptions, args ...string) error {
+	return c.streamQuery(writer, "component", backstage.COMPONENT_TYPE, opts, args)
+}
+
+// ListResources streams the AI related Resources in the Backstage Catalog to writer as a JSON array
+func (c *CatalogRESTClientWrapper) ListResources(writer io.Writer, opts *QueryOptions, args ...string) error {
+	return c.streamQuery(writer, "resource", backstage.RESOURCE_TYPE, opts, args)
+}
+
+// ListAPIs streams the AI related APIs in the Backstage Catalog to writer as a JSON array, or all APIs if opts.All is set
+func (c *CatalogRESTClientWrapper) ListAPIs(writer io.Writer, opts *QueryOptions, args ...string) error {
+	return c.streamQuery(writer, "api", "", opts, args)
+}
+
+// GetComponents streams the Components with the provided 'namespace:name' keys, or if no keys are provided or the
+// arguments are tags, the result of ListComponents
+func (c *CatalogRESTClientWrapper) GetComponents(writer io.Writer, opts *QueryOptions, args ...string) error {
+	if len(args) == 0 || c.Tags {
+		return c.ListComponents(writer, opts, args...)
+	}
+	return c.streamByName(writer, rest.COMPONENT_URI, args)
+}
+
+// GetResources streams the Resources with the provided 'namespace:name' keys, or if no keys are provided or the
+// arguments are tags, the result of ListResources
+func (c *CatalogRESTClientWrapper) GetResources(writer io.Writer, opts *QueryOptions, args ...string) error {
+	if len(args) == 0 || c.Tags {
+		return c.ListResources(writer, opts, args...)
+	}
+	return c.streamByName(writer, rest.RESOURCE_URI, args)
+}
+
+// GetAPIs streams the APIs with the provided 'namespace:name' keys, or if no keys are provided or the arguments
+// are tags, the result of ListAPIs
+func (c *CatalogRESTClientWrapper) GetAPIs(writer io.Writer, opts *QueryOptions, args ...string) error {
+	if len(args) == 0 || c.Tags {
+		return c.ListAPIs(writer, opts, args...)
+	}
+	return c.streamByName(writer, rest.API_URI, args)
+}
+
+func (c *CatalogRESTClientWrapper) streamByName(writer io.Writer, uriFormat string, args []string) error {
+	out := &jsonArrayStreamer{writer: writer}
+	for _, arg := range args {
+		namespace := rest.DEFAULT_NS
+		name := arg
+		if i := strings.Index(arg, ":"); i >= 0 {
+			namespace = arg[:i]
+			name = arg[i+1:]
+		}
+		u := c.RootURL + fmt.Sprintf(uriFormat, namespace, name)
+		buf, err := c.fetch(u, nil)
+		if err != nil {
+			return err
+		}
+		err = out.add(buf)
+		if err != nil {
+			return err
+		}
+	}
+	return out.close()
+}
+
+func (c *CatalogRESTClientWrapper) streamQuery(writer io.Writer, kind, specType string, opts *QueryOptions, args []string) error {
+	if opts == nil {
+		opts = &QueryOptions{}
+	}
+	tags := []string{}
+	if c.Tags {
+		tags = args
+	}
+	out := &jsonArrayStreamer{writer: writer}
+	err := c.QueryEntities(kind, specType, tags, opts, func(item json.RawMessage) (bool, error) {
+		if len(tags) > 0 {
+			te := &taggedEntity{}
+			if err := json.Unmarshal(item, te); err != nil {
+				return false, err
+			}
+			// the Backstage filter on tags returns entities with any of the tags, so we still have to apply the exact
+			// match or subset semantics here
+			switch {
+			case c.Subset && !tagsIncluded(tags, te.Metadata.Tags):
+				return false, nil
+			case !c.Subset && !tagsMatch(tags, te.Metadata.Tags):
+				return false, nil
+			}
+		}
+		return true, out.add(item)
+	})
+	if err != nil {
+		return err
+	}
+	return out.close()
+}
+
+// QueryEntities walks the Backstage Catalog 'by-query' REST API, following the page cursor, and calls fn with each
+// entity returned.  The kind, spec type, tags, and the filtering options are pushed to Backstage as the 'filter' query
+// parameter.  fn reports whether it kept the entity, as it may filter further, like on the exact tags.  Walking stops
+// after opts.Limit entities are kept when a limit is set, or when fn returns an error.
+func (c *CatalogRESTClientWrapper) QueryEntities(kind, specType string, tags []string, opts *QueryOptions, fn func(item json.RawMessage) (bool, error)) error {
+	if opts == nil {
+		opts = &QueryOptions{}
+	}
+	qparams := BuildQueryParams(kind, specType, tags, opts)
+	u := c.RootURL + rest.QUERY_URI
+	count := 0
+	for {
+		pageSize := DefaultPageSize
+		if opts.Limit > 0 && opts.Limit-count < pageSize {
+			pageSize = opts.Limit - count
+		}
+		qparams.Set("limit", strconv.Itoa(pageSize))
+
+		buf, err := c.fetch(u, qparams)
+		if err != nil {
+			return err
+		}
+		page := &queryPage{}
+		err = json.Unmarshal(buf, page)
+		if err != nil {
+			return fmt.Errorf("json unmarshall error for %s: %s", string(buf), err.Error())
+		}
+		klog.V(4).Infof("query for %s returned %d of %d items", u, len(page.Items), page.TotalItems)
+
+		for _, item := range page.Items {
+			kept, err := fn(item)
+			if err != nil {
+				return err
+			}
+			if !kept {
+				continue
+			}
+			count++
+			if opts.Limit > 0 && count >= opts.Limit {
+				return nil
+			}
+		}
+
+		if len(page.PageInfo.NextCursor) == 0 || len(page.Items) == 0 {
+			return nil
+		}
+		// per the Backstage REST API, the cursor encodes the filter, ordering, and full text settings of the
+		// original query, so only the cursor, limit, and fields are sent for subsequent pages
+		next := url.Values{"cursor": []string{page.PageInfo.NextCursor}}
+		if qparams.Has("fields") {
+			next["fields"] = qparams["fields"]
+		}
+		qparams = next
+	}
+}
+
+// BuildQueryParams converts the kind, spec type, tags, and query options into the query parameters for the Backstage
+// Catalog 'by-query' REST API.
+func BuildQueryParams(kind, specType string, tags []string, opts *QueryOptions) url.Values {
+	// example 'filter' value from swagger doc:  'kind=component,metadata.annotations.backstage.io/orphan=true'
+	// conditions within a single filter are AND'ed, except for repeated keys, whose values are OR'ed
+	filter := []string{"kind=" + kind}
+	if len(specType) > 0 {
+		filter = append(filter, "spec.type="+specType)
+	}
+	// the spec.type of an API is its format, so our AI related APIs are identified by label instead
+	if strings.EqualFold(kind, "api") && !opts.All {
+		filter = append(filter, "metadata.labels."+API_TYPE_LABEL+"="+MODEL_SERVICE_API_TYPE)
+	}
+	if len(opts.Owner) > 0 {
+		filter = append(filter, "relations.ownedBy="+NormalizeEntityRef(opts.Owner, "user"))
+	}
+	if len(opts.Lifecycle) > 0 {
+		filter = append(filter, "spec.lifecycle="+opts.Lifecycle)
+	}
+	if len(opts.Namespace) > 0 {
+		filter = append(filter, "metadata.namespace="+opts.Namespace)
+	}
+	for _, tag := range tags {
+		filter = append(filter, tagsField+"="+tag)
+	}
+	qparams := url.Values{
+		"filter": []string{strings.Join(filter, ",")},
+	}
+
+	if len(opts.Fields) > 0 {
+		fields := append([]string{}, opts.Fields...)
+		// we need the tags to apply the exact match or subset check
+		if len(tags) > 0 && !containsField(fields, tagsField) {
+			fields = append(fields, tagsField)
+		}
+		qparams["fields"] = []string{strings.Join(fields, ",")}
+	}
+	for _, orderField := range opts.OrderFields {
+		qparams.Add("orderField", orderField)
+	}
+	if len(opts.FullText) > 0 {
+		qparams.Set("fullTextFilterTerm", opts.FullText)
+	}
+	return qparams
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field || strings.HasPrefix(field, f+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonArrayStreamer writes entities as the elements of an indented JSON array as they arrive, so that large results
+// do not need to be held in memory before being printed
+type jsonArrayStreamer struct {
+	writer io.Writer
+	count  int
+}
+
+func (s *jsonArrayStreamer) add(item json.RawMessage) error {
+	buffer := &bytes.Buffer{}
+	if s.count == 0 {
+		buffer.WriteString("[\n    ")
+	} else {
+		buffer.WriteString(",\n    ")
+	}
+	err := json.Indent(buffer, item, "    ", "    ")
+	if err != nil {
+		return err
+	}
+	s.count++
+	_, err = s.writer.Write(buffer.Bytes())
+	return err
+}
+
+func (s *jsonArrayStreamer) close() error {
+	var err error
+	if s.count == 0 {
+		_, err = io.WriteString(s.writer, "[]\n")
+		return err
+	}
+	_, err = io.WriteString(s.writer, "\n]\n")
+	return err
+}
@@ -0,0 +1,213 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"golang.org/x/oauth2"
+)
+
+func TestBuildQueryParams(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		kind     string
+		specType string
+		tags     []string
+		opts     *QueryOptions
+		expected url.Values
+	}{
+		{
+			name:     "kind and type only",
+			kind:     "component",
+			specType: "model-server",
+			opts:     &QueryOptions{},
+			expected: url.Values{"filter": []string{"kind=component,spec.type=model-server"}},
+		},
+		{
+			name:     "owner lifecycle namespace and tags",
+			kind:     "resource",
+			specType: "ai-model",
+			tags:     []string{"genai", "vllm"},
+			opts:     &QueryOptions{Owner: "group:ml-platform", Lifecycle: "production", Namespace: "ai"},
+			expected: url.Values{"filter": []string{"kind=resource,spec.type=ai-model,relations.ownedBy=group:default/ml-platform,spec.lifecycle=production,metadata.namespace=ai,metadata.tags=genai,metadata.tags=vllm"}},
+		},
+		{
+			name: "user owner default and fields order and full text",
+			kind: "api",
+			tags: []string{"genai"},
+			opts: &QueryOptions{Owner: "jdoe", Fields: []string{"metadata.name"}, OrderFields: []string{"metadata.name,desc", "spec.type"}, FullText: "granite"},
+			expected: url.Values{
+				"filter":             []string{"kind=api,metadata.labels.rhdh.modelcatalog.io/api-type=model-service-api,relations.ownedBy=user:default/jdoe,metadata.tags=genai"},
+				"fields":             []string{"metadata.name,metadata.tags"},
+				"orderField":         []string{"metadata.name,desc", "spec.type"},
+				"fullTextFilterTerm": []string{"granite"},
+			},
+		},
+		{
+			name:     "fields already include tags",
+			kind:     "api",
+			tags:     []string{"genai"},
+			opts:     &QueryOptions{Fields: []string{"metadata"}, All: true},
+			expected: url.Values{"filter": []string{"kind=api,metadata.tags=genai"}, "fields": []string{"metadata"}},
+		},
+		{
+			name:     "all has no effect on components",
+			kind:     "component",
+			specType: "model-server",
+			opts:     &QueryOptions{All: true},
+			expected: url.Values{"filter": []string{"kind=component,spec.type=model-server"}},
+		},
+	} {
+		got := BuildQueryParams(tc.kind, tc.specType, tc.tags, tc.opts)
+		if !common.Equal(tc.expected, got) {
+			t.Errorf("%s: expected %#v got %#v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestListComponents(t *testing.T) {
+	queries := []url.Values{}
+	ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.HasSuffix(r.URL.Path, rest.QUERY_URI) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		queries = append(queries, r.URL.Query())
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			_, _ = w.Write([]byte(pageOne))
+		case "page2":
+			_, _ = w.Write([]byte(pageTwo))
+		}
+	})
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		tags     bool
+		subset   bool
+		opts     *QueryOptions
+		expected []string
+		requests int
+	}{
+		{
+			name:     "all pages",
+			opts:     &QueryOptions{},
+			expected: []string{"model-1", "model-2", "model-3"},
+			requests: 2,
+		},
+		{
+			name:     "limit stops paging",
+			opts:     &QueryOptions{Limit: 2},
+			expected: []string{"model-1", "model-2"},
+			requests: 1,
+		},
+		{
+			name:     "exact tags",
+			args:     []string{"vllm", "genai"},
+			tags:     true,
+			opts:     &QueryOptions{},
+			expected: []string{"model-1"},
+			requests: 2,
+		},
+		{
+			name:     "subset tags",
+			args:     []string{"genai"},
+			tags:     true,
+			subset:   true,
+			opts:     &QueryOptions{},
+			expected: []string{"model-1", "model-3"},
+			requests: 2,
+		},
+		{
+			name:     "limit counts only the entities with the tags",
+			args:     []string{"genai"},
+			tags:     true,
+			subset:   true,
+			opts:     &QueryOptions{Limit: 2},
+			expected: []string{"model-1", "model-3"},
+			requests: 2,
+		},
+	} {
+		queries = []url.Values{}
+		cfg := config.NewConfig()
+		cfg.BackstageURL = ts.URL
+		cfg.ParamsAsTags = tc.tags
+		cfg.AnySubsetWorks = tc.subset
+		buf := &bytes.Buffer{}
+		err := SetupCatalogRESTClient(context.Background(), cfg).ListComponents(buf, tc.opts, tc.args...)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err.Error())
+			continue
+		}
+		items := []map[string]interface{}{}
+		err = json.Unmarshal(buf.Bytes(), &items)
+		if err != nil {
+			t.Errorf("%s: output not a JSON array: %s: %s", tc.name, err.Error(), buf.String())
+			continue
+		}
+		names := []string{}
+		for _, item := range items {
+			names = append(names, item["metadata"].(map[string]interface{})["name"].(string))
+		}
+		common.AssertEqual(t, tc.expected, names)
+		if len(queries) != tc.requests {
+			t.Errorf("%s: expected %d requests but got %d", tc.name, tc.requests, len(queries))
+			continue
+		}
+		if queries[0].Get("filter") == "" || queries[0].Has("cursor") {
+			t.Errorf("%s: first request should have a filter and no cursor: %#v", tc.name, queries[0])
+		}
+		if len(queries) > 1 && (queries[1].Has("filter") || queries[1].Get("cursor") != "page2") {
+			t.Errorf("%s: second request should only have the cursor: %#v", tc.name, queries[1])
+		}
+	}
+}
+
+func TestListComponentsEmpty(t *testing.T) {
+	ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"items":[],"totalItems":0,"pageInfo":{}}`))
+	})
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	cfg := config.NewConfig()
+	cfg.BackstageURL = ts.URL
+	err := SetupCatalogRESTClient(context.Background(), cfg).ListComponents(buf, nil)
+	common.AssertError(t, err)
+	common.AssertEqual(t, "[]\n", buf.String())
+}
+
+func TestRequestTokenSource(t *testing.T) {
+	authHeaders := []string{}
+	ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"items":[],"totalItems":0,"pageInfo":{}}`))
+	})
+	defer ts.Close()
+	cfg := config.NewConfig()
+	cfg.BackstageURL = ts.URL
+	cfg.BackstageToken = "flag-token"
+	err := SetupCatalogRESTClient(context.Background(), cfg).ListComponents(&bytes.Buffer{}, nil)
+	common.AssertError(t, err)
+	cfg.BackstageTokenSource = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "source-token"})
+	err = SetupCatalogRESTClient(context.Background(), cfg).ListComponents(&bytes.Buffer{}, nil)
+	common.AssertError(t, err)
+	common.AssertEqual(t, []string{"Bearer flag-token", "Bearer source-token"}, authHeaders)
+}
+
+const (
+	pageOne = `{"items":[{"kind":"Component","metadata":{"name":"model-1","tags":["genai","vllm"]}},{"kind":"Component","metadata":{"name":"model-2","tags":["vllm"]}}],"totalItems":3,"pageInfo":{"nextCursor":"page2"}}`
+	pageTwo = `{"items":[{"kind":"Component","metadata":{"name":"model-3","tags":["genai","granite"]}}],"totalItems":3,"pageInfo":{"prevCursor":"page1"}}`
+)
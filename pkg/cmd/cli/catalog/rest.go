@@ -0,0 +1,87 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"golang.org/x/oauth2"
+	"k8s.io/klog/v2"
+)
+
+// CatalogRESTClientWrapper extends the bridge's Backstage REST client with the query capabilities the bridge does
+// not currently provide, like paging through results and pushing filters to the Backstage Catalog.
+type CatalogRESTClientWrapper struct {
+	*backstage.BackstageRESTClientWrapper
+	// tokens provides the bearer token for each request, so short-lived tokens are renewed as needed
+	tokens oauth2.TokenSource
+	// ctx is set on each request so cancelling the command, i.e. with Ctrl-C, aborts requests and retries in flight
+	ctx context.Context
+}
+
+func SetupCatalogRESTClient(ctx context.Context, cfg *config.Config) *CatalogRESTClientWrapper {
+	c := &CatalogRESTClientWrapper{BackstageRESTClientWrapper: backstage.SetupBackstageRESTClient(cfg.Config), tokens: cfg.BackstageTokenSource, ctx: ctx}
+	cfg.Requests.Apply(c.RESTClient)
+	// the bridge only knows about skipping TLS and the CA file of a co-located RHDH instance, so the CA bundles and
+	// client certificates for the CLI, when provided, replace the TLS settings the bridge made
+	if cfg.BackstageTLS.TLSConfig != nil {
+		c.RESTClient.SetTLSClientConfig(cfg.BackstageTLS.TLSConfig)
+	}
+	return c
+}
+
+func (c *CatalogRESTClientWrapper) request() (*resty.Request, error) {
+	token := c.Token
+	if c.tokens != nil {
+		t, err := c.tokens.Token()
+		if err != nil {
+			return nil, util.NewError(util.ExitAuth, err)
+		}
+		token = t.AccessToken
+	}
+	return c.RESTClient.R().SetContext(c.ctx).SetAuthToken(token).SetHeader("Accept", "application/json"), nil
+}
+
+// Context returns the context the client's requests are made with
+func (c *CatalogRESTClientWrapper) Context() context.Context {
+	return c.ctx
+}
+
+// fetch sends a GET for url with the optional query parameters, returning the body of a successful response, or an
+// error with the exit code corresponding to the failure
+func (c *CatalogRESTClientWrapper) fetch(url string, qparams url.Values) ([]byte, error) {
+	req, err := c.request()
+	if err != nil {
+		return nil, err
+	}
+	if qparams != nil {
+		req.SetQueryParamsFromValues(qparams)
+	}
+	resp, err := req.Get(url)
+	if err != nil {
+		return nil, util.NewTransportError(err)
+	}
+	return c.processFetch(resp, url, "get")
+}
+
+func (c *CatalogRESTClientWrapper) processFetch(resp *resty.Response, url, action string) ([]byte, error) {
+	rc := resp.StatusCode()
+	if rc != 200 {
+		return nil, util.NewHTTPError(rc, fmt.Errorf("%s for %s rc %d body %s", action, url, rc, resp.String()))
+	}
+	klog.V(4).Infof("%s for %s returned ok", action, url)
+	return resp.Body(), nil
+}
+
+func (c *CatalogRESTClientWrapper) processUpdate(resp *resty.Response, url, action string) ([]byte, error) {
+	rc := resp.StatusCode()
+	if rc != 200 && rc != 201 && rc != 204 {
+		return nil, util.NewHTTPError(rc, fmt.Errorf("%s for %s rc %d body %s", action, url, rc, resp.String()))
+	}
+	klog.V(4).Infof("%s for %s returned ok", action, url)
+	return resp.Body(), nil
+}
@@ -0,0 +1,104 @@
+package catalog
+
+import (
+	"io"
+	"regexp"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	brdgutil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"k8s.io/klog/v2"
+)
+
+// pulled from makeValidator.ts in the catalog-model package in core backstage
+var entityNameRegexp = regexp.MustCompile("^([a-zA-Z0-9]+[-_.])*[a-zA-Z0-9]+$")
+
+// SystemEntityV1alpha1 and DomainEntityV1alpha1 complete the bridge's backstage entity kinds with the kinds that group
+// Components, Resources, and APIs, see https://backstage.io/docs/features/software-catalog/descriptor-format#kind-system
+type SystemEntityV1alpha1 struct {
+	ApiVersion string                    `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string                    `json:"kind" yaml:"kind"`
+	Metadata   backstage.EntityMeta      `json:"metadata" yaml:"metadata"`
+	Spec       *SystemEntityV1alpha1Spec `json:"spec" yaml:"spec"`
+}
+
+type SystemEntityV1alpha1Spec struct {
+	// Owner is an entity reference to the owner of the system
+	Owner string `json:"owner" yaml:"owner"`
+	// Domain is an entity reference to the domain the system is part of
+	Domain string `json:"domain,omitempty" yaml:"domain,omitempty"`
+}
+
+type DomainEntityV1alpha1 struct {
+	ApiVersion string                    `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string                    `json:"kind" yaml:"kind"`
+	Metadata   backstage.EntityMeta      `json:"metadata" yaml:"metadata"`
+	Spec       *DomainEntityV1alpha1Spec `json:"spec" yaml:"spec"`
+}
+
+type DomainEntityV1alpha1Spec struct {
+	// Owner is an entity reference to the owner of the domain
+	Owner string `json:"owner" yaml:"owner"`
+}
+
+// SystemPopulator is implemented by the populators of entities which are part of a System; PrintComponent,
+// PrintResource, and PrintAPI set the spec.system of the entity from it
+type SystemPopulator interface {
+	GetSystem() string
+}
+
+// ValidateEntityOptions returns a usage error when the System and Domain settings of opts cannot be used together, or
+// in a mode, like watching, where each model is stored on its own, so the entities shared by the models would collide
+func ValidateEntityOptions(opts *config.EntityOptions, separately bool) error {
+	if len(opts.Domain) > 0 && !opts.Systems {
+		return util.NewUsageError("--domain can only be used with --systems")
+	}
+	if len(opts.Domain) > 0 && (len(opts.Domain) > 63 || !entityNameRegexp.MatchString(opts.Domain)) {
+		return util.NewUsageError("%q is not a valid entity name for --domain; use up to 63 letters and digits, separated by '-', '_', or '.'", opts.Domain)
+	}
+	if opts.Systems && separately {
+		return util.NewUsageError("--systems cannot be used with --watch or --poll, which store the entities of each model separately; generate the Systems once without them")
+	}
+	return nil
+}
+
+// PrintSystem prints a System in namespace, owned like the model entities by owner, which is part of domain when it is
+// set
+func PrintSystem(name, namespace, description, owner, domain string, writer io.Writer) error {
+	system := &SystemEntityV1alpha1{
+		ApiVersion: backstage.VERSION,
+		Kind:       "System",
+		Metadata:   backstage.EntityMeta{Name: name, Namespace: namespace, Description: description},
+		Spec:       &SystemEntityV1alpha1Spec{Owner: OwnerRef(owner), Domain: domain},
+	}
+	err := brdgutil.PrintYaml(system, true, writer)
+	if err != nil {
+		klog.Errorf("ERROR: converting system to yaml and printing: %s, %#v", err.Error(), system)
+		return err
+	}
+	return nil
+}
+
+// PrintDomain prints a Domain in namespace, owned like the model entities by owner
+func PrintDomain(name, namespace, owner string, writer io.Writer) error {
+	domain := &DomainEntityV1alpha1{
+		ApiVersion: backstage.VERSION,
+		Kind:       "Domain",
+		Metadata:   backstage.EntityMeta{Name: name, Namespace: namespace, Description: "The AI models of " + name},
+		Spec:       &DomainEntityV1alpha1Spec{Owner: OwnerRef(owner)},
+	}
+	err := brdgutil.PrintYaml(domain, true, writer)
+	if err != nil {
+		klog.Errorf("ERROR: converting domain to yaml and printing: %s, %#v", err.Error(), domain)
+		return err
+	}
+	return nil
+}
+
+func systemOf(pop interface{}) string {
+	if sp, ok := pop.(SystemPopulator); ok {
+		return sp.GetSystem()
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package catalog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+)
+
+// ParseEntityRef splits the short hand entity references Backstage allows, like 'jdoe' or 'group:ml-platform', into
+// their kind, namespace, and name, using defaultKind when no kind is present and the 'default' namespace when no
+// namespace is present.
+func ParseEntityRef(ref, defaultKind string) (kind, namespace, name string) {
+	kind = defaultKind
+	namespace = rest.DEFAULT_NS
+	name = ref
+	if i := strings.Index(name, ":"); i >= 0 {
+		kind = name[:i]
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "/"); i >= 0 {
+		namespace = name[:i]
+		name = name[i+1:]
+	}
+	return kind, namespace, name
+}
+
+// NormalizeEntityRef turns the short hand entity references Backstage allows into the fully qualified, lower case
+// 'kind:namespace/name' form Backstage uses in relations, using defaultKind when no kind is present.
+func NormalizeEntityRef(ref, defaultKind string) string {
+	kind, namespace, name := ParseEntityRef(ref, defaultKind)
+	return strings.ToLower(fmt.Sprintf("%s:%s/%s", kind, namespace, name))
+}
+
+// EntityRef returns the fully qualified, lower case 'kind:namespace/name' reference for entity
+func EntityRef(entity *backstage.Entity) string {
+	namespace := entity.Metadata.Namespace
+	if len(namespace) == 0 {
+		namespace = rest.DEFAULT_NS
+	}
+	return strings.ToLower(fmt.Sprintf("%s:%s/%s", entity.Kind, namespace, entity.Metadata.Name))
+}
+
+func tagsIncluded(args, tags []string) bool {
+	if len(tags) < len(args) {
+		return false
+	}
+	for _, arg := range args {
+		found := false
+		for _, tag := range tags {
+			if arg == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func tagsMatch(args, tags []string) bool {
+	if len(args) != len(tags) {
+		return false
+	}
+	// we don't require exact order with the set of tags specified so we sort copies of the two arrays to facilitate the compare
+	a := append([]string{}, args...)
+	t := append([]string{}, tags...)
+	sort.Strings(a)
+	sort.Strings(t)
+	for i, tag := range t {
+		if a[i] != tag {
+			return false
+		}
+	}
+	return true
+}
+
+// pulled from makeValidator.ts in the catalog-model package in core backstage
+var tagInvalidCharRegexp = regexp.MustCompile("[^a-z0-9:+#]+")
+
+// SanitizeTag lower cases value and replaces each run of the characters Backstage does not allow in a tag with a '-',
+// trimming it to the 63 characters allowed; the result is empty when value has none of the allowed characters
+func SanitizeTag(value string) string {
+	tag := strings.Trim(tagInvalidCharRegexp.ReplaceAllString(strings.ToLower(value), "-"), "-")
+	if len(tag) > 63 {
+		tag = strings.TrimRight(tag[:63], "-")
+	}
+	return tag
+}
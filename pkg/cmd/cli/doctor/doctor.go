@@ -0,0 +1,262 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+const (
+	doctorExamples = `
+# Scan the AI related Components, Resources, and APIs in the Backstage Catalog and report any problems found as JSON
+$ %s doctor catalog
+
+# Exit with a non-zero return code when problems are found, for use in a scheduled hygiene job
+$ %s doctor catalog --fail-on-issues
+`
+
+	ORPHAN_ANNOTATION     = "backstage.io/orphan"
+	MANAGED_BY_ANNOTATION = "backstage.io/managed-by-location"
+
+	CheckOrphan              = "orphan"
+	CheckDanglingReference   = "dangling-reference"
+	CheckUnresolvedOwner     = "unresolved-owner"
+	CheckLocationNotFound    = "location-not-found"
+	CheckLocationUnreachable = "location-unreachable"
+	CheckStatusError         = "status-error"
+)
+
+// Issue is a single problem found with an entity in the Backstage Catalog
+type Issue struct {
+	Entity  string `json:"entity"`
+	Check   string `json:"check"`
+	Target  string `json:"target,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report is the machine-readable result of scanning the Backstage Catalog
+type Report struct {
+	EntitiesScanned int     `json:"entitiesScanned"`
+	IssueCount      int     `json:"issueCount"`
+	Issues          []Issue `json:"issues"`
+}
+
+type doctor struct {
+	client *catalog.CatalogRESTClientWrapper
+	// resolved caches the lookups of referenced entities; a nil entry means the entity does not exist
+	resolved map[string]*backstage.Entity
+	// locations caches the result of checking location targets, keyed by the managed-by-location annotation value
+	locations map[string]*Issue
+}
+
+// CheckCatalog scans the AI related entities in the Backstage Catalog for orphans, dangling references, owners that do
+// not resolve to a User or Group, locations whose target no longer exists, and entities with errors in their status
+func CheckCatalog(c *catalog.CatalogRESTClientWrapper) (*Report, error) {
+	d := &doctor{client: c, resolved: map[string]*backstage.Entity{}, locations: map[string]*Issue{}}
+	entities, err := c.ListAIEntities(nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entities {
+		d.resolved[catalog.EntityRef(&entities[i])] = &entities[i]
+	}
+
+	report := &Report{EntitiesScanned: len(entities), Issues: []Issue{}}
+	for i := range entities {
+		issues, err := d.checkEntity(&entities[i])
+		if err != nil {
+			return nil, err
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		if report.Issues[i].Entity == report.Issues[j].Entity {
+			return report.Issues[i].Check < report.Issues[j].Check
+		}
+		return report.Issues[i].Entity < report.Issues[j].Entity
+	})
+	report.IssueCount = len(report.Issues)
+	return report, nil
+}
+
+func (d *doctor) checkEntity(entity *backstage.Entity) ([]Issue, error) {
+	ref := catalog.EntityRef(entity)
+	issues := []Issue{}
+
+	if strings.EqualFold(entity.Metadata.Annotations[ORPHAN_ANNOTATION], "true") {
+		issues = append(issues, Issue{Entity: ref, Check: CheckOrphan, Message: "the location which defined this entity no longer provides it"})
+	}
+
+	for _, target := range referencedEntities(entity) {
+		e, err := d.resolve(target)
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			issues = append(issues, Issue{Entity: ref, Check: CheckDanglingReference, Target: target, Message: "referenced entity does not exist in the Backstage Catalog"})
+		}
+	}
+
+	if owner, ok := entity.Spec["owner"].(string); ok && len(owner) > 0 {
+		// per the Backstage docs, an owner without a kind is a Group
+		target := catalog.NormalizeEntityRef(owner, "group")
+		e, err := d.resolve(target)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case e == nil:
+			issues = append(issues, Issue{Entity: ref, Check: CheckUnresolvedOwner, Target: target, Message: "owner does not exist in the Backstage Catalog"})
+		case !strings.EqualFold(e.Kind, "user") && !strings.EqualFold(e.Kind, "group"):
+			issues = append(issues, Issue{Entity: ref, Check: CheckUnresolvedOwner, Target: target, Message: fmt.Sprintf("owner is a %s, not a User or Group", e.Kind)})
+		}
+	}
+
+	if location, ok := entity.Metadata.Annotations[MANAGED_BY_ANNOTATION]; ok && len(location) > 0 {
+		if issue := d.checkLocation(location); issue != nil {
+			i := *issue
+			i.Entity = ref
+			issues = append(issues, i)
+		}
+	}
+
+	if entity.Status != nil {
+		for _, item := range entity.Status.Items {
+			if !strings.EqualFold(item.Level, "error") {
+				continue
+			}
+			msg := item.Message
+			if item.Error != nil && len(item.Error.Message) > 0 {
+				msg = fmt.Sprintf("%s: %s", msg, item.Error.Message)
+			}
+			issues = append(issues, Issue{Entity: ref, Check: CheckStatusError, Target: item.Type, Message: msg})
+		}
+	}
+	return issues, nil
+}
+
+// referencedEntities returns the sorted, de-duplicated references from the dependsOn and providesApis spec fields as well
+// as the corresponding relations Backstage computed from them
+func referencedEntities(entity *backstage.Entity) []string {
+	refs := map[string]bool{}
+	for _, field := range []struct {
+		name        string
+		defaultKind string
+	}{{"dependsOn", "component"}, {"providesApis", "api"}} {
+		values, ok := entity.Spec[field.name].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, v := range values {
+			if s, ok := v.(string); ok && len(s) > 0 {
+				refs[catalog.NormalizeEntityRef(s, field.defaultKind)] = true
+			}
+		}
+	}
+	for _, r := range entity.Relations {
+		if r.Type == "dependsOn" || r.Type == "providesApi" {
+			refs[strings.ToLower(r.TargetRef)] = true
+		}
+	}
+	keys := []string{}
+	for k := range refs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (d *doctor) resolve(ref string) (*backstage.Entity, error) {
+	if e, ok := d.resolved[ref]; ok {
+		return e, nil
+	}
+	e, err := d.client.GetEntityByRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	d.resolved[ref] = e
+	return e, nil
+}
+
+// checkLocation fetches the target of a 'type:target' managed-by-location annotation, returning an Issue without the
+// entity set if the target cannot be retrieved
+func (d *doctor) checkLocation(location string) *Issue {
+	if issue, ok := d.locations[location]; ok {
+		return issue
+	}
+	var issue *Issue
+	target := location
+	if i := strings.Index(location, ":"); i >= 0 {
+		target = location[i+1:]
+	}
+	// only URL based locations can be checked from here; 'file' locations are relative to the Backstage server
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		resp, err := d.client.RESTClient.R().SetContext(d.client.Context()).Get(target)
+		switch {
+		case err != nil:
+			issue = &Issue{Check: CheckLocationUnreachable, Target: target, Message: err.Error()}
+		case resp.StatusCode() == http.StatusNotFound:
+			issue = &Issue{Check: CheckLocationNotFound, Target: target, Message: "location target returned 404"}
+		default:
+			klog.V(4).Infof("location %s returned rc %d", target, resp.StatusCode())
+		}
+	}
+	d.locations[location] = issue
+	return issue
+}
+
+// PrintReport writes the report to writer as indented JSON
+func PrintReport(report *Report, writer io.Writer) error {
+	buf, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(buf, '\n'))
+	return err
+}
+
+func NewCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "doctor",
+		Long:    "doctor inspects the AI related content of the Backstage Catalog and reports problems found",
+		Example: strings.ReplaceAll(doctorExamples, "%s", util.ApplicationName),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	failOnIssues := false
+	catalogCmd := &cobra.Command{
+		Use:     "catalog",
+		Long:    "catalog scans the model-server Components, ai-model Resources, and AI related APIs in the Backstage Catalog for orphaned entities, dangling dependsOn and providesApis references, owners which are not a User or Group, locations whose target returns 404, and entities with errors in their status, and prints the results as JSON",
+		Aliases: []string{"c"},
+		Example: strings.ReplaceAll(doctorExamples, "%s", util.ApplicationName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := CheckCatalog(catalog.SetupCatalogRESTClient(cmd.Context(), cfg))
+			if err != nil {
+				return err
+			}
+			err = PrintReport(report, cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+			if failOnIssues && report.IssueCount > 0 {
+				return util.NewValidationError("%d issues found in the Backstage Catalog", report.IssueCount)
+			}
+			return nil
+		},
+	}
+	catalogCmd.Flags().BoolVar(&failOnIssues, "fail-on-issues", failOnIssues,
+		"Return an error when any issues are found")
+	cmd.AddCommand(catalogCmd)
+	return cmd
+}
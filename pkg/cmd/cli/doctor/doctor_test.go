@@ -0,0 +1,141 @@
+package doctor
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	cobra2 "github.com/redhat-ai-dev/model-catalog-bridge/test/cobra"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+)
+
+func TestNewCmd(t *testing.T) {
+	var serverURL string
+	ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, rest.QUERY_URI):
+			filter := r.URL.Query().Get("filter")
+			switch {
+			case strings.HasPrefix(filter, "kind=component"):
+				_, _ = w.Write([]byte(strings.ReplaceAll(components, "SERVER", serverURL)))
+			case strings.HasPrefix(filter, "kind=resource"):
+				_, _ = w.Write([]byte(resources))
+			default:
+				_, _ = w.Write([]byte(`{"items":[],"totalItems":0,"pageInfo":{}}`))
+			}
+		case strings.HasSuffix(r.URL.Path, "/entities/by-name/user/default/jdoe"):
+			_, _ = w.Write([]byte(`{"kind":"User","metadata":{"name":"jdoe","namespace":"default"}}`))
+		case strings.HasSuffix(r.URL.Path, "/entities/by-name/system/default/ml"):
+			_, _ = w.Write([]byte(`{"kind":"System","metadata":{"name":"ml","namespace":"default"}}`))
+		case strings.HasSuffix(r.URL.Path, "/catalog-info.yaml"):
+			_, _ = w.Write([]byte("kind: Component"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	serverURL = ts.URL
+
+	for _, tc := range []struct {
+		name           string
+		args           []string
+		generatesError bool
+		generatesHelp  bool
+		errorStr       string
+		outStr         string
+	}{
+		{
+			name:          "no subcommand",
+			args:          []string{},
+			generatesHelp: true,
+		},
+		{
+			name:   "catalog",
+			args:   []string{"catalog"},
+			outStr: strings.ReplaceAll(report, "SERVER", serverURL),
+		},
+		{
+			name:           "catalog fail on issues",
+			args:           []string{"catalog", "--fail-on-issues"},
+			generatesError: true,
+			errorStr:       "7 issues found in the Backstage Catalog",
+		},
+	} {
+		cfg := config.NewConfig()
+		cfg.BackstageURL = ts.URL
+		cmd := NewCmd(cfg)
+		_, stdout, stderr, err := cobra2.ExecuteCommandC(cmd, tc.args...)
+		switch {
+		case err == nil && tc.generatesError:
+			t.Errorf("error should have been generated for '%s'", tc.name)
+		case err != nil && !tc.generatesError:
+			t.Errorf("error generated unexpectedly for '%s': %s", tc.name, err.Error())
+		case tc.generatesError && !strings.Contains(stderr, tc.errorStr):
+			t.Errorf("unexpected error output for '%s': %s", tc.name, stderr)
+		case tc.generatesHelp && !strings.Contains(stdout, "Usage:"):
+			t.Errorf("help output expected for '%s': %s", tc.name, stdout)
+		case !tc.generatesError && !tc.generatesHelp:
+			common.AssertEqual(t, tc.outStr, stdout)
+		}
+	}
+}
+
+const (
+	healthy    = `{"kind":"Component","metadata":{"name":"healthy","namespace":"default","annotations":{"backstage.io/managed-by-location":"url:SERVER/catalog-info.yaml"}},"spec":{"type":"model-server","owner":"user:jdoe","dependsOn":["resource:model-1"]},"relations":[{"type":"dependsOn","targetRef":"resource:default/model-1"}]}`
+	broken     = `{"kind":"Component","metadata":{"name":"broken","namespace":"default","annotations":{"backstage.io/orphan":"true","backstage.io/managed-by-location":"url:SERVER/gone.yaml"}},"spec":{"type":"model-server","owner":"ml-platform","dependsOn":["resource:default/model-9"],"providesApis":["broken"]}}`
+	badOwner   = `{"kind":"Component","metadata":{"name":"bad-owner","namespace":"default"},"spec":{"type":"model-server","owner":"system:ml"}}`
+	components = `{"items":[` + healthy + `,` + broken + `,` + badOwner + `],"totalItems":3,"pageInfo":{}}`
+	resources  = `{"items":[{"kind":"Resource","metadata":{"name":"model-1","namespace":"default"},"spec":{"type":"ai-model","owner":"user:jdoe"},"status":{"items":[{"type":"backstage.io/catalog-processing","level":"error","message":"processing failed","error":{"name":"InputError","message":"bad spec"}},{"type":"other","level":"warning","message":"ignored"}]}}],"totalItems":1,"pageInfo":{}}`
+
+	report = `{
+    "entitiesScanned": 4,
+    "issueCount": 7,
+    "issues": [
+        {
+            "entity": "component:default/bad-owner",
+            "check": "unresolved-owner",
+            "target": "system:default/ml",
+            "message": "owner is a System, not a User or Group"
+        },
+        {
+            "entity": "component:default/broken",
+            "check": "dangling-reference",
+            "target": "api:default/broken",
+            "message": "referenced entity does not exist in the Backstage Catalog"
+        },
+        {
+            "entity": "component:default/broken",
+            "check": "dangling-reference",
+            "target": "resource:default/model-9",
+            "message": "referenced entity does not exist in the Backstage Catalog"
+        },
+        {
+            "entity": "component:default/broken",
+            "check": "location-not-found",
+            "target": "SERVER/gone.yaml",
+            "message": "location target returned 404"
+        },
+        {
+            "entity": "component:default/broken",
+            "check": "orphan",
+            "message": "the location which defined this entity no longer provides it"
+        },
+        {
+            "entity": "component:default/broken",
+            "check": "unresolved-owner",
+            "target": "group:default/ml-platform",
+            "message": "owner does not exist in the Backstage Catalog"
+        },
+        {
+            "entity": "resource:default/model-1",
+            "check": "status-error",
+            "target": "backstage.io/catalog-processing",
+            "message": "processing failed: bad spec"
+        }
+    ]
+}
+`
+)
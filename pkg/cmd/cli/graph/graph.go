@@ -0,0 +1,417 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	graphExamples = `
+# Render the relationships between all the AI related Components, Resources, and APIs in the Backstage Catalog as a tree.
+# Resources and APIs no Component points to, and Components which do not provide an API, are listed after the tree.
+$ %s get graph
+
+# Start from a single entity; the kind defaults to 'component' and the namespace to 'default'
+$ %s get graph component:default/granite-8b-instruct
+
+# Render the graph in Graphviz DOT format, or as a Mermaid flowchart, for use in docs or a Backstage TechDoc
+$ %s get graph -o dot | dot -Tsvg > catalog.svg
+$ %s get graph -o mermaid
+`
+
+	OutputTree    = "tree"
+	OutputDot     = "dot"
+	OutputMermaid = "mermaid"
+)
+
+// forwardRelations are the relation types we follow when walking the graph; Backstage also records the inverse of each
+// of these (dependencyOf, apiProvidedBy, apiConsumedBy, partOf) on the target entity, which we skip so that each edge
+// is only rendered once
+var forwardRelations = map[string]bool{
+	"dependsOn":   true,
+	"providesApi": true,
+	"consumesApi": true,
+	"hasPart":     true,
+}
+
+// Edge is a relation from one entity to the entity referenced by Target
+type Edge struct {
+	Type   string
+	Target string
+}
+
+// Graph is the set of entities and the forward relations between them, keyed by their 'kind:namespace/name' reference
+type Graph struct {
+	Nodes map[string]*backstage.Entity
+	Edges map[string][]Edge
+}
+
+// NewGraph builds a Graph from the relations of the provided entities
+func NewGraph(entities []backstage.Entity) *Graph {
+	g := &Graph{Nodes: map[string]*backstage.Entity{}, Edges: map[string][]Edge{}}
+	for i := range entities {
+		g.Add(&entities[i])
+	}
+	return g
+}
+
+// Add includes entity and its forward relations in the graph
+func (g *Graph) Add(entity *backstage.Entity) {
+	ref := catalog.EntityRef(entity)
+	g.Nodes[ref] = entity
+	edges := []Edge{}
+	for _, r := range entity.Relations {
+		if !forwardRelations[r.Type] {
+			continue
+		}
+		edges = append(edges, Edge{Type: r.Type, Target: strings.ToLower(r.TargetRef)})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Target == edges[j].Target {
+			return edges[i].Type < edges[j].Type
+		}
+		return edges[i].Target < edges[j].Target
+	})
+	g.Edges[ref] = edges
+}
+
+// Missing returns the sorted references which are the target of a relation but not part of the graph
+func (g *Graph) Missing() []string {
+	missing := map[string]bool{}
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			if _, ok := g.Nodes[e.Target]; !ok {
+				missing[e.Target] = true
+			}
+		}
+	}
+	return sortedKeys(missing)
+}
+
+// Orphans returns the sorted references of the Resources and APIs that no other entity in the graph relates to
+func (g *Graph) Orphans() []string {
+	targeted := map[string]bool{}
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			targeted[e.Target] = true
+		}
+	}
+	orphans := map[string]bool{}
+	for ref, entity := range g.Nodes {
+		if entity == nil || targeted[ref] {
+			continue
+		}
+		if strings.EqualFold(entity.Kind, "resource") || strings.EqualFold(entity.Kind, "api") {
+			orphans[ref] = true
+		}
+	}
+	return sortedKeys(orphans)
+}
+
+// NoAPI returns the sorted references of the Components in the graph which do not provide an API
+func (g *Graph) NoAPI() []string {
+	noAPI := map[string]bool{}
+	for ref, entity := range g.Nodes {
+		if entity == nil || !strings.EqualFold(entity.Kind, "component") {
+			continue
+		}
+		found := false
+		for _, e := range g.Edges[ref] {
+			if e.Type == "providesApi" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			noAPI[ref] = true
+		}
+	}
+	return sortedKeys(noAPI)
+}
+
+// Roots returns the starting points for rendering the graph: the sorted references of the Components, followed by any
+// other entity no other entity relates to
+func (g *Graph) Roots() []string {
+	targeted := map[string]bool{}
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			targeted[e.Target] = true
+		}
+	}
+	components := map[string]bool{}
+	others := map[string]bool{}
+	for ref, entity := range g.Nodes {
+		switch {
+		case entity != nil && strings.EqualFold(entity.Kind, "component"):
+			components[ref] = true
+		case !targeted[ref]:
+			others[ref] = true
+		}
+	}
+	return append(sortedKeys(components), sortedKeys(others)...)
+}
+
+// Print renders the graph starting from roots to writer in the tree, dot, or mermaid format
+func (g *Graph) Print(writer io.Writer, format string, roots []string) error {
+	switch format {
+	case OutputTree, "":
+		return g.printTree(writer, roots)
+	case OutputDot:
+		return g.printDot(writer, roots)
+	case OutputMermaid:
+		return g.printMermaid(writer, roots)
+	default:
+		return util.NewUsageError("unsupported output format %q; supported formats are %s, %s, and %s", format, OutputTree, OutputDot, OutputMermaid)
+	}
+}
+
+func (g *Graph) printTree(writer io.Writer, roots []string) error {
+	noAPI := g.NoAPI()
+	noAPISet := setOf(noAPI)
+	sb := &strings.Builder{}
+	for _, root := range roots {
+		sb.WriteString(root + g.marker(root, noAPISet) + "\n")
+		g.treeChildren(sb, root, "", map[string]bool{root: true}, noAPISet)
+	}
+	if orphans := g.Orphans(); len(orphans) > 0 {
+		sb.WriteString("\nOrphaned Resources and APIs:\n")
+		for _, ref := range orphans {
+			sb.WriteString("  " + ref + "\n")
+		}
+	}
+	if len(noAPI) > 0 {
+		sb.WriteString("\nComponents without an API:\n")
+		for _, ref := range noAPI {
+			sb.WriteString("  " + ref + "\n")
+		}
+	}
+	if missing := g.Missing(); len(missing) > 0 {
+		sb.WriteString("\nReferenced entities not found:\n")
+		for _, ref := range missing {
+			sb.WriteString("  " + ref + "\n")
+		}
+	}
+	_, err := io.WriteString(writer, sb.String())
+	return err
+}
+
+func (g *Graph) treeChildren(sb *strings.Builder, ref, prefix string, path, noAPI map[string]bool) {
+	edges := g.Edges[ref]
+	for i, e := range edges {
+		branch, indent := "├── ", "│   "
+		if i == len(edges)-1 {
+			branch, indent = "└── ", "    "
+		}
+		if path[e.Target] {
+			sb.WriteString(fmt.Sprintf("%s%s%s: %s (cycle)\n", prefix, branch, e.Type, e.Target))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s%s%s: %s%s\n", prefix, branch, e.Type, e.Target, g.marker(e.Target, noAPI)))
+		path[e.Target] = true
+		g.treeChildren(sb, e.Target, prefix+indent, path, noAPI)
+		delete(path, e.Target)
+	}
+}
+
+// marker flags ref when it is not in the catalog, or is in noAPI, the set of Components without an API, which the
+// caller computes once rather than for each entity rendered
+func (g *Graph) marker(ref string, noAPI map[string]bool) string {
+	entity, ok := g.Nodes[ref]
+	switch {
+	case !ok || entity == nil:
+		return " [not found]"
+	case strings.EqualFold(entity.Kind, "component") && noAPI[ref]:
+		return " [no api]"
+	}
+	return ""
+}
+
+// reachable returns the sorted references of the entities reachable from roots, including the roots
+func (g *Graph) reachable(roots []string) []string {
+	seen := map[string]bool{}
+	var walk func(ref string)
+	walk = func(ref string) {
+		if seen[ref] {
+			return
+		}
+		seen[ref] = true
+		for _, e := range g.Edges[ref] {
+			walk(e.Target)
+		}
+	}
+	for _, root := range roots {
+		walk(root)
+	}
+	return sortedKeys(seen)
+}
+
+func (g *Graph) printDot(writer io.Writer, roots []string) error {
+	refs := g.reachable(roots)
+	orphans := setOf(g.Orphans())
+	noAPI := setOf(g.NoAPI())
+	sb := &strings.Builder{}
+	sb.WriteString("digraph catalog {\n    rankdir=LR;\n    node [shape=box];\n")
+	for _, ref := range refs {
+		attrs := []string{}
+		entity := g.Nodes[ref]
+		switch {
+		case entity == nil:
+			attrs = append(attrs, "style=dashed", "color=gray")
+		case orphans[ref]:
+			attrs = append(attrs, "style=dashed", "color=red")
+		case noAPI[ref]:
+			attrs = append(attrs, "color=orange")
+		}
+		if len(attrs) > 0 {
+			sb.WriteString(fmt.Sprintf("    %q [%s];\n", ref, strings.Join(attrs, ", ")))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("    %q;\n", ref))
+	}
+	for _, ref := range refs {
+		for _, e := range g.Edges[ref] {
+			sb.WriteString(fmt.Sprintf("    %q -> %q [label=%q];\n", ref, e.Target, e.Type))
+		}
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(writer, sb.String())
+	return err
+}
+
+func (g *Graph) printMermaid(writer io.Writer, roots []string) error {
+	refs := g.reachable(roots)
+	ids := map[string]string{}
+	for i, ref := range refs {
+		ids[ref] = fmt.Sprintf("n%d", i)
+	}
+	classes := map[string][]string{}
+	orphans := setOf(g.Orphans())
+	noAPI := setOf(g.NoAPI())
+	sb := &strings.Builder{}
+	sb.WriteString("graph LR\n")
+	for _, ref := range refs {
+		sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", ids[ref], ref))
+		switch {
+		case g.Nodes[ref] == nil:
+			classes["missing"] = append(classes["missing"], ids[ref])
+		case orphans[ref]:
+			classes["orphan"] = append(classes["orphan"], ids[ref])
+		case noAPI[ref]:
+			classes["noapi"] = append(classes["noapi"], ids[ref])
+		}
+	}
+	for _, ref := range refs {
+		for _, e := range g.Edges[ref] {
+			sb.WriteString(fmt.Sprintf("    %s -->|%s| %s\n", ids[ref], e.Type, ids[e.Target]))
+		}
+	}
+	styles := map[string]string{
+		"missing": "stroke:gray,stroke-dasharray: 5 5",
+		"orphan":  "stroke:red,stroke-dasharray: 5 5",
+		"noapi":   "stroke:orange",
+	}
+	for _, class := range []string{"missing", "orphan", "noapi"} {
+		if len(classes[class]) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("    classDef %s %s\n", class, styles[class]))
+		sb.WriteString(fmt.Sprintf("    class %s %s\n", strings.Join(classes[class], ","), class))
+	}
+	_, err := io.WriteString(writer, sb.String())
+	return err
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func setOf(list []string) map[string]bool {
+	set := map[string]bool{}
+	for _, l := range list {
+		set[l] = true
+	}
+	return set
+}
+
+// fetchMissing retrieves the entities referenced by the graph which were not part of the initial query, like the
+// entities reachable from a single entity, or entities of kinds outside of the AI related query
+func fetchMissing(c *catalog.CatalogRESTClientWrapper, g *Graph) error {
+	fetched := map[string]bool{}
+	for {
+		missing := []string{}
+		for _, ref := range g.Missing() {
+			if !fetched[ref] {
+				missing = append(missing, ref)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		for _, ref := range missing {
+			fetched[ref] = true
+			entity, err := c.GetEntityByRef(ref)
+			if err != nil {
+				return err
+			}
+			if entity != nil {
+				g.Add(entity)
+			}
+		}
+	}
+}
+
+func NewCmd(cfg *config.Config, opts *catalog.QueryOptions) *cobra.Command {
+	output := OutputTree
+	cmd := &cobra.Command{
+		Use:     "graph [entity-ref]",
+		Long:    "graph renders the relationships between the AI related Backstage Catalog Components, Resources, and APIs, highlighting orphaned Resources and APIs and Components without an API",
+		Aliases: []string{"gr"},
+		Example: strings.ReplaceAll(graphExamples, "%s", util.ApplicationName),
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := catalog.SetupCatalogRESTClient(cmd.Context(), cfg)
+			g := NewGraph(nil)
+			var roots []string
+			if len(args) > 0 {
+				root := catalog.NormalizeEntityRef(args[0], "component")
+				entity, err := c.GetEntityByRef(root)
+				if err != nil {
+					return err
+				}
+				if entity == nil {
+					return util.NewNotFoundError("entity %s not found in the Backstage Catalog", root)
+				}
+				g.Add(entity)
+				roots = []string{root}
+			} else {
+				entities, err := c.ListAIEntities(opts)
+				if err != nil {
+					return err
+				}
+				g = NewGraph(entities)
+				roots = g.Roots()
+			}
+			err := fetchMissing(c, g)
+			if err != nil {
+				return err
+			}
+			return g.Print(cmd.OutOrStdout(), output, roots)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", output,
+		fmt.Sprintf("The output format, one of %s, %s, or %s", OutputTree, OutputDot, OutputMermaid))
+	return cmd
+}
@@ -0,0 +1,168 @@
+package graph
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	cobra2 "github.com/redhat-ai-dev/model-catalog-bridge/test/cobra"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+)
+
+func TestNewCmd(t *testing.T) {
+	ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, rest.QUERY_URI):
+			filter := r.URL.Query().Get("filter")
+			switch {
+			case strings.HasPrefix(filter, "kind=component"):
+				_, _ = w.Write([]byte(components))
+			case strings.HasPrefix(filter, "kind=resource"):
+				_, _ = w.Write([]byte(resources))
+			default:
+				_, _ = w.Write([]byte(apis))
+			}
+		case strings.HasSuffix(r.URL.Path, "/entities/by-name/component/default/model-1"):
+			_, _ = w.Write([]byte(model1))
+		case strings.HasSuffix(r.URL.Path, "/entities/by-name/resource/default/model-1"):
+			_, _ = w.Write([]byte(resource1))
+		case strings.HasSuffix(r.URL.Path, "/entities/by-name/api/default/model-1"):
+			_, _ = w.Write([]byte(api1))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		name           string
+		args           []string
+		generatesError bool
+		errorStr       string
+		outStr         string
+	}{
+		{
+			name:   "tree",
+			args:   []string{},
+			outStr: tree,
+		},
+		{
+			name:   "single entity",
+			args:   []string{"model-1"},
+			outStr: singleTree,
+		},
+		{
+			name:   "dot",
+			args:   []string{"-o", "dot"},
+			outStr: dot,
+		},
+		{
+			name:   "mermaid",
+			args:   []string{"-o", "mermaid"},
+			outStr: mermaid,
+		},
+		{
+			name:           "entity not found",
+			args:           []string{"component:default/model-9"},
+			generatesError: true,
+			errorStr:       "entity component:default/model-9 not found in the Backstage Catalog",
+		},
+		{
+			name:           "bad output",
+			args:           []string{"-o", "json"},
+			generatesError: true,
+			errorStr:       "unsupported output format",
+		},
+	} {
+		cfg := config.NewConfig()
+		cfg.BackstageURL = ts.URL
+		cmd := NewCmd(cfg, &catalog.QueryOptions{})
+		_, stdout, stderr, err := cobra2.ExecuteCommandC(cmd, tc.args...)
+		switch {
+		case err == nil && tc.generatesError:
+			t.Errorf("error should have been generated for '%s'", tc.name)
+		case err != nil && !tc.generatesError:
+			t.Errorf("error generated unexpectedly for '%s': %s", tc.name, err.Error())
+		case tc.generatesError && !strings.Contains(stderr, tc.errorStr):
+			t.Errorf("unexpected error output for '%s': %s", tc.name, stderr)
+		case !tc.generatesError:
+			common.AssertEqual(t, tc.outStr, stdout)
+		}
+	}
+}
+
+const (
+	model1     = `{"apiVersion":"backstage.io/v1alpha1","kind":"Component","metadata":{"name":"model-1","namespace":"default"},"spec":{"type":"model-server"},"relations":[{"type":"providesApi","targetRef":"api:default/model-1"},{"type":"dependsOn","targetRef":"resource:default/model-1"},{"type":"ownedBy","targetRef":"user:default/jdoe"}]}`
+	model2     = `{"apiVersion":"backstage.io/v1alpha1","kind":"Component","metadata":{"name":"model-2"},"spec":{"type":"model-server"},"relations":[{"type":"dependsOn","targetRef":"resource:default/model-2"},{"type":"dependsOn","targetRef":"resource:default/gone"}]}`
+	resource1  = `{"apiVersion":"backstage.io/v1alpha1","kind":"Resource","metadata":{"name":"model-1","namespace":"default"},"spec":{"type":"ai-model"},"relations":[{"type":"dependencyOf","targetRef":"component:default/model-1"}]}`
+	resource2  = `{"apiVersion":"backstage.io/v1alpha1","kind":"Resource","metadata":{"name":"model-2","namespace":"default"},"spec":{"type":"ai-model"}}`
+	resource3  = `{"apiVersion":"backstage.io/v1alpha1","kind":"Resource","metadata":{"name":"model-3","namespace":"default"},"spec":{"type":"ai-model"}}`
+	api1       = `{"apiVersion":"backstage.io/v1alpha1","kind":"API","metadata":{"name":"model-1","namespace":"default"},"spec":{"type":"openapi"},"relations":[{"type":"apiProvidedBy","targetRef":"component:default/model-1"}]}`
+	components = `{"items":[` + model2 + `,` + model1 + `],"totalItems":2,"pageInfo":{}}`
+	resources  = `{"items":[` + resource1 + `,` + resource2 + `,` + resource3 + `],"totalItems":3,"pageInfo":{}}`
+	apis       = `{"items":[` + api1 + `],"totalItems":1,"pageInfo":{}}`
+
+	tree = `component:default/model-1
+├── providesApi: api:default/model-1
+└── dependsOn: resource:default/model-1
+component:default/model-2 [no api]
+├── dependsOn: resource:default/gone [not found]
+└── dependsOn: resource:default/model-2
+resource:default/model-3
+
+Orphaned Resources and APIs:
+  resource:default/model-3
+
+Components without an API:
+  component:default/model-2
+
+Referenced entities not found:
+  resource:default/gone
+`
+
+	singleTree = `component:default/model-1
+├── providesApi: api:default/model-1
+└── dependsOn: resource:default/model-1
+`
+
+	dot = `digraph catalog {
+    rankdir=LR;
+    node [shape=box];
+    "api:default/model-1";
+    "component:default/model-1";
+    "component:default/model-2" [color=orange];
+    "resource:default/gone" [style=dashed, color=gray];
+    "resource:default/model-1";
+    "resource:default/model-2";
+    "resource:default/model-3" [style=dashed, color=red];
+    "component:default/model-1" -> "api:default/model-1" [label="providesApi"];
+    "component:default/model-1" -> "resource:default/model-1" [label="dependsOn"];
+    "component:default/model-2" -> "resource:default/gone" [label="dependsOn"];
+    "component:default/model-2" -> "resource:default/model-2" [label="dependsOn"];
+}
+`
+
+	mermaid = `graph LR
+    n0["api:default/model-1"]
+    n1["component:default/model-1"]
+    n2["component:default/model-2"]
+    n3["resource:default/gone"]
+    n4["resource:default/model-1"]
+    n5["resource:default/model-2"]
+    n6["resource:default/model-3"]
+    n1 -->|providesApi| n0
+    n1 -->|dependsOn| n4
+    n2 -->|dependsOn| n3
+    n2 -->|dependsOn| n5
+    classDef missing stroke:gray,stroke-dasharray: 5 5
+    class n3 missing
+    classDef orphan stroke:red,stroke-dasharray: 5 5
+    class n6 orphan
+    classDef noapi stroke:orange
+    class n2 noapi
+`
+)
@@ -0,0 +1,222 @@
+package kserve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	servingv1alpha1 "github.com/kserve/kserve/pkg/apis/serving/v1alpha1"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	brdgutil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// GRAPH_TYPE is the spec.type of the Components of the InferenceGraphs, which chain InferenceServices into
+	// pipelines, like the retrieval and generation steps of RAG
+	GRAPH_TYPE = "model-pipeline"
+
+	// GRAPH_ROUTER_TYPE_ANNOTATION is the router type of the root node of the graph, and GRAPH_NODES_ANNOTATION the
+	// JSON of every node, with the names, targets, and traffic weights of its steps
+	GRAPH_ROUTER_TYPE_ANNOTATION = "rhdh.modelcatalog.io/router-type"
+	GRAPH_NODES_ANNOTATION       = "rhdh.modelcatalog.io/graph-nodes"
+)
+
+// ListGraphs returns the InferenceGraphs in namespace in name order
+func ListGraphs(ctx context.Context, cfg *config.Config, namespace string) ([]servingv1alpha1.InferenceGraph, error) {
+	client, err := cfg.GetServingAlphaClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	list, err := client.InferenceGraphs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, util.NewKubeError(fmt.Errorf("inference graph retrieval error for %s: %w", namespace, err))
+	}
+	igl := list.Items
+	slices.SortFunc(igl, func(a, b servingv1alpha1.InferenceGraph) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return igl, nil
+}
+
+// PrintGraph prints the Component and API of ig, named by namer like the entities of an InferenceService, in the
+// Backstage namespace of namer, which are part of system when it is set.  The Component depends on the Component of
+// each InferenceService a step of the graph targets, looked up from namer, which named it in CallBackstagePrinters,
+// without claiming the names again.
+func PrintGraph(owner, lifecycle, system string, namer *catalog.Namer, ig *servingv1alpha1.InferenceGraph, writer io.Writer) error {
+	pop := &GraphPopulator{Owner: owner, Lifecycle: lifecycle, Namespace: namer.Namespace(), System: system, Graph: ig}
+	// the key of a graph is apart from the keys of the InferenceServices, which can have the same namespace and name
+	me, err := namer.Name("inferencegraph:"+ig.Namespace+"/"+ig.Name, catalog.EntityNameData{Namespace: ig.Namespace, Name: ig.Name},
+		catalog.ModelEntities{Component: pop.GetName(), API: pop.GetName()})
+	if err != nil {
+		return err
+	}
+	pop.Component, pop.API = me.Component, me.API
+	for _, node := range ig.Spec.Nodes {
+		for _, step := range node.Steps {
+			if len(step.ServiceName) == 0 {
+				continue
+			}
+			name := fmt.Sprintf("%s_%s", ig.Namespace, step.ServiceName)
+			me, err := namer.Lookup(ig.Namespace+"/"+step.ServiceName, catalog.EntityNameData{Namespace: ig.Namespace, Name: step.ServiceName},
+				catalog.ModelEntities{Component: name, Resource: name, API: name})
+			if err != nil {
+				return err
+			}
+			pop.DependsOn = append(pop.DependsOn, "component:"+me.Component)
+		}
+	}
+	err = catalog.PrintComponent(&GraphComponentPopulator{GraphPopulator: pop}, writer)
+	if err != nil {
+		return err
+	}
+	return catalog.PrintAPI(&GraphAPIPopulator{GraphPopulator: pop}, writer)
+}
+
+// GraphPopulator has what the Component and API of an InferenceGraph share
+type GraphPopulator struct {
+	Owner     string
+	Lifecycle string
+	// Namespace is the Backstage namespace of the entities
+	Namespace string
+	System    string
+	Graph     *servingv1alpha1.InferenceGraph
+	// DependsOn are the references of the Components of the InferenceServices the steps of the graph target
+	DependsOn []string
+	// Component and API are the names the Namer gave the entities
+	Component string
+	API       string
+}
+
+func (pop *GraphPopulator) GetOwner() string {
+	return pop.Owner
+}
+
+func (pop *GraphPopulator) GetLifecycle() string {
+	return pop.Lifecycle
+}
+
+// GetName is the name of the entities unless the Namer renames them; it ends with '_graph', so an InferenceGraph and an
+// InferenceService of the same name do not collide
+func (pop *GraphPopulator) GetName() string {
+	return brdgutil.SanitizeName(fmt.Sprintf("%s_%s_graph", pop.Graph.Namespace, pop.Graph.Name))
+}
+
+func (pop *GraphPopulator) GetNamespace() string {
+	return pop.Namespace
+}
+
+func (pop *GraphPopulator) GetSystem() string {
+	return pop.System
+}
+
+func (pop *GraphPopulator) GetDescription() string {
+	return fmt.Sprintf("KServe InferenceGraph %s:%s", pop.Graph.Namespace, pop.Graph.Name)
+}
+
+func (pop *GraphPopulator) GetLinks() []backstage.EntityLink {
+	links := []backstage.EntityLink{}
+	if pop.Graph.Status.URL != nil {
+		links = append(links, backstage.EntityLink{
+			URL:   pop.Graph.Status.URL.String(),
+			Title: backstage.LINK_API_URL,
+			Icon:  backstage.LINK_ICON_WEBASSET,
+			Type:  backstage.LINK_TYPE_WEBSITE,
+		})
+	}
+	return links
+}
+
+// GetTags returns the router types of the nodes of the graph, like sequence or splitter
+func (pop *GraphPopulator) GetTags() []string {
+	tags := []string{}
+	for _, node := range pop.Graph.Spec.Nodes {
+		if tag := catalog.SanitizeTag(string(node.RouterType)); len(tag) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (pop *GraphPopulator) GetProvidedAPIs() []string {
+	return []string{pop.API}
+}
+
+// GetDisplayName is the name of the entities even when the Namer renames them, like the display names of the entities
+// of an InferenceService
+func (pop *GraphPopulator) GetDisplayName() string {
+	return pop.GetName()
+}
+
+// GraphComponentPopulator populates the model-pipeline Component of an InferenceGraph
+type GraphComponentPopulator struct {
+	*GraphPopulator
+}
+
+func (pop *GraphComponentPopulator) GetName() string {
+	return pop.Component
+}
+
+func (pop *GraphComponentPopulator) GetType() string {
+	return GRAPH_TYPE
+}
+
+func (pop *GraphComponentPopulator) GetDependsOn() []string {
+	return append([]string{"api:" + pop.API}, pop.DependsOn...)
+}
+
+// GetAnnotations returns the router type of the root node, and the nodes of the graph as JSON; the map of nodes is
+// marshaled with sorted keys, so the annotation is the same from one run to the next
+func (pop *GraphComponentPopulator) GetAnnotations() map[string]string {
+	annotations := map[string]string{}
+	if root, ok := pop.Graph.Spec.Nodes[servingv1alpha1.GraphRootNodeName]; ok {
+		annotations[GRAPH_ROUTER_TYPE_ANNOTATION] = string(root.RouterType)
+	}
+	nodes, err := json.Marshal(pop.Graph.Spec.Nodes)
+	if err == nil && len(pop.Graph.Spec.Nodes) > 0 {
+		annotations[GRAPH_NODES_ANNOTATION] = string(nodes)
+	}
+	return annotations
+}
+
+func (pop *GraphComponentPopulator) GetTechdocRef() string {
+	return "./"
+}
+
+// GraphAPIPopulator populates the API of the endpoint of an InferenceGraph
+type GraphAPIPopulator struct {
+	*GraphPopulator
+}
+
+// GetDefinition is the placeholder the bridge uses, as the router of an InferenceGraph does not serve an OpenAPI
+// document like the model servers
+func (pop *GraphAPIPopulator) GetDefinition() string {
+	return "no-definition-yet"
+}
+
+// GetType is openapi, as the router of an InferenceGraph takes the same REST requests as the model servers it routes to
+func (pop *GraphAPIPopulator) GetType() string {
+	return backstage.OPENAPI_API_TYPE
+}
+
+func (pop *GraphAPIPopulator) GetName() string {
+	return pop.API
+}
+
+func (pop *GraphAPIPopulator) GetDependencyOf() []string {
+	return []string{"component:" + pop.Component}
+}
+
+func (pop *GraphAPIPopulator) GetProvidedAPIs() []string {
+	return []string{}
+}
+
+func (pop *GraphAPIPopulator) GetTechdocRef() string {
+	return "api/"
+}
@@ -4,14 +4,15 @@ import (
 	"context"
 	"fmt"
 	serverapiv1beta1 "github.com/kserve/kserve/pkg/apis/serving/v1beta1"
-	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
 	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/kserve"
-	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
 	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
 	"github.com/spf13/cobra"
 	"io"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/klog/v2"
+	"os"
+	"slices"
 	"strings"
 )
 
@@ -32,6 +33,24 @@ $ %s new-model kserve <Owner> <Lifecycle> --kubeconfig=/home/myid/my-kube.json
 # This form will pull in only the InferenceService instances with the names 'inferenceservice1' and 'inferenceservice2'
 # in the 'my-datascience-project'namespace in order to build Catalog Component, Resource, and API Entities.
 $ %s new-model kserve Owner Lifecycle inferenceservice1 inferenceservice2 --namespace my-datascience-project
+
+# This form keeps running, and as InferenceService instances are created, updated, or deleted, re-writes or removes the
+# '<namespace>_<name>.yaml' file for each in the 'catalog' directory, or the '<namespace>_<name>' key for each in the
+# ConfigMap the bridge serves its catalog-info.yaml files from.  Use Ctrl-C to stop it.
+$ %s new-model kserve <Owner> <Lifecycle> --watch --output-dir=catalog
+$ %s new-model kserve <Owner> <Lifecycle> --watch --to-bridge
+
+# This form also generates a Resource of type 'model-runtime' for each ServingRuntime in the namespace, and each
+# ClusterServingRuntime the InferenceServices use, and makes the Component of each InferenceService depend on its runtime.
+$ %s new-model kserve <Owner> <Lifecycle> --include-runtimes
+
+# This form also generates a Component of type 'model-pipeline', and an API for its endpoint, for each InferenceGraph in
+# the namespace, which depends on the Components of the InferenceServices its steps call.
+$ %s new-model kserve <Owner> <Lifecycle> --include-graphs
+
+# This form also generates a Resource of type 'ai-model' for each TrainedModel in the namespace, each of the models a
+# multi-model InferenceService serves, which is a dependency of the Component of that InferenceService.
+$ %s new-model kserve <Owner> <Lifecycle> --include-trained-models
 `
 )
 
@@ -42,6 +61,12 @@ type CommonPopulator struct {
 }
 
 func NewCmd(cfg *config.Config) *cobra.Command {
+	watchMode := false
+	outputDir := ""
+	toBridge := false
+	includeRuntimes := false
+	includeGraphs := false
+	includeTrainedModels := false
 	cmd := &cobra.Command{
 		Use:     "kserve",
 		Short:   "KServe related API",
@@ -51,10 +76,7 @@ func NewCmd(cfg *config.Config) *cobra.Command {
 			ids := []string{}
 
 			if len(args) < 2 {
-				err := fmt.Errorf("need to specify an Owner and Lifecycle setting")
-				klog.Errorf("%s", err.Error())
-				klog.Flush()
-				return err
+				return util.NewUsageError("need to specify an Owner and Lifecycle setting")
 			}
 			owner := args[0]
 			lifecycle := args[1]
@@ -63,70 +85,297 @@ func NewCmd(cfg *config.Config) *cobra.Command {
 				ids = args[2:]
 			}
 
-			kserve.SetupKServeClient(cfg)
+			if !watchMode && (len(outputDir) > 0 || toBridge) {
+				return util.NewUsageError("--output-dir and --to-bridge can only be used with --watch")
+			}
+			if watchMode && (len(outputDir) > 0) == toBridge {
+				return util.NewUsageError("--watch needs exactly one of --output-dir or --to-bridge")
+			}
+			if watchMode && includeRuntimes {
+				return util.NewUsageError("--include-runtimes cannot be used with --watch, which stores the entities of each InferenceService separately; generate the runtimes once without it")
+			}
+			if watchMode && includeGraphs {
+				return util.NewUsageError("--include-graphs cannot be used with --watch, which only watches InferenceServices; generate the InferenceGraphs once without it")
+			}
+			if watchMode && includeTrainedModels {
+				return util.NewUsageError("--include-trained-models cannot be used with --watch, which only watches InferenceServices; generate the TrainedModels once without it")
+			}
+			if err := catalog.ValidateEntityOptions(&cfg.Entities, watchMode); err != nil {
+				return err
+			}
+			owners, err := catalog.NewOwners(cmd.Context(), cfg, owner)
+			if err != nil {
+				return err
+			}
+			nsOwners := &NamespaceOwners{cfg: cfg, owners: owners}
+			namer, err := catalog.NewNamer(&cfg.Entities)
+			if err != nil {
+				return err
+			}
+			runtimes := &Runtimes{cfg: cfg}
+
+			kserve.SetupKServeClient(cfg.Config)
+			if watchMode {
+				w := &watcher{
+					ctx:       cmd.Context(),
+					cfg:       cfg,
+					owners:    nsOwners,
+					namer:     namer,
+					runtimes:  runtimes,
+					lifecycle: lifecycle,
+					names:     ids,
+					sink:      &bridgeSink{cfg: cfg},
+					errOut:    cmd.ErrOrStderr(),
+					written:   map[string][]byte{},
+				}
+				if len(outputDir) > 0 {
+					if err := os.MkdirAll(outputDir, 0755); err != nil {
+						return util.NewError(util.ExitError, fmt.Errorf("creating %s: %w", outputDir, err))
+					}
+					w.sink = &dirSink{dir: outputDir}
+				}
+				return w.Run(DEFAULT_WATCH_RESYNC)
+			}
+			nsOwners.values = map[string]string{}
+			runtimes.values = map[string]*Runtime{}
 			namespace := cfg.Namespace
 			servingClient := cfg.ServingClient
 
+			var isl []serverapiv1beta1.InferenceService
 			if len(ids) != 0 {
 				for _, id := range ids {
-					is, err := servingClient.InferenceServices(namespace).Get(context.Background(), id, metav1.GetOptions{})
+					is, err := servingClient.InferenceServices(namespace).Get(cmd.Context(), id, metav1.GetOptions{})
+					if err != nil {
+						return util.NewKubeError(fmt.Errorf("inference service retrieval error for %s:%s: %w", namespace, id, err))
+					}
+					isl = append(isl, *is)
+				}
+			} else {
+				list, err := servingClient.InferenceServices(namespace).List(cmd.Context(), metav1.ListOptions{})
+				if err != nil {
+					return util.NewKubeError(fmt.Errorf("inference service retrieval error for %s: %w", namespace, err))
+				}
+				isl = list.Items
+				// print in namespace and name order, rather than whatever order the API server returns, so the output
+				// is the same from one run to the next
+				slices.SortFunc(isl, func(a, b serverapiv1beta1.InferenceService) int {
+					if c := strings.Compare(a.Namespace, b.Namespace); c != 0 {
+						return c
+					}
+					return strings.Compare(a.Name, b.Name)
+				})
+			}
+			if cfg.Entities.Systems {
+				err := PrintSystems(cmd.Context(), nsOwners, &cfg.Entities, isl, cmd.OutOrStdout())
+				if err != nil {
+					return err
+				}
+			}
+			if includeRuntimes {
+				rl, err := runtimes.List(cmd.Context(), namespace, isl)
+				if err != nil {
+					return err
+				}
+				err = PrintRuntimes(owner, lifecycle, namer, rl, cmd.OutOrStdout())
+				if err != nil {
+					return err
+				}
+			}
+			for i := range isl {
+				if i > 0 {
+					catalog.PrintDocumentSeparator(cmd.OutOrStdout())
+				}
+				system := ""
+				if cfg.Entities.Systems {
+					system = isl[i].Namespace
+				}
+				isOwner, err := nsOwners.Owner(cmd.Context(), isl[i].Namespace)
+				if err != nil {
+					return err
+				}
+				runtime, err := runtimes.Get(cmd.Context(), &isl[i])
+				if err != nil {
+					return err
+				}
+				err = CallBackstagePrinters(isOwner, lifecycle, system, namer, runtime, includeRuntimes, &isl[i], cmd.OutOrStdout())
+				if err != nil {
+					return err
+				}
+			}
+			// the APIs printed last do not end with a document separator, unlike the Resources of the TrainedModels
+			separate := len(isl) > 0
+			// the TrainedModels and graphs are printed after the InferenceServices, so they refer to the names the
+			// InferenceServices were given
+			if includeTrainedModels {
+				tml, err := ListTrainedModels(cmd.Context(), cfg, namespace)
+				if err != nil {
+					return err
+				}
+				for i := range tml {
+					if separate {
+						catalog.PrintDocumentSeparator(cmd.OutOrStdout())
+						separate = false
+					}
+					system := ""
+					if cfg.Entities.Systems {
+						system = tml[i].Namespace
+					}
+					tmOwner, err := nsOwners.Owner(cmd.Context(), tml[i].Namespace)
 					if err != nil {
-						klog.Errorf("inference service retrieval error for %s:%s: %s", namespace, id, err.Error())
-						klog.Flush()
 						return err
 					}
-
-					err = CallBackstagePrinters(owner, lifecycle, is, cmd.OutOrStdout())
+					err = PrintTrainedModel(tmOwner, lifecycle, system, namer, &tml[i], cmd.OutOrStdout())
 					if err != nil {
 						return err
 					}
 				}
-			} else {
-				isl, err := servingClient.InferenceServices(namespace).List(context.Background(), metav1.ListOptions{})
+			}
+			if includeGraphs {
+				igl, err := ListGraphs(cmd.Context(), cfg, namespace)
 				if err != nil {
-					klog.Errorf("inference service retrieval error for %s: %s", namespace, err.Error())
-					klog.Flush()
 					return err
 				}
-				for _, is := range isl.Items {
-					err = CallBackstagePrinters(owner, lifecycle, &is, cmd.OutOrStdout())
+				for i := range igl {
+					if separate {
+						catalog.PrintDocumentSeparator(cmd.OutOrStdout())
+					}
+					system := ""
+					if cfg.Entities.Systems {
+						system = igl[i].Namespace
+					}
+					igOwner, err := nsOwners.Owner(cmd.Context(), igl[i].Namespace)
+					if err != nil {
+						return err
+					}
+					err = PrintGraph(igOwner, lifecycle, system, namer, &igl[i], cmd.OutOrStdout())
 					if err != nil {
-						klog.Errorf("%s", err.Error())
-						klog.Flush()
 						return err
 					}
+					separate = true
 				}
 			}
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&watchMode, "watch", false,
+		"Keep running and regenerate the entities of each InferenceService as it is created, updated, or deleted.")
+	cmd.Flags().StringVar(&outputDir, "output-dir", "",
+		"With --watch, the directory the '<namespace>_<name>.yaml' file for each InferenceService is written to.")
+	cmd.Flags().BoolVar(&toBridge, "to-bridge", false,
+		"With --watch, store the entities of each InferenceService in the ConfigMap the bridge serves them from.")
+	cmd.Flags().BoolVar(&includeRuntimes, "include-runtimes", false,
+		"Also generate a Resource of type model-runtime for each ServingRuntime in the namespace, and ClusterServingRuntime used, which the Component of each InferenceService depends on.")
+	cmd.Flags().BoolVar(&includeGraphs, "include-graphs", false,
+		"Also generate a Component of type model-pipeline, and an API for its endpoint, for each InferenceGraph in the namespace, which depends on the Component of the InferenceService of each step.")
+	cmd.Flags().BoolVar(&includeTrainedModels, "include-trained-models", false,
+		"Also generate a Resource of type ai-model for each TrainedModel in the namespace, which is a dependency of the Component of the multi-model InferenceService serving it.")
+	cmd.Flags().StringVar(&(cfg.Entities.OwnerLabel), "owner-label", cfg.Entities.OwnerLabel,
+		"The label of the namespace of each InferenceService whose value, when set, is the owner of its entities instead of <Owner>; the kind of the owner defaults to group.")
+
 	return cmd
 }
 
-func CallBackstagePrinters(owner, lifecycle string, is *serverapiv1beta1.InferenceService, writer io.Writer) error {
+// NamespaceOwners resolves the owner of the entities of InferenceServices from the --owner-label of their namespace
+type NamespaceOwners struct {
+	cfg    *config.Config
+	owners *catalog.Owners
+	// values caches the label value of each namespace for a single run; it is nil when watching, so changes to the
+	// labels are picked up by the next update of each InferenceService
+	values map[string]string
+}
+
+// Owner returns the owner of the entities of the InferenceServices in namespace
+func (n *NamespaceOwners) Owner(ctx context.Context, namespace string) (string, error) {
+	if len(n.owners.Label()) == 0 {
+		return n.owners.Default, nil
+	}
+	value, ok := n.values[namespace]
+	if !ok {
+		coreClient, err := n.cfg.GetCoreClient()
+		if err != nil {
+			return "", util.NewKubeError(err)
+		}
+		ns, err := coreClient.Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return "", util.NewKubeError(fmt.Errorf("namespace retrieval error for %s: %w", namespace, err))
+		}
+		value = ns.Labels[n.owners.Label()]
+		if n.values != nil {
+			n.values[namespace] = value
+		}
+	}
+	return n.owners.Resolve(value)
+}
+
+// PrintSystems prints the Domain of opts, when set, and a System for each namespace of isl, which is where the
+// InferenceServices of a data science project live, in the Backstage namespace of opts.  Each System is owned by the
+// owner of the entities of the InferenceServices of its namespace, and the Domain by the default owner.
+func PrintSystems(ctx context.Context, owners *NamespaceOwners, opts *config.EntityOptions, isl []serverapiv1beta1.InferenceService, writer io.Writer) error {
+	if len(opts.Domain) > 0 {
+		err := catalog.PrintDomain(opts.Domain, opts.Namespace, owners.owners.Default, writer)
+		if err != nil {
+			return err
+		}
+	}
+	namespaces := []string{}
+	for _, is := range isl {
+		namespaces = append(namespaces, is.Namespace)
+	}
+	slices.Sort(namespaces)
+	for _, namespace := range slices.Compact(namespaces) {
+		owner, err := owners.Owner(ctx, namespace)
+		if err != nil {
+			return err
+		}
+		err = catalog.PrintSystem(namespace, opts.Namespace, "The models served by KServe in the "+namespace+" namespace", owner, opts.Domain, writer)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CallBackstagePrinters prints the Component, Resource, and API of is, named by namer, which are part of system when it
+// is set, and tagged per Tags with the runtime serving is, when known; with dependsOnRuntime the Component depends on
+// the Resource PrintRuntimes prints for the runtime
+func CallBackstagePrinters(owner, lifecycle, system string, namer *catalog.Namer, runtime *Runtime, dependsOnRuntime bool, is *serverapiv1beta1.InferenceService, writer io.Writer) error {
 	compPop := kserve.ComponentPopulator{}
 	compPop.Owner = owner
 	compPop.Lifecycle = lifecycle
 	compPop.InferSvc = is
-	err := backstage.PrintComponent(&compPop, writer)
-	if err != nil {
-		return err
-	}
 
 	resPop := kserve.ResourcePopulator{}
 	resPop.Owner = owner
 	resPop.Lifecycle = lifecycle
 	resPop.InferSvc = is
-	err = backstage.PrintResource(&resPop, writer)
-	if err != nil {
-		return err
-	}
 
 	apiPop := kserve.ApiPopulator{}
 	apiPop.Owner = owner
 	apiPop.Lifecycle = lifecycle
 	apiPop.InferSvc = is
-	err = backstage.PrintAPI(&apiPop, writer)
-	return err
+
+	defaults := catalog.ModelEntities{System: system, Component: compPop.GetName(), Resource: resPop.GetName(), API: apiPop.GetName()}
+	if dependsOnRuntime && runtime != nil {
+		// the name PrintRuntimes gave the Resource of the runtime
+		name, err := namer.Claim(runtime.key(), "resource", runtime.EntityName())
+		if err != nil {
+			return err
+		}
+		defaults.DependsOn = []string{"resource:" + name}
+	}
+	me, err := namer.Name(is.Namespace+"/"+is.Name, catalog.EntityNameData{Namespace: is.Namespace, Name: is.Name}, defaults)
+	if err != nil {
+		return err
+	}
+	tags := Tags(is, runtime)
+	err = catalog.PrintComponent(catalog.ComponentAs(&componentPopulator{ComponentPopulator: &compPop, tags: tags}, &me), writer)
+	if err != nil {
+		return err
+	}
+	err = catalog.PrintResource(catalog.ResourceAs(&resourcePopulator{ResourcePopulator: &resPop, tags: tags}, &me), writer)
+	if err != nil {
+		return err
+	}
+	return catalog.PrintAPI(catalog.APIAs(&apiPopulator{ApiPopulator: &apiPop, tags: tags}, &me), writer)
 }
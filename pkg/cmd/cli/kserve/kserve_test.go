@@ -2,20 +2,32 @@ package kserve
 
 import (
 	"context"
+	servingv1alpha1 "github.com/kserve/kserve/pkg/apis/serving/v1alpha1"
 	serverapiv1beta1 "github.com/kserve/kserve/pkg/apis/serving/v1beta1"
 	fakeservingv1beta1 "github.com/kserve/kserve/pkg/client/clientset/versioned/fake"
-	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/config"
+	"github.com/kserve/kserve/pkg/constants"
 	cobra2 "github.com/redhat-ai-dev/model-catalog-bridge/test/cobra"
 	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
 	"knative.dev/pkg/apis"
+	"net/http"
 	"strings"
 	"testing"
 )
 
 func setupConfig(cfg *config.Config, objs []serverapiv1beta1.InferenceService) {
 	cfg.ServingClient = fakeservingv1beta1.NewSimpleClientset().ServingV1beta1()
+	// no runtimes, graphs, or trained models unless a test sets them
+	cfg.ServingAlphaClient = fakeservingv1beta1.NewSimpleClientset().ServingV1alpha1()
 	for _, obj := range objs {
 		cfg.ServingClient.InferenceServices(obj.Namespace).Create(context.TODO(), &obj, metav1.CreateOptions{})
 		cfg.Namespace = obj.Namespace
@@ -29,8 +41,8 @@ func TestNewCmd(t *testing.T) {
 		generatesError bool
 		generatesHelp  bool
 		errorStr       string
-		// we do output compare in chunks as ranges over the components status map are non-deterministic wrt order
-		outStr []string
+		// the output is compared byte for byte, as the lists in the entities, and the entities themselves, are sorted
+		outStr string
 		is     []serverapiv1beta1.InferenceService
 	}{
 		{
@@ -50,6 +62,24 @@ func TestNewCmd(t *testing.T) {
 			generatesError: true,
 			errorStr:       "need to specify an Owner and Lifecycle setting",
 		},
+		{
+			name:           "output dir without watch",
+			args:           []string{"Owner", "Lifecycle", "--output-dir=catalog"},
+			generatesError: true,
+			errorStr:       "--output-dir and --to-bridge can only be used with --watch",
+		},
+		{
+			name:           "watch without a destination",
+			args:           []string{"Owner", "Lifecycle", "--watch"},
+			generatesError: true,
+			errorStr:       "--watch needs exactly one of --output-dir or --to-bridge",
+		},
+		{
+			name:           "watch with both destinations",
+			args:           []string{"Owner", "Lifecycle", "--watch", "--output-dir=catalog", "--to-bridge"},
+			generatesError: true,
+			errorStr:       "--watch needs exactly one of --output-dir or --to-bridge",
+		},
 		{
 			name: "Owner and Lifecycle but no data",
 			args: []string{"Owner", "Lifecycle"},
@@ -65,7 +95,7 @@ func TestNewCmd(t *testing.T) {
 					},
 				},
 			},
-			outStr: []string{urlNotSet},
+			outStr: urlNotSet,
 		},
 		{
 			name: "Owner and Lifecycle set and data and url",
@@ -84,7 +114,7 @@ func TestNewCmd(t *testing.T) {
 					},
 				},
 			},
-			outStr: []string{urlSet},
+			outStr: urlSet,
 		},
 		{
 			name: "use everything including bunch of tags",
@@ -186,7 +216,7 @@ func TestNewCmd(t *testing.T) {
 					},
 				},
 			},
-			outStr: []string{urlSet, description2, link21, link22, link23, link24, link25, link26, link27, link28, link29, link30, link31, link32, link33, nameTags2, compSpec2, resourceSpec2, apiSpec2},
+			outStr: urlSet + "---\n" + inferSvc2,
 		},
 		{
 			name: "fetch 2 specific inferenceservices",
@@ -288,10 +318,10 @@ func TestNewCmd(t *testing.T) {
 					},
 				},
 			},
-			outStr: []string{urlSet, description2, link21, link22, link23, link24, link25, link26, link27, link28, link29, link30, link31, link32, link33, nameTags2, compSpec2, resourceSpec2, apiSpec2},
+			outStr: urlSet + "---\n" + inferSvc2,
 		},
 	} {
-		cfg := &config.Config{}
+		cfg := config.NewConfig()
 		setupConfig(cfg, tc.is)
 		cmd := NewCmd(cfg)
 		subCmd, stdout, stderr, err := cobra2.ExecuteCommandC(cmd, tc.args...)
@@ -304,12 +334,8 @@ func TestNewCmd(t *testing.T) {
 			t.Errorf("unexpected error output for '%s'- got '%s' but expected '%s'", strings.Join(tc.args, " "), stderr, tc.errorStr)
 		case tc.generatesHelp && !testHelpOK(stdout, subCmd):
 			t.Errorf("unexpected help output for '%s' - got '%s' but expected '%s'", strings.Join(tc.args, " "), stdout, subCmd.Long)
-		case err == nil && !tc.generatesError:
-			if len(tc.outStr) == 1 {
-				common.AssertLineCompare(t, stdout, tc.outStr[0], 0)
-				continue
-			}
-			common.AssertContains(t, stdout, tc.outStr)
+		case err == nil && !tc.generatesError && !tc.generatesHelp:
+			common.AssertEqual(t, tc.outStr, stdout)
 		}
 	}
 
@@ -336,8 +362,8 @@ metadata:
   name: default_InferSvc-1
 spec:
   dependsOn:
-  - resource:default_InferSvc-1
   - api:default_InferSvc-1
+  - resource:default_InferSvc-1
   lifecycle: Lifecycle
   owner: user:Owner
   profile:
@@ -370,6 +396,8 @@ metadata:
   annotations:
     backstage.io/techdocs-ref: api/
   description: KServe instance default:InferSvc-1
+  labels:
+    rhdh.modelcatalog.io/api-type: model-service-api
   name: default_InferSvc-1
 spec:
   definition: ""
@@ -395,8 +423,8 @@ metadata:
   name: default_InferSvc-1
 spec:
   dependsOn:
-  - resource:default_InferSvc-1
   - api:default_InferSvc-1
+  - resource:default_InferSvc-1
   lifecycle: Lifecycle
   owner: user:Owner
   profile:
@@ -434,6 +462,8 @@ metadata:
   annotations:
     backstage.io/techdocs-ref: api/
   description: KServe instance default:InferSvc-1
+  labels:
+    rhdh.modelcatalog.io/api-type: model-service-api
   links:
   - icon: WebAsset
     title: API URL
@@ -451,91 +481,87 @@ spec:
   type: unknown
 `
 
-	description2 = "description: KServe instance default:InferSvc-2"
-	link21       = `  - icon: WebAsset
+	inferSvc2 = `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  annotations:
+    backstage.io/techdocs-ref: ./
+  description: KServe instance default:InferSvc-2
+  links:
+  - icon: WebAsset
     title: API URL
     type: website
     url: https://kserve.com
-`
-	link22 = `  - icon: WebAsset
-    title: transformer FastAPI URL
+  - icon: WebAsset
+    title: explainer FastAPI URL
     type: website
     url: https://kserve.com/docs/docs
-`
-	link23 = `  - icon: WebAsset
-    title: transformer model serving URL
+  - icon: WebAsset
+    title: explainer GRPC model serving URL
     type: website
-    url: https://kserve.com/docs
-`
-	link24 = `  - icon: WebAsset
-    title: transformer REST model serving URL
+    url: https://kserve.com/grpc
+  - icon: WebAsset
+    title: explainer REST model serving URL
     type: website
     url: https://kserve.com/rest
-`
-	link25 = `  - icon: WebAsset
-    title: transformer GRPC model serving URL
+  - icon: WebAsset
+    title: explainer model serving URL
     type: website
-    url: https://kserve.com/grpc
-`
-	link26 = `  - icon: WebAsset
+    url: https://kserve.com/docs
+  - icon: WebAsset
     title: predictor FastAPI URL
     type: website
     url: https://kserve.com/docs/docs
-`
-	link27 = `  - icon: WebAsset
-    title: predictor model serving URL
+  - icon: WebAsset
+    title: predictor GRPC model serving URL
     type: website
-    url: https://kserve.com/docs
-`
-	link28 = `  - icon: WebAsset
+    url: https://kserve.com/grpc
+  - icon: WebAsset
     title: predictor REST model serving URL
     type: website
     url: https://kserve.com/rest
-`
-	link29 = `  - icon: WebAsset
-    title: predictor GRPC model serving URL
+  - icon: WebAsset
+    title: predictor model serving URL
     type: website
-    url: https://kserve.com/grpc
-`
-	link30 = `  - icon: WebAsset
-    title: explainer FastAPI URL
+    url: https://kserve.com/docs
+  - icon: WebAsset
+    title: transformer FastAPI URL
     type: website
     url: https://kserve.com/docs/docs
-`
-	link31 = `  - icon: WebAsset
-    title: explainer model serving URL
+  - icon: WebAsset
+    title: transformer GRPC model serving URL
     type: website
-    url: https://kserve.com/docs
-`
-	link32 = `  - icon: WebAsset
-    title: explainer REST model serving URL
+    url: https://kserve.com/grpc
+  - icon: WebAsset
+    title: transformer REST model serving URL
     type: website
     url: https://kserve.com/rest
-`
-	link33 = `  - icon: WebAsset
-    title: explainer GRPC model serving URL
+  - icon: WebAsset
+    title: transformer model serving URL
     type: website
-    url: https://kserve.com/grpc
-`
-	nameTags2 = `  name: default_InferSvc-2
+    url: https://kserve.com/docs
+  name: default_InferSvc-2
   tags:
-  - sklearn
-  - xgboost
-  - tensorflow
-  - pytorch
-  - triton
-  - onnx
+  - f1
+  - f1-v1-0
   - huggingface
-  - pmml
   - lightgbm
+  - onnx
   - paddle
-  - f1-v1.0
+  - pmml
+  - protocol-openai
+  - protocol-v1
+  - protocol-v2
+  - pytorch
+  - sklearn
   - squareattack
-`
-	compSpec2 = `spec:
+  - tensorflow
+  - triton
+  - xgboost
+spec:
   dependsOn:
-  - resource:default_InferSvc-2
   - api:default_InferSvc-2
+  - resource:default_InferSvc-2
   lifecycle: Lifecycle
   owner: user:Owner
   profile:
@@ -543,8 +569,85 @@ spec:
   providesApis:
   - default_InferSvc-2
   type: model-server
-`
-	resourceSpec2 = `spec:
+---
+apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  annotations:
+    backstage.io/techdocs-ref: resource/
+  description: KServe instance default:InferSvc-2
+  links:
+  - icon: WebAsset
+    title: API URL
+    type: website
+    url: https://kserve.com
+  - icon: WebAsset
+    title: explainer FastAPI URL
+    type: website
+    url: https://kserve.com/docs/docs
+  - icon: WebAsset
+    title: explainer GRPC model serving URL
+    type: website
+    url: https://kserve.com/grpc
+  - icon: WebAsset
+    title: explainer REST model serving URL
+    type: website
+    url: https://kserve.com/rest
+  - icon: WebAsset
+    title: explainer model serving URL
+    type: website
+    url: https://kserve.com/docs
+  - icon: WebAsset
+    title: predictor FastAPI URL
+    type: website
+    url: https://kserve.com/docs/docs
+  - icon: WebAsset
+    title: predictor GRPC model serving URL
+    type: website
+    url: https://kserve.com/grpc
+  - icon: WebAsset
+    title: predictor REST model serving URL
+    type: website
+    url: https://kserve.com/rest
+  - icon: WebAsset
+    title: predictor model serving URL
+    type: website
+    url: https://kserve.com/docs
+  - icon: WebAsset
+    title: transformer FastAPI URL
+    type: website
+    url: https://kserve.com/docs/docs
+  - icon: WebAsset
+    title: transformer GRPC model serving URL
+    type: website
+    url: https://kserve.com/grpc
+  - icon: WebAsset
+    title: transformer REST model serving URL
+    type: website
+    url: https://kserve.com/rest
+  - icon: WebAsset
+    title: transformer model serving URL
+    type: website
+    url: https://kserve.com/docs
+  name: default_InferSvc-2
+  tags:
+  - f1
+  - f1-v1-0
+  - huggingface
+  - lightgbm
+  - onnx
+  - paddle
+  - pmml
+  - protocol-openai
+  - protocol-v1
+  - protocol-v2
+  - pytorch
+  - sklearn
+  - squareattack
+  - tensorflow
+  - triton
+  - xgboost
+spec:
   dependencyOf:
   - component:default_InferSvc-2
   lifecycle: Lifecycle
@@ -554,8 +657,87 @@ spec:
   providesApis:
   - default_InferSvc-2
   type: ai-model
-`
-	apiSpec2 = `spec:
+---
+apiVersion: backstage.io/v1alpha1
+kind: API
+metadata:
+  annotations:
+    backstage.io/techdocs-ref: api/
+  description: KServe instance default:InferSvc-2
+  labels:
+    rhdh.modelcatalog.io/api-type: model-service-api
+  links:
+  - icon: WebAsset
+    title: API URL
+    type: website
+    url: https://kserve.com
+  - icon: WebAsset
+    title: explainer FastAPI URL
+    type: website
+    url: https://kserve.com/docs/docs
+  - icon: WebAsset
+    title: explainer GRPC model serving URL
+    type: website
+    url: https://kserve.com/grpc
+  - icon: WebAsset
+    title: explainer REST model serving URL
+    type: website
+    url: https://kserve.com/rest
+  - icon: WebAsset
+    title: explainer model serving URL
+    type: website
+    url: https://kserve.com/docs
+  - icon: WebAsset
+    title: predictor FastAPI URL
+    type: website
+    url: https://kserve.com/docs/docs
+  - icon: WebAsset
+    title: predictor GRPC model serving URL
+    type: website
+    url: https://kserve.com/grpc
+  - icon: WebAsset
+    title: predictor REST model serving URL
+    type: website
+    url: https://kserve.com/rest
+  - icon: WebAsset
+    title: predictor model serving URL
+    type: website
+    url: https://kserve.com/docs
+  - icon: WebAsset
+    title: transformer FastAPI URL
+    type: website
+    url: https://kserve.com/docs/docs
+  - icon: WebAsset
+    title: transformer GRPC model serving URL
+    type: website
+    url: https://kserve.com/grpc
+  - icon: WebAsset
+    title: transformer REST model serving URL
+    type: website
+    url: https://kserve.com/rest
+  - icon: WebAsset
+    title: transformer model serving URL
+    type: website
+    url: https://kserve.com/docs
+  name: default_InferSvc-2
+  tags:
+  - f1
+  - f1-v1-0
+  - huggingface
+  - lightgbm
+  - onnx
+  - paddle
+  - pmml
+  - protocol-openai
+  - protocol-v1
+  - protocol-v2
+  - pytorch
+  - sklearn
+  - squareattack
+  - tensorflow
+  - triton
+  - xgboost
+spec:
   definition: ""
   dependencyOf:
   - component:default_InferSvc-2
@@ -566,3 +748,482 @@ spec:
   type: unknown
 `
 )
+
+func TestSystems(t *testing.T) {
+	cfg := config.NewConfig()
+	setupConfig(cfg, []serverapiv1beta1.InferenceService{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "InferSvc-1"}},
+	})
+	cfg.Entities = config.EntityOptions{Systems: true, Domain: "ai-models"}
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, true, strings.HasPrefix(stdout, `apiVersion: backstage.io/v1alpha1
+kind: Domain
+metadata:
+  description: The AI models of ai-models
+  name: ai-models
+spec:
+  owner: user:Owner
+---
+apiVersion: backstage.io/v1alpha1
+kind: System
+metadata:
+  description: The models served by KServe in the default namespace
+  name: default
+spec:
+  domain: ai-models
+  owner: user:Owner
+---
+apiVersion: backstage.io/v1alpha1
+kind: Component
+`))
+	// the Component, Resource, and API are all part of the System
+	common.AssertEqual(t, 3, strings.Count(stdout, "\n  system: default\n"))
+
+	for _, tc := range []struct {
+		entities config.EntityOptions
+		args     []string
+		errorStr string
+	}{
+		{entities: config.EntityOptions{Domain: "ai-models"}, errorStr: "--domain can only be used with --systems"},
+		{entities: config.EntityOptions{Systems: true, Domain: "ai models"}, errorStr: `"ai models" is not a valid entity name for --domain`},
+		{entities: config.EntityOptions{Systems: true}, args: []string{"--watch", "--to-bridge"}, errorStr: "--systems cannot be used with --watch or --poll"},
+	} {
+		cfg.Entities = tc.entities
+		_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), append([]string{"Owner", "Lifecycle"}, tc.args...)...)
+		if err == nil || !strings.Contains(err.Error(), tc.errorStr) {
+			t.Errorf("expected an error with %q, got %v", tc.errorStr, err)
+		}
+	}
+}
+
+func TestOwnerLabel(t *testing.T) {
+	requests := 0
+	apiServer := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"default","labels":{"team":"ds"}}}`))
+	})
+	defer apiServer.Close()
+	coreClient, err := corev1client.NewForConfig(&rest.Config{Host: apiServer.URL, ContentConfig: rest.ContentConfig{ContentType: "application/json"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewConfig()
+	cfg.CoreClient = coreClient
+	setupConfig(cfg, []serverapiv1beta1.InferenceService{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "InferSvc-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "InferSvc-2"}},
+	})
+	cfg.Entities = config.EntityOptions{OwnerMap: map[string]string{"ds": "group:default/data-science"}}
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--owner-label=team")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	// the Component, Resource, and API of both InferenceServices are owned by the mapped Group
+	common.AssertEqual(t, 6, strings.Count(stdout, "\n  owner: group:default/data-science\n"))
+	common.AssertEqual(t, 0, strings.Count(stdout, "user:Owner"))
+	// the namespace is looked up once
+	common.AssertEqual(t, 1, requests)
+
+	// the System of the namespace is owned like its models, and the Domain by <Owner>
+	cfg.Entities.Systems = true
+	cfg.Entities.Domain = "ai-models"
+	_, stdout, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--owner-label=team")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, true, strings.Contains(stdout, `kind: System
+metadata:
+  description: The models served by KServe in the default namespace
+  name: default
+spec:
+  domain: ai-models
+  owner: group:default/data-science
+`))
+	common.AssertEqual(t, 7, strings.Count(stdout, "\n  owner: group:default/data-science\n"))
+	common.AssertEqual(t, 1, strings.Count(stdout, "\n  owner: user:Owner\n"))
+}
+
+func TestNameTemplates(t *testing.T) {
+	cfg := config.NewConfig()
+	setupConfig(cfg, []serverapiv1beta1.InferenceService{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "InferSvc-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "InferSvc-2"}},
+	})
+	cfg.Entities = config.EntityOptions{Namespace: "ai", NameTemplates: map[string]string{"component": "{{.Namespace}}-model", "api": "{{.Name}}-api"}}
+	_, _, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle")
+	if util.GetExitCode(err) != int(util.ExitConflict) || !strings.Contains(err.Error(), `gives default/InferSvc-1 and default/InferSvc-2 the same name "default-model"`) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+
+	cfg.Entities.OnCollision = catalog.CollisionSuffix
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	// every entity is in the namespace, and the relations between the entities of each model follow their names
+	common.AssertEqual(t, 6, strings.Count(stdout, "\n  namespace: ai\n"))
+	for _, names := range [][3]string{{"default-model", "default_InferSvc-1", "InferSvc-1-api"}, {"default-model-2", "default_InferSvc-2", "InferSvc-2-api"}} {
+		common.AssertEqual(t, true, strings.Contains(stdout, "\n  name: "+names[0]+"\n"))
+		common.AssertEqual(t, true, strings.Contains(stdout, "\n  dependsOn:\n  - api:"+names[2]+"\n  - resource:"+names[1]+"\n"))
+		common.AssertEqual(t, true, strings.Contains(stdout, "\n  providesApis:\n  - "+names[2]+"\n"))
+		common.AssertEqual(t, 2, strings.Count(stdout, "\n  dependencyOf:\n  - component:"+names[0]+"\n"))
+	}
+}
+
+func TestIncludeRuntimes(t *testing.T) {
+	cfg := config.NewConfig()
+	setupConfig(cfg, []serverapiv1beta1.InferenceService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "granite"},
+			Spec: serverapiv1beta1.InferenceServiceSpec{Predictor: serverapiv1beta1.PredictorSpec{Model: &serverapiv1beta1.ModelSpec{
+				ModelFormat: serverapiv1beta1.ModelFormat{Name: "vLLM"},
+				Runtime:     ptr.To("granite"),
+			}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "mnist"},
+			Spec: serverapiv1beta1.InferenceServiceSpec{Predictor: serverapiv1beta1.PredictorSpec{Model: &serverapiv1beta1.ModelSpec{
+				ModelFormat: serverapiv1beta1.ModelFormat{Name: "onnx"},
+				Runtime:     ptr.To("kserve-ovms"),
+			}}},
+		},
+	})
+	cfg.ServingAlphaClient = fakeservingv1beta1.NewSimpleClientset(
+		&servingv1alpha1.ServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "granite"},
+			Spec: servingv1alpha1.ServingRuntimeSpec{
+				SupportedModelFormats: []servingv1alpha1.SupportedModelFormat{{Name: "vLLM", AutoSelect: ptr.To(true)}},
+				ProtocolVersions:      []constants.InferenceServiceProtocol{constants.ProtocolV2},
+				ServingRuntimePodSpec: servingv1alpha1.ServingRuntimePodSpec{Containers: []corev1.Container{{Image: "quay.io/modh/vllm:rhoai-2.16"}}},
+			},
+		},
+		&servingv1alpha1.ClusterServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "kserve-ovms"},
+			Spec: servingv1alpha1.ServingRuntimeSpec{
+				SupportedModelFormats: []servingv1alpha1.SupportedModelFormat{{Name: "onnx", Version: ptr.To("1")}, {Name: "openvino_ir", Version: ptr.To("opset13")}},
+				MultiModel:            ptr.To(true),
+				ServingRuntimePodSpec: servingv1alpha1.ServingRuntimePodSpec{Containers: []corev1.Container{{Image: "quay.io/modh/openvino_model_server:stable"}}},
+			},
+		},
+		&servingv1alpha1.ClusterServingRuntime{ObjectMeta: metav1.ObjectMeta{Name: "kserve-tritonserver"}},
+	).ServingV1alpha1()
+
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-runtimes")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	// the runtimes are printed first, and the ClusterServingRuntime no InferenceService uses is left out
+	common.AssertEqual(t, true, strings.HasPrefix(stdout, `apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  annotations:
+    backstage.io/techdocs-ref: resource/
+    rhdh.modelcatalog.io/multi-model: "false"
+    rhdh.modelcatalog.io/protocol-versions: v2
+    rhdh.modelcatalog.io/runtime-images: quay.io/modh/vllm:rhoai-2.16
+    rhdh.modelcatalog.io/supported-model-formats: vLLM
+  description: KServe ServingRuntime default:granite
+  name: default_granite_runtime
+  tags:
+  - protocol-v2
+  - vllm
+spec:
+  lifecycle: Lifecycle
+  owner: user:Owner
+  profile:
+    displayName: default_granite_runtime
+  type: model-runtime
+---
+apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  annotations:
+    backstage.io/techdocs-ref: resource/
+    rhdh.modelcatalog.io/multi-model: "true"
+    rhdh.modelcatalog.io/runtime-images: quay.io/modh/openvino_model_server:stable
+    rhdh.modelcatalog.io/supported-model-formats: onnx:1,openvino_ir:opset13
+  description: KServe ClusterServingRuntime kserve-ovms
+  name: kserve-ovms_runtime
+  tags:
+  - onnx
+  - openvino-ir
+  - ovms
+spec:
+  lifecycle: Lifecycle
+  owner: user:Owner
+  profile:
+    displayName: kserve-ovms_runtime
+  type: model-runtime
+---
+apiVersion: backstage.io/v1alpha1
+kind: Component
+`))
+	common.AssertEqual(t, false, strings.Contains(stdout, "tritonserver"))
+	// each Component depends on its runtime
+	common.AssertEqual(t, true, strings.Contains(stdout, "\n  dependsOn:\n  - api:default_granite\n  - resource:default_granite\n  - resource:default_granite_runtime\n"))
+	common.AssertEqual(t, true, strings.Contains(stdout, "\n  dependsOn:\n  - api:default_mnist\n  - resource:default_mnist\n  - resource:kserve-ovms_runtime\n"))
+
+	// the names of the runtimes are claimed, so a Resource of an InferenceService cannot be given one
+	cfg.Entities = config.EntityOptions{NameTemplates: map[string]string{"resource": "{{.Namespace}}_{{.Name}}_runtime"}}
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-runtimes")
+	if util.GetExitCode(err) != int(util.ExitConflict) || !strings.Contains(err.Error(), `gives servingruntime:default/granite and default/granite the same name "default_granite_runtime"`) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+	cfg.Entities.OnCollision = catalog.CollisionSuffix
+	_, stdout, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-runtimes")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, true, strings.Contains(stdout, "\n  dependsOn:\n  - api:default_granite\n  - resource:default_granite_runtime\n  - resource:default_granite_runtime-2\n"))
+	cfg.Entities = config.EntityOptions{}
+
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-runtimes", "--watch", "--to-bridge")
+	if err == nil || !strings.Contains(err.Error(), "--include-runtimes cannot be used with --watch") {
+		t.Errorf("expected a usage error, got %v", err)
+	}
+}
+
+func TestIncludeGraphs(t *testing.T) {
+	cfg := config.NewConfig()
+	setupConfig(cfg, []serverapiv1beta1.InferenceService{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "embedder"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "granite"}},
+	})
+	cfg.ServingAlphaClient = fakeservingv1beta1.NewSimpleClientset(&servingv1alpha1.InferenceGraph{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "rag"},
+		Spec: servingv1alpha1.InferenceGraphSpec{Nodes: map[string]servingv1alpha1.InferenceRouter{
+			servingv1alpha1.GraphRootNodeName: {RouterType: servingv1alpha1.Sequence, Steps: []servingv1alpha1.InferenceStep{
+				{StepName: "embed", InferenceTarget: servingv1alpha1.InferenceTarget{ServiceName: "embedder"}},
+				{StepName: "generate", InferenceTarget: servingv1alpha1.InferenceTarget{NodeName: "split"}},
+			}},
+			"split": {RouterType: servingv1alpha1.Splitter, Steps: []servingv1alpha1.InferenceStep{
+				{InferenceTarget: servingv1alpha1.InferenceTarget{ServiceName: "granite"}, Weight: ptr.To(int64(80))},
+				{InferenceTarget: servingv1alpha1.InferenceTarget{ServiceURL: "http://canary.default.svc"}, Weight: ptr.To(int64(20))},
+			}},
+		}},
+		Status: servingv1alpha1.InferenceGraphStatus{URL: &apis.URL{Scheme: "https", Host: "rag.kserve.com"}},
+	}).ServingV1alpha1()
+
+	cfg.Entities = config.EntityOptions{NameTemplates: map[string]string{"component": "{{.Name}}-model"}}
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-graphs")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	// the graph is printed after the InferenceServices, and depends on their Components by the names they were given;
+	// its Component is named by the template too
+	common.AssertEqual(t, true, strings.HasSuffix(stdout, `---
+apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  annotations:
+    backstage.io/techdocs-ref: ./
+    rhdh.modelcatalog.io/graph-nodes: '{"root":{"routerType":"Sequence","steps":[{"name":"embed","serviceName":"embedder"},{"name":"generate","nodeName":"split"}]},"split":{"routerType":"Splitter","steps":[{"serviceName":"granite","weight":80},{"serviceUrl":"http://canary.default.svc","weight":20}]}}'
+    rhdh.modelcatalog.io/router-type: Sequence
+  description: KServe InferenceGraph default:rag
+  links:
+  - icon: WebAsset
+    title: API URL
+    type: website
+    url: https://rag.kserve.com
+  name: rag-model
+  tags:
+  - sequence
+  - splitter
+spec:
+  dependsOn:
+  - api:default_rag_graph
+  - component:embedder-model
+  - component:granite-model
+  lifecycle: Lifecycle
+  owner: user:Owner
+  profile:
+    displayName: default_rag_graph
+  providesApis:
+  - default_rag_graph
+  type: model-pipeline
+---
+apiVersion: backstage.io/v1alpha1
+kind: API
+metadata:
+  annotations:
+    backstage.io/techdocs-ref: api/
+  description: KServe InferenceGraph default:rag
+  labels:
+    rhdh.modelcatalog.io/api-type: model-service-api
+  links:
+  - icon: WebAsset
+    title: API URL
+    type: website
+    url: https://rag.kserve.com
+  name: default_rag_graph
+  tags:
+  - sequence
+  - splitter
+spec:
+  definition: no-definition-yet
+  dependencyOf:
+  - component:rag-model
+  lifecycle: Lifecycle
+  owner: user:Owner
+  profile:
+    displayName: default_rag_graph
+  type: openapi
+`))
+
+	// the names of the graph are claimed with those of the InferenceServices
+	cfg.Entities = config.EntityOptions{OnCollision: catalog.CollisionSuffix, NameTemplates: map[string]string{"api": "{{.Namespace}}-api"}}
+	_, stdout, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-graphs")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, true, strings.Contains(stdout, "\n  name: default-api-3\n"))
+	common.AssertEqual(t, true, strings.Contains(stdout, "\n  dependsOn:\n  - api:default-api-3\n  - component:default_embedder\n  - component:default_granite\n"))
+	common.AssertEqual(t, true, strings.Contains(stdout, "\n  providesApis:\n  - default-api-3\n  type: model-pipeline\n"))
+	cfg.Entities = config.EntityOptions{}
+
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-graphs", "--watch", "--to-bridge")
+	if err == nil || !strings.Contains(err.Error(), "--include-graphs cannot be used with --watch") {
+		t.Errorf("expected a usage error, got %v", err)
+	}
+}
+
+func TestIncludeTrainedModels(t *testing.T) {
+	cfg := config.NewConfig()
+	setupConfig(cfg, []serverapiv1beta1.InferenceService{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "triton-mms"}},
+	})
+	cfg.ServingAlphaClient = fakeservingv1beta1.NewSimpleClientset(
+		&servingv1alpha1.TrainedModel{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "simple-string"},
+			Spec: servingv1alpha1.TrainedModelSpec{InferenceService: "triton-mms", Model: servingv1alpha1.ModelSpec{
+				StorageURI: "gs://kfserving-examples/models/triton/simple_string",
+				Framework:  "tensorflow",
+				Memory:     resource.MustParse("1Gi"),
+			}},
+			Status: servingv1alpha1.TrainedModelStatus{URL: &apis.URL{Scheme: "http", Host: "triton-mms.default.example.com", Path: "/v2/models/simple-string/infer"}},
+		},
+		&servingv1alpha1.TrainedModel{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "cifar10"},
+			Spec: servingv1alpha1.TrainedModelSpec{InferenceService: "triton-mms", Model: servingv1alpha1.ModelSpec{
+				StorageURI: "s3://models/cifar10",
+				Framework:  "pytorch",
+				Memory:     resource.MustParse("512Mi"),
+			}},
+		},
+		&servingv1alpha1.InferenceGraph{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "pipeline"}},
+	).ServingV1alpha1()
+
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-trained-models", "--include-graphs")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	// the TrainedModels are printed after the InferenceService, in name order, and before the graphs
+	common.AssertEqual(t, true, strings.Contains(stdout, `  type: unknown
+---
+apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  annotations:
+    backstage.io/techdocs-ref: resource/
+    rhdh.modelcatalog.io/framework: pytorch
+    rhdh.modelcatalog.io/memory: 512Mi
+    rhdh.modelcatalog.io/storage-uri: s3://models/cifar10
+  description: KServe TrainedModel default:cifar10 served by triton-mms
+  name: default_cifar10_model
+  tags:
+  - pytorch
+  - storage-s3
+spec:
+  dependencyOf:
+  - component:default_triton-mms
+  lifecycle: Lifecycle
+  owner: user:Owner
+  profile:
+    displayName: default_cifar10_model
+  type: ai-model
+---
+apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  annotations:
+    backstage.io/techdocs-ref: resource/
+    rhdh.modelcatalog.io/framework: tensorflow
+    rhdh.modelcatalog.io/memory: 1Gi
+    rhdh.modelcatalog.io/storage-uri: gs://kfserving-examples/models/triton/simple_string
+  description: KServe TrainedModel default:simple-string served by triton-mms
+  links:
+  - icon: WebAsset
+    title: API URL
+    type: website
+    url: http://triton-mms.default.example.com/v2/models/simple-string/infer
+  name: default_simple-string_model
+  tags:
+  - storage-gs
+  - tensorflow
+spec:
+  dependencyOf:
+  - component:default_triton-mms
+  lifecycle: Lifecycle
+  owner: user:Owner
+  profile:
+    displayName: default_simple-string_model
+  type: ai-model
+---
+apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  annotations:
+    backstage.io/techdocs-ref: ./
+  description: KServe InferenceGraph default:pipeline
+`))
+	// every document is separated once
+	common.AssertEqual(t, false, strings.Contains(stdout, "---\n---"))
+
+	// the name of the Component hosting a TrainedModel is looked up, not claimed, so a TrainedModel of an
+	// InferenceService which is gone does not collide with the InferenceServices
+	cfg.Entities = config.EntityOptions{NameTemplates: map[string]string{"component": "{{.Namespace}}-model"}}
+	cfg.ServingAlphaClient = fakeservingv1beta1.NewSimpleClientset(
+		&servingv1alpha1.TrainedModel{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "orphan"},
+			Spec:       servingv1alpha1.TrainedModelSpec{InferenceService: "gone"},
+		},
+	).ServingV1alpha1()
+	_, stdout, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-trained-models")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, true, strings.Contains(stdout, "\n  name: default_orphan_model\n"))
+	// the Resource and API of the InferenceService, and the Resource of the TrainedModel
+	common.AssertEqual(t, 3, strings.Count(stdout, "\n  dependencyOf:\n  - component:default-model\n"))
+
+	// the Resource of a TrainedModel is named by the resource --name-template, and claimed like the Resource of the
+	// InferenceService
+	cfg.Entities = config.EntityOptions{NameTemplates: map[string]string{"resource": "{{.Namespace}}-{{.Name}}"}}
+	cfg.ServingAlphaClient = fakeservingv1beta1.NewSimpleClientset(
+		&servingv1alpha1.TrainedModel{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "triton-mms"},
+			Spec:       servingv1alpha1.TrainedModelSpec{InferenceService: "triton-mms"},
+		},
+	).ServingV1alpha1()
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-trained-models")
+	if err == nil || !strings.Contains(err.Error(), `gives default/triton-mms and trainedmodel:default/triton-mms the same name "default-triton-mms"`) {
+		t.Errorf("expected a collision error, got %v", err)
+	}
+	cfg.Entities.OnCollision = catalog.CollisionSuffix
+	_, stdout, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-trained-models")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, true, strings.Contains(stdout, "\n  name: default-triton-mms-2\n"))
+	common.AssertEqual(t, true, strings.Contains(stdout, "\n    displayName: default_triton-mms_model\n"))
+	cfg.Entities = config.EntityOptions{}
+
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--include-trained-models", "--watch", "--to-bridge")
+	if err == nil || !strings.Contains(err.Error(), "--include-trained-models cannot be used with --watch") {
+		t.Errorf("expected a usage error, got %v", err)
+	}
+}
@@ -0,0 +1,379 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	servingv1alpha1 "github.com/kserve/kserve/pkg/apis/serving/v1alpha1"
+	serverapiv1beta1 "github.com/kserve/kserve/pkg/apis/serving/v1beta1"
+	"github.com/kserve/kserve/pkg/constants"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	brdgutil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// RUNTIME_TYPE is the spec.type of the Resources of the runtimes, so they can be told apart from the ai-model
+	// Resources of the InferenceServices they serve
+	RUNTIME_TYPE = "model-runtime"
+
+	RUNTIME_FORMATS_ANNOTATION     = "rhdh.modelcatalog.io/supported-model-formats"
+	RUNTIME_IMAGES_ANNOTATION      = "rhdh.modelcatalog.io/runtime-images"
+	RUNTIME_PROTOCOLS_ANNOTATION   = "rhdh.modelcatalog.io/protocol-versions"
+	RUNTIME_MULTI_MODEL_ANNOTATION = "rhdh.modelcatalog.io/multi-model"
+)
+
+// Runtime is the ServingRuntime, or the ClusterServingRuntime when Namespace is empty, an InferenceService is served by
+type Runtime struct {
+	Name      string
+	Namespace string
+	Spec      *servingv1alpha1.ServingRuntimeSpec
+	// created orders the runtimes which can be auto-selected
+	created metav1.Time
+}
+
+// Runtimes resolves the runtime of the predictor of an InferenceService, like KServe does, from the ServingRuntimes in
+// its namespace, then the ClusterServingRuntimes; the runtime is the one the predictor names, or else the one KServe
+// auto-selects for its model format
+type Runtimes struct {
+	cfg *config.Config
+	// values caches the runtime for each namespace and name for a single run; it is nil when watching, so changes to the
+	// runtimes are picked up by the next update of each InferenceService
+	values map[string]*Runtime
+}
+
+// Get returns the runtime of is, which is nil when its predictor has no model format, or the runtime does not exist,
+// or no runtime supports the model format, or the runtimes cannot be read
+func (r *Runtimes) Get(ctx context.Context, is *serverapiv1beta1.InferenceService) (*Runtime, error) {
+	model := is.Spec.Predictor.Model
+	if model == nil {
+		return nil, nil
+	}
+	var key string
+	var get func() (*Runtime, error)
+	switch {
+	case model.Runtime != nil && len(*model.Runtime) > 0:
+		key = is.Namespace + "/" + *model.Runtime
+		get = func() (*Runtime, error) { return r.get(ctx, is.Namespace, *model.Runtime) }
+	case len(model.ModelFormat.Name) > 0:
+		// the key of an auto-selected runtime has what the selection depends on, and a ':', which a name cannot have
+		isMMS := is.Annotations[constants.DeploymentMode] == string(constants.ModelMeshDeployment)
+		isMultinode := is.Spec.Predictor.WorkerSpec != nil
+		key = fmt.Sprintf("%s/%s:%s:%s:%t:%t", is.Namespace, model.ModelFormat.Name, ptr.Deref(model.ModelFormat.Version, ""), model.GetProtocol(), isMMS, isMultinode)
+		get = func() (*Runtime, error) { return r.autoSelect(ctx, is.Namespace, model, isMMS, isMultinode) }
+	default:
+		return nil, nil
+	}
+	if runtime, ok := r.values[key]; ok {
+		return runtime, nil
+	}
+	runtime, err := get()
+	if err != nil {
+		return nil, err
+	}
+	if r.values != nil {
+		r.values[key] = runtime
+	}
+	return runtime, nil
+}
+
+func (r *Runtimes) get(ctx context.Context, namespace, name string) (*Runtime, error) {
+	client, err := r.cfg.GetServingAlphaClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	sr, err := client.ServingRuntimes(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return &Runtime{Name: sr.Name, Namespace: sr.Namespace, Spec: &sr.Spec}, nil
+	case errors.IsForbidden(err):
+		// the runtime cannot be told when the ServingRuntime of the namespace, which comes first, cannot be read
+		return nil, nil
+	case !errors.IsNotFound(err):
+		return nil, util.NewKubeError(fmt.Errorf("serving runtime retrieval error for %s:%s: %w", namespace, name, err))
+	}
+	csr, err := client.ClusterServingRuntimes("").Get(ctx, name, metav1.GetOptions{})
+	// reading the ClusterServingRuntimes takes a cluster role many users of a data science project do not have
+	if errors.IsNotFound(err) || errors.IsForbidden(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, util.NewKubeError(fmt.Errorf("cluster serving runtime retrieval error for %s: %w", name, err))
+	}
+	return &Runtime{Name: csr.Name, Spec: &csr.Spec}, nil
+}
+
+// autoSelect returns the runtime KServe's ModelSpec.GetSupportingRuntimes puts first for model: the enabled runtimes
+// which auto-select its model format, and support its protocol and deployment mode, sorted by their protocol,
+// creation, and name, and then by the priority of the model format, with the ServingRuntimes of namespace before the
+// ClusterServingRuntimes.  The ServingRuntimes are skipped when the user is forbidden to list them, and the
+// ClusterServingRuntimes also when their API is not installed, and nil is returned when no runtime is left.
+func (r *Runtimes) autoSelect(ctx context.Context, namespace string, model *serverapiv1beta1.ModelSpec, isMMS, isMultinode bool) (*Runtime, error) {
+	client, err := r.cfg.GetServingAlphaClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	supports := func(spec *servingv1alpha1.ServingRuntimeSpec) bool {
+		return !spec.IsDisabled() && spec.IsMultiModelRuntime() == isMMS && model.RuntimeSupportsModel(spec) &&
+			spec.IsProtocolVersionSupported(model.GetProtocol()) && spec.IsMultiNodeRuntime() == isMultinode
+	}
+	list, err := client.ServingRuntimes(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !errors.IsForbidden(err) {
+		return nil, util.NewKubeError(fmt.Errorf("serving runtime retrieval error for %s: %w", namespace, err))
+	}
+	runtimes := []*Runtime{}
+	if err == nil {
+		for i := range list.Items {
+			sr := &list.Items[i]
+			if supports(&sr.Spec) {
+				runtimes = append(runtimes, &Runtime{Name: sr.Name, Namespace: sr.Namespace, Spec: &sr.Spec, created: sr.CreationTimestamp})
+			}
+		}
+	}
+	if runtime := selectRuntime(runtimes, model.ModelFormat.Name); runtime != nil {
+		return runtime, nil
+	}
+	clusterList, err := client.ClusterServingRuntimes("").List(ctx, metav1.ListOptions{})
+	if errors.IsNotFound(err) || errors.IsForbidden(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, util.NewKubeError(fmt.Errorf("cluster serving runtime retrieval error: %w", err))
+	}
+	runtimes = runtimes[:0]
+	for i := range clusterList.Items {
+		csr := &clusterList.Items[i]
+		if supports(&csr.Spec) {
+			runtimes = append(runtimes, &Runtime{Name: csr.Name, Spec: &csr.Spec, created: csr.CreationTimestamp})
+		}
+	}
+	return selectRuntime(runtimes, model.ModelFormat.Name), nil
+}
+
+// selectRuntime returns the first of runtimes in KServe's order, or nil when there are none
+func selectRuntime(runtimes []*Runtime, modelFormat string) *Runtime {
+	slices.SortStableFunc(runtimes, func(a, b *Runtime) int {
+		pa, pb := serverapiv1beta1.GetProtocolVersionPriority(a.Spec.ProtocolVersions), serverapiv1beta1.GetProtocolVersionPriority(b.Spec.ProtocolVersions)
+		if pa != pb {
+			return pa - pb
+		}
+		if !a.created.Equal(&b.created) {
+			// the newest first
+			return b.created.Compare(a.created.Time)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	slices.SortStableFunc(runtimes, func(a, b *Runtime) int {
+		pa, pb := a.Spec.GetPriority(modelFormat), b.Spec.GetPriority(modelFormat)
+		switch {
+		case pa == nil && pb == nil:
+			return 0
+		case pa == nil:
+			return 1
+		case pb == nil:
+			return -1
+		}
+		return int(*pb - *pa)
+	})
+	if len(runtimes) == 0 {
+		return nil
+	}
+	return runtimes[0]
+}
+
+// EntityName is the name of the Resource of the runtime, which, unlike the names of the entities of an
+// InferenceService, ends with '_runtime', as RHOAI names the ServingRuntime of a model after its InferenceService
+func (r *Runtime) EntityName() string {
+	if len(r.Namespace) == 0 {
+		return brdgutil.SanitizeName(r.Name + "_runtime")
+	}
+	return brdgutil.SanitizeName(r.Namespace + "_" + r.Name + "_runtime")
+}
+
+// key is the key the Namer claims the name of the Resource of the runtime for, which is apart from the keys of the
+// InferenceServices, as a ServingRuntime is often named like the InferenceService it serves
+func (r *Runtime) key() string {
+	if len(r.Namespace) == 0 {
+		return "clusterservingruntime:" + r.Name
+	}
+	return "servingruntime:" + r.Namespace + "/" + r.Name
+}
+
+// List returns the ServingRuntimes in namespace, and the ClusterServingRuntimes isl are served by, in the order of
+// their entity names
+func (r *Runtimes) List(ctx context.Context, namespace string, isl []serverapiv1beta1.InferenceService) ([]*Runtime, error) {
+	client, err := r.cfg.GetServingAlphaClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	list, err := client.ServingRuntimes(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, util.NewKubeError(fmt.Errorf("serving runtime retrieval error for %s: %w", namespace, err))
+	}
+	runtimes := map[string]*Runtime{}
+	for i := range list.Items {
+		sr := &list.Items[i]
+		runtime := &Runtime{Name: sr.Name, Namespace: sr.Namespace, Spec: &sr.Spec}
+		runtimes[runtime.EntityName()] = runtime
+	}
+	for i := range isl {
+		runtime, err := r.Get(ctx, &isl[i])
+		if err != nil {
+			return nil, err
+		}
+		if runtime != nil {
+			runtimes[runtime.EntityName()] = runtime
+		}
+	}
+	names := []string{}
+	for name := range runtimes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	sorted := []*Runtime{}
+	for _, name := range names {
+		sorted = append(sorted, runtimes[name])
+	}
+	return sorted, nil
+}
+
+// PrintRuntimes prints a Resource for each of runtimes in the Backstage namespace of namer, named by namer, which claims
+// the names so the entities of the InferenceServices cannot be given them; the runtimes are shared by the
+// InferenceServices of every data science project, so they are not part of a System
+func PrintRuntimes(owner, lifecycle string, namer *catalog.Namer, runtimes []*Runtime, writer io.Writer) error {
+	for _, runtime := range runtimes {
+		name, err := namer.Claim(runtime.key(), "resource", runtime.EntityName())
+		if err != nil {
+			return err
+		}
+		err = catalog.PrintResource(&RuntimePopulator{Owner: owner, Lifecycle: lifecycle, Namespace: namer.Namespace(), Name: name, Runtime: runtime}, writer)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RuntimePopulator populates the Resource of a ServingRuntime or ClusterServingRuntime with the model formats, images,
+// and protocol versions it supports, and whether it serves more than one model
+type RuntimePopulator struct {
+	Owner     string
+	Lifecycle string
+	// Namespace is the Backstage namespace of the Resource
+	Namespace string
+	// Name is the name the Namer gave the Resource; the EntityName of the runtime is used when it is empty
+	Name    string
+	Runtime *Runtime
+}
+
+func (pop *RuntimePopulator) GetOwner() string {
+	return pop.Owner
+}
+
+func (pop *RuntimePopulator) GetLifecycle() string {
+	return pop.Lifecycle
+}
+
+func (pop *RuntimePopulator) GetName() string {
+	if len(pop.Name) > 0 {
+		return pop.Name
+	}
+	return pop.Runtime.EntityName()
+}
+
+func (pop *RuntimePopulator) GetNamespace() string {
+	return pop.Namespace
+}
+
+func (pop *RuntimePopulator) GetType() string {
+	return RUNTIME_TYPE
+}
+
+func (pop *RuntimePopulator) GetDescription() string {
+	if len(pop.Runtime.Namespace) == 0 {
+		return fmt.Sprintf("KServe ClusterServingRuntime %s", pop.Runtime.Name)
+	}
+	return fmt.Sprintf("KServe ServingRuntime %s:%s", pop.Runtime.Namespace, pop.Runtime.Name)
+}
+
+func (pop *RuntimePopulator) GetLinks() []backstage.EntityLink {
+	return []backstage.EntityLink{}
+}
+
+// GetTags returns the model servers the runtime is built on, the protocols it serves, and the names of the model
+// formats it supports
+func (pop *RuntimePopulator) GetTags() []string {
+	spec := pop.Runtime.Spec
+	values := runtimeTags(spec.Containers, pop.Runtime.Name)
+	for _, protocol := range spec.ProtocolVersions {
+		values = append(values, "protocol-"+string(protocol))
+	}
+	for _, format := range spec.SupportedModelFormats {
+		values = append(values, format.Name)
+	}
+	tags := []string{}
+	for _, value := range values {
+		if tag := catalog.SanitizeTag(value); len(tag) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (pop *RuntimePopulator) GetAnnotations() map[string]string {
+	spec := pop.Runtime.Spec
+	formats := []string{}
+	for _, format := range spec.SupportedModelFormats {
+		if format.Version != nil {
+			formats = append(formats, format.Name+":"+*format.Version)
+			continue
+		}
+		formats = append(formats, format.Name)
+	}
+	images := []string{}
+	for _, container := range spec.Containers {
+		images = append(images, container.Image)
+	}
+	protocols := []string{}
+	for _, protocol := range spec.ProtocolVersions {
+		protocols = append(protocols, string(protocol))
+	}
+	annotations := map[string]string{RUNTIME_MULTI_MODEL_ANNOTATION: strconv.FormatBool(spec.MultiModel != nil && *spec.MultiModel)}
+	for key, values := range map[string][]string{
+		RUNTIME_FORMATS_ANNOTATION:   formats,
+		RUNTIME_IMAGES_ANNOTATION:    images,
+		RUNTIME_PROTOCOLS_ANNOTATION: protocols,
+	} {
+		if len(values) > 0 {
+			annotations[key] = strings.Join(values, ",")
+		}
+	}
+	return annotations
+}
+
+func (pop *RuntimePopulator) GetProvidedAPIs() []string {
+	return []string{}
+}
+
+func (pop *RuntimePopulator) GetDependencyOf() []string {
+	return []string{}
+}
+
+func (pop *RuntimePopulator) GetTechdocRef() string {
+	return "resource/"
+}
+
+// GetDisplayName is the EntityName of the runtime even when the Namer suffixes the name, like the display names of the
+// entities of an InferenceService
+func (pop *RuntimePopulator) GetDisplayName() string {
+	return pop.Runtime.EntityName()
+}
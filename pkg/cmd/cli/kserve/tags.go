@@ -0,0 +1,163 @@
+package kserve
+
+import (
+	"slices"
+	"strings"
+
+	serverapiv1beta1 "github.com/kserve/kserve/pkg/apis/serving/v1beta1"
+	"github.com/kserve/kserve/pkg/constants"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/kserve"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// OPENAI_PROTOCOL is the protocol tag of the InferenceServices which serve the OpenAI compatible API
+	OPENAI_PROTOCOL = "openai"
+)
+
+// runtimeFamilies maps what appears in the name or container images of a runtime to the tag of the model server it is;
+// a runtime can be more than one, like Caikit with TGIS
+var runtimeFamilies = []struct {
+	match []string
+	tag   string
+}{
+	{match: []string{"vllm"}, tag: "vllm"},
+	{match: []string{"caikit"}, tag: "caikit"},
+	{match: []string{"tgis", "text-generation-inference"}, tag: "tgis"},
+	{match: []string{"ovms", "openvino"}, tag: "ovms"},
+	{match: []string{"triton"}, tag: "triton"},
+}
+
+// Tags returns the tags of is, replacing those of the bridge's kserve.CommonPopulator, which tags a predictor with every
+// framework listed after its own.  They are, with each value sanitized for Backstage:
+//   - the framework of each predictor implementation, or the name, and name and version, of the model format
+//   - the model servers, like vllm or ovms, the runtime, or the containers of a custom predictor, are built on
+//   - 'protocol-<version>' for the protocol each predictor implementation is served with, plus 'protocol-openai' for
+//     vLLM and Hugging Face, which also serve the OpenAI compatible API
+//   - 'storage-<scheme>' for the scheme of the storage URI of each predictor implementation, like s3, pvc, oci, or hf
+//   - the type of the ART explainer, as the bridge does
+//
+// The tags are sorted, without duplicates.
+func Tags(is *serverapiv1beta1.InferenceService, runtime *Runtime) []string {
+	tags := []string{}
+	add := func(values ...string) {
+		for _, value := range values {
+			if tag := catalog.SanitizeTag(value); len(tag) > 0 {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	predictor := &is.Spec.Predictor
+	for _, framework := range []struct {
+		set bool
+		tag string
+	}{
+		{predictor.SKLearn != nil, "sklearn"},
+		{predictor.XGBoost != nil, "xgboost"},
+		{predictor.Tensorflow != nil, "tensorflow"},
+		{predictor.PyTorch != nil, "pytorch"},
+		{predictor.Triton != nil, "triton"},
+		{predictor.ONNX != nil, "onnx"},
+		{predictor.HuggingFace != nil, "huggingface"},
+		{predictor.PMML != nil, "pmml"},
+		{predictor.LightGBM != nil, "lightgbm"},
+		{predictor.Paddle != nil, "paddle"},
+	} {
+		if framework.set {
+			add(framework.tag)
+		}
+	}
+	if predictor.Model != nil {
+		modelFormat := predictor.Model.ModelFormat
+		add(modelFormat.Name)
+		if modelFormat.Version != nil {
+			add(modelFormat.Name + "-" + *modelFormat.Version)
+		}
+	}
+
+	families := runtimeTags(predictor.Containers)
+	if runtime != nil {
+		families = append(families, runtimeTags(runtime.Spec.Containers, runtime.Name)...)
+	}
+	add(families...)
+
+	openAI := predictor.HuggingFace != nil || (predictor.Model != nil && strings.EqualFold(predictor.Model.ModelFormat.Name, "huggingface"))
+	for _, family := range families {
+		openAI = openAI || family == "vllm"
+	}
+	for _, impl := range predictor.GetImplementations() {
+		protocol := impl.GetProtocol()
+		// the protocol of a model format defaults to the first one its runtime supports
+		if model, ok := impl.(*serverapiv1beta1.ModelSpec); ok && model.ProtocolVersion == nil && runtime != nil && len(runtime.Spec.ProtocolVersions) > 0 {
+			protocol = runtime.Spec.ProtocolVersions[0]
+		}
+		if protocol != constants.ProtocolUnknown {
+			add("protocol-" + string(protocol))
+		}
+		if uri := impl.GetStorageUri(); uri != nil {
+			if scheme, _, found := strings.Cut(*uri, "://"); found {
+				add("storage-" + scheme)
+			}
+		}
+	}
+	if openAI {
+		add("protocol-" + OPENAI_PROTOCOL)
+	}
+
+	explainer := is.Spec.Explainer
+	if explainer != nil && explainer.ART != nil {
+		add(string(explainer.ART.Type))
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+// runtimeTags returns the tag of each of the runtimeFamilies that names, or the images of containers, match
+func runtimeTags(containers []corev1.Container, names ...string) []string {
+	for _, container := range containers {
+		names = append(names, container.Image)
+	}
+	tags := []string{}
+	for _, family := range runtimeFamilies {
+		found := false
+		for _, name := range names {
+			for _, match := range family.match {
+				found = found || strings.Contains(strings.ToLower(name), match)
+			}
+		}
+		if found {
+			tags = append(tags, family.tag)
+		}
+	}
+	return tags
+}
+
+// componentPopulator, resourcePopulator, and apiPopulator replace the tags of the bridge's populators with Tags; each
+// returns a copy, as the printers sort and compact the tags in place
+type componentPopulator struct {
+	*kserve.ComponentPopulator
+	tags []string
+}
+
+func (pop *componentPopulator) GetTags() []string {
+	return slices.Clone(pop.tags)
+}
+
+type resourcePopulator struct {
+	*kserve.ResourcePopulator
+	tags []string
+}
+
+func (pop *resourcePopulator) GetTags() []string {
+	return slices.Clone(pop.tags)
+}
+
+type apiPopulator struct {
+	*kserve.ApiPopulator
+	tags []string
+}
+
+func (pop *apiPopulator) GetTags() []string {
+	return slices.Clone(pop.tags)
+}
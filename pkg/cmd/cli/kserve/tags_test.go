@@ -0,0 +1,249 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	servingv1alpha1 "github.com/kserve/kserve/pkg/apis/serving/v1alpha1"
+	serverapiv1beta1 "github.com/kserve/kserve/pkg/apis/serving/v1beta1"
+	fakeservingv1beta1 "github.com/kserve/kserve/pkg/client/clientset/versioned/fake"
+	"github.com/kserve/kserve/pkg/constants"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+func TestTags(t *testing.T) {
+	autoSelect := func(name string, version *string, priority int32) servingv1alpha1.SupportedModelFormat {
+		return servingv1alpha1.SupportedModelFormat{Name: name, Version: version, AutoSelect: ptr.To(true), Priority: ptr.To(priority)}
+	}
+	cfg := config.NewConfig()
+	cfg.ServingAlphaClient = fakeservingv1beta1.NewSimpleClientset(
+		// auto-selected for tensorflow ahead of the ClusterServingRuntime with a higher priority, as it is namespaced
+		&servingv1alpha1.ServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "triton-runtime"},
+			Spec: servingv1alpha1.ServingRuntimeSpec{
+				SupportedModelFormats: []servingv1alpha1.SupportedModelFormat{autoSelect("tensorflow", nil, 1)},
+				ServingRuntimePodSpec: servingv1alpha1.ServingRuntimePodSpec{Containers: []corev1.Container{{Image: "nvcr.io/nvidia/tritonserver:23.05-py3"}}},
+				ProtocolVersions:      []constants.InferenceServiceProtocol{constants.ProtocolV2, constants.ProtocolGRPCV2},
+			},
+		},
+		&servingv1alpha1.ClusterServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "kserve-tensorflow-serving"},
+			Spec: servingv1alpha1.ServingRuntimeSpec{
+				SupportedModelFormats: []servingv1alpha1.SupportedModelFormat{autoSelect("tensorflow", nil, 2)},
+				ServingRuntimePodSpec: servingv1alpha1.ServingRuntimePodSpec{Containers: []corev1.Container{{Image: "tensorflow/serving:2.6.2"}}},
+			},
+		},
+		// of the two ClusterServingRuntimes auto-selecting onnx, the one with the higher priority is selected
+		&servingv1alpha1.ClusterServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "kserve-tritonserver"},
+			Spec: servingv1alpha1.ServingRuntimeSpec{
+				SupportedModelFormats: []servingv1alpha1.SupportedModelFormat{autoSelect("onnx", ptr.To("1"), 1)},
+				ServingRuntimePodSpec: servingv1alpha1.ServingRuntimePodSpec{Containers: []corev1.Container{{Image: "nvcr.io/nvidia/tritonserver:23.05-py3"}}},
+			},
+		},
+		// the vLLM ServingRuntime is only used when named, as it does not auto-select its model format
+		&servingv1alpha1.ServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "vllm-runtime"},
+			Spec: servingv1alpha1.ServingRuntimeSpec{
+				SupportedModelFormats: []servingv1alpha1.SupportedModelFormat{{Name: "vLLM"}},
+				ServingRuntimePodSpec: servingv1alpha1.ServingRuntimePodSpec{Containers: []corev1.Container{{Image: "quay.io/modh/vllm:rhoai-2.16"}}},
+				ProtocolVersions:      []constants.InferenceServiceProtocol{constants.ProtocolV2},
+			},
+		},
+		&servingv1alpha1.ClusterServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "kserve-ovms"},
+			Spec: servingv1alpha1.ServingRuntimeSpec{
+				SupportedModelFormats: []servingv1alpha1.SupportedModelFormat{autoSelect("onnx", ptr.To("1"), 2)},
+				ServingRuntimePodSpec: servingv1alpha1.ServingRuntimePodSpec{Containers: []corev1.Container{{Image: "quay.io/modh/openvino_model_server:stable"}}},
+			},
+		},
+		&servingv1alpha1.ClusterServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "caikit-tgis-runtime"},
+			Spec: servingv1alpha1.ServingRuntimeSpec{
+				ServingRuntimePodSpec: servingv1alpha1.ServingRuntimePodSpec{Containers: []corev1.Container{
+					{Image: "quay.io/modh/text-generation-inference:stable"},
+					{Image: "quay.io/modh/caikit-tgis-serving:stable"},
+				}},
+				ProtocolVersions: []constants.InferenceServiceProtocol{constants.ProtocolGRPCV1},
+			},
+		},
+	).ServingV1alpha1()
+	runtimes := &Runtimes{cfg: cfg, values: map[string]*Runtime{}}
+
+	extension := func(uri string) serverapiv1beta1.PredictorExtensionSpec {
+		return serverapiv1beta1.PredictorExtensionSpec{StorageURI: ptr.To(uri)}
+	}
+	for _, tc := range []struct {
+		name      string
+		predictor serverapiv1beta1.PredictorSpec
+		tags      []string
+	}{
+		{
+			name:      "sklearn",
+			predictor: serverapiv1beta1.PredictorSpec{SKLearn: &serverapiv1beta1.SKLearnSpec{PredictorExtensionSpec: extension("s3://models/iris")}},
+			tags:      []string{"protocol-v1", "sklearn", "storage-s3"},
+		},
+		{
+			name:      "xgboost",
+			predictor: serverapiv1beta1.PredictorSpec{XGBoost: &serverapiv1beta1.XGBoostSpec{PredictorExtensionSpec: extension("gs://models/xgb")}},
+			tags:      []string{"protocol-v1", "storage-gs", "xgboost"},
+		},
+		{
+			name:      "tensorflow",
+			predictor: serverapiv1beta1.PredictorSpec{Tensorflow: &serverapiv1beta1.TFServingSpec{PredictorExtensionSpec: extension("pvc://models/flowers")}},
+			tags:      []string{"protocol-v1", "storage-pvc", "tensorflow"},
+		},
+		{
+			name:      "pytorch",
+			predictor: serverapiv1beta1.PredictorSpec{PyTorch: &serverapiv1beta1.TorchServeSpec{}},
+			tags:      []string{"protocol-v1", "pytorch"},
+		},
+		{
+			name:      "triton",
+			predictor: serverapiv1beta1.PredictorSpec{Triton: &serverapiv1beta1.TritonSpec{PredictorExtensionSpec: extension("oci://quay.io/models/resnet:1")}},
+			tags:      []string{"protocol-v2", "storage-oci", "triton"},
+		},
+		{
+			name:      "onnx",
+			predictor: serverapiv1beta1.PredictorSpec{ONNX: &serverapiv1beta1.ONNXRuntimeSpec{}},
+			tags:      []string{"onnx", "protocol-v1"},
+		},
+		{
+			name:      "huggingface",
+			predictor: serverapiv1beta1.PredictorSpec{HuggingFace: &serverapiv1beta1.HuggingFaceRuntimeSpec{PredictorExtensionSpec: extension("hf://meta-llama/Llama-3.1-8B")}},
+			tags:      []string{"huggingface", "protocol-openai", "protocol-v2", "storage-hf"},
+		},
+		{
+			name:      "pmml",
+			predictor: serverapiv1beta1.PredictorSpec{PMML: &serverapiv1beta1.PMMLSpec{}},
+			tags:      []string{"pmml", "protocol-v1"},
+		},
+		{
+			name:      "lightgbm",
+			predictor: serverapiv1beta1.PredictorSpec{LightGBM: &serverapiv1beta1.LightGBMSpec{}},
+			tags:      []string{"lightgbm", "protocol-v1"},
+		},
+		{
+			name:      "paddle",
+			predictor: serverapiv1beta1.PredictorSpec{Paddle: &serverapiv1beta1.PaddleServerSpec{}},
+			tags:      []string{"paddle", "protocol-v1"},
+		},
+		{
+			name: "model format without a runtime",
+			predictor: serverapiv1beta1.PredictorSpec{Model: &serverapiv1beta1.ModelSpec{
+				ModelFormat:            serverapiv1beta1.ModelFormat{Name: "sklearn", Version: ptr.To("1.0")},
+				PredictorExtensionSpec: serverapiv1beta1.PredictorExtensionSpec{ProtocolVersion: ptr.To(constants.ProtocolV2)},
+			}},
+			tags: []string{"protocol-v2", "sklearn", "sklearn-1-0"},
+		},
+		{
+			name: "model format auto-selecting a ServingRuntime",
+			predictor: serverapiv1beta1.PredictorSpec{Model: &serverapiv1beta1.ModelSpec{
+				ModelFormat:            serverapiv1beta1.ModelFormat{Name: "tensorflow"},
+				PredictorExtensionSpec: serverapiv1beta1.PredictorExtensionSpec{ProtocolVersion: ptr.To(constants.ProtocolV2)},
+			}},
+			tags: []string{"protocol-v2", "tensorflow", "triton"},
+		},
+		{
+			name: "model format auto-selecting a ClusterServingRuntime",
+			predictor: serverapiv1beta1.PredictorSpec{Model: &serverapiv1beta1.ModelSpec{
+				ModelFormat: serverapiv1beta1.ModelFormat{Name: "onnx", Version: ptr.To("1")},
+			}},
+			tags: []string{"onnx", "onnx-1", "ovms", "protocol-v1"},
+		},
+		{
+			name: "model format no runtime auto-selects",
+			predictor: serverapiv1beta1.PredictorSpec{Model: &serverapiv1beta1.ModelSpec{
+				ModelFormat: serverapiv1beta1.ModelFormat{Name: "vLLM"},
+			}},
+			tags: []string{"protocol-v1", "vllm"},
+		},
+		{
+			name: "model format with a ServingRuntime",
+			predictor: serverapiv1beta1.PredictorSpec{Model: &serverapiv1beta1.ModelSpec{
+				ModelFormat:            serverapiv1beta1.ModelFormat{Name: "vLLM"},
+				Runtime:                ptr.To("vllm-runtime"),
+				PredictorExtensionSpec: extension("oci://quay.io/models/granite:1"),
+			}},
+			tags: []string{"protocol-openai", "protocol-v2", "storage-oci", "vllm"},
+		},
+		{
+			name: "model format with a ClusterServingRuntime",
+			predictor: serverapiv1beta1.PredictorSpec{Model: &serverapiv1beta1.ModelSpec{
+				ModelFormat:            serverapiv1beta1.ModelFormat{Name: "onnx", Version: ptr.To("1")},
+				Runtime:                ptr.To("kserve-ovms"),
+				PredictorExtensionSpec: extension("s3://models/mnist"),
+			}},
+			tags: []string{"onnx", "onnx-1", "ovms", "protocol-v1", "storage-s3"},
+		},
+		{
+			name: "model format with a runtime of two model servers",
+			predictor: serverapiv1beta1.PredictorSpec{Model: &serverapiv1beta1.ModelSpec{
+				ModelFormat:            serverapiv1beta1.ModelFormat{Name: "caikit"},
+				Runtime:                ptr.To("caikit-tgis-runtime"),
+				PredictorExtensionSpec: extension("pvc://models/flan-t5"),
+			}},
+			tags: []string{"caikit", "protocol-grpc-v1", "storage-pvc", "tgis"},
+		},
+		{
+			name: "model format with a runtime which does not exist",
+			predictor: serverapiv1beta1.PredictorSpec{Model: &serverapiv1beta1.ModelSpec{
+				ModelFormat: serverapiv1beta1.ModelFormat{Name: "pytorch"},
+				Runtime:     ptr.To("missing"),
+			}},
+			tags: []string{"protocol-v1", "pytorch"},
+		},
+		{
+			name: "custom predictor",
+			predictor: serverapiv1beta1.PredictorSpec{PodSpec: serverapiv1beta1.PodSpec{Containers: []corev1.Container{{
+				Name:  constants.InferenceServiceContainerName,
+				Image: "docker.io/vllm/vllm-openai:latest",
+				Env:   []corev1.EnvVar{{Name: constants.CustomSpecStorageUriEnvVarKey, Value: "hf://ibm-granite/granite-3.0-8b-instruct"}},
+			}}}},
+			tags: []string{"protocol-openai", "protocol-v1", "storage-hf", "vllm"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			is := &serverapiv1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "InferSvc-1"},
+				Spec:       serverapiv1beta1.InferenceServiceSpec{Predictor: tc.predictor},
+			}
+			runtime, err := runtimes.Get(context.TODO(), is)
+			common.AssertError(t, err)
+			common.AssertEqual(t, tc.tags, Tags(is, runtime))
+		})
+	}
+}
+
+func TestRuntimesForbidden(t *testing.T) {
+	// a user who cannot read the runtimes, namespaced or cluster scoped, gets the tags which do not need them
+	clientset := fakeservingv1beta1.NewSimpleClientset()
+	clientset.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		resource := action.GetResource()
+		return true, nil, errors.NewForbidden(resource.GroupResource(), "", fmt.Errorf("no access to %s", resource.Resource))
+	})
+	cfg := config.NewConfig()
+	cfg.ServingAlphaClient = clientset.ServingV1alpha1()
+	runtimes := &Runtimes{cfg: cfg, values: map[string]*Runtime{}}
+	for _, model := range []*serverapiv1beta1.ModelSpec{
+		{ModelFormat: serverapiv1beta1.ModelFormat{Name: "vLLM"}, Runtime: ptr.To("vllm-runtime")},
+		{ModelFormat: serverapiv1beta1.ModelFormat{Name: "vLLM"}},
+	} {
+		is := &serverapiv1beta1.InferenceService{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "InferSvc-1"},
+			Spec:       serverapiv1beta1.InferenceServiceSpec{Predictor: serverapiv1beta1.PredictorSpec{Model: model}},
+		}
+		found, err := runtimes.Get(context.TODO(), is)
+		common.AssertError(t, err)
+		common.AssertEqual(t, true, found == nil)
+		common.AssertEqual(t, []string{"protocol-v1", "vllm"}, Tags(is, found))
+	}
+}
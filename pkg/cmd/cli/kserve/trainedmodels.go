@@ -0,0 +1,171 @@
+package kserve
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	servingv1alpha1 "github.com/kserve/kserve/pkg/apis/serving/v1alpha1"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	brdgutil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the framework, storage URI, and memory of a TrainedModel, which the Resource of the model has as annotations
+	TRAINED_MODEL_FRAMEWORK_ANNOTATION   = "rhdh.modelcatalog.io/framework"
+	TRAINED_MODEL_STORAGE_URI_ANNOTATION = "rhdh.modelcatalog.io/storage-uri"
+	TRAINED_MODEL_MEMORY_ANNOTATION      = "rhdh.modelcatalog.io/memory"
+)
+
+// ListTrainedModels returns the TrainedModels in namespace in name order
+func ListTrainedModels(ctx context.Context, cfg *config.Config, namespace string) ([]servingv1alpha1.TrainedModel, error) {
+	client, err := cfg.GetServingAlphaClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	list, err := client.TrainedModels(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, util.NewKubeError(fmt.Errorf("trained model retrieval error for %s: %w", namespace, err))
+	}
+	tml := list.Items
+	slices.SortFunc(tml, func(a, b servingv1alpha1.TrainedModel) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return tml, nil
+}
+
+// PrintTrainedModel prints the ai-model Resource of tm, named by namer like the Resource of an InferenceService, in the
+// Backstage namespace of namer, which is part of system when it is set, and a dependency of the Component of the
+// multi-model InferenceService hosting it, looked up from namer, which named it in CallBackstagePrinters, without
+// claiming the names again
+func PrintTrainedModel(owner, lifecycle, system string, namer *catalog.Namer, tm *servingv1alpha1.TrainedModel, writer io.Writer) error {
+	pop := &TrainedModelPopulator{Owner: owner, Lifecycle: lifecycle, Namespace: namer.Namespace(), System: system, TrainedModel: tm}
+	// the key of a TrainedModel is apart from the keys of the InferenceServices, which can have the same namespace and
+	// name
+	me, err := namer.Name("trainedmodel:"+tm.Namespace+"/"+tm.Name, catalog.EntityNameData{Namespace: tm.Namespace, Name: tm.Name},
+		catalog.ModelEntities{Resource: pop.GetName()})
+	if err != nil {
+		return err
+	}
+	pop.Name = me.Resource
+	if isName := tm.Spec.InferenceService; len(isName) > 0 {
+		name := fmt.Sprintf("%s_%s", tm.Namespace, isName)
+		me, err := namer.Lookup(tm.Namespace+"/"+isName, catalog.EntityNameData{Namespace: tm.Namespace, Name: isName},
+			catalog.ModelEntities{Component: name, Resource: name, API: name})
+		if err != nil {
+			return err
+		}
+		pop.Host = me.Component
+	}
+	return catalog.PrintResource(pop, writer)
+}
+
+// TrainedModelPopulator populates the Resource of a TrainedModel, one of the models a multi-model InferenceService
+// serves, with its framework, storage URI, and memory
+type TrainedModelPopulator struct {
+	Owner     string
+	Lifecycle string
+	// Namespace is the Backstage namespace of the Resource
+	Namespace    string
+	System       string
+	TrainedModel *servingv1alpha1.TrainedModel
+	// Host is the name of the Component of the InferenceService the model is served by
+	Host string
+	// Name is the name the Namer gave the Resource
+	Name string
+}
+
+func (pop *TrainedModelPopulator) GetOwner() string {
+	return pop.Owner
+}
+
+func (pop *TrainedModelPopulator) GetLifecycle() string {
+	return pop.Lifecycle
+}
+
+// GetName is the name the Namer gave the Resource, or, when it is not named yet, ends with '_model', so a TrainedModel
+// and an InferenceService of the same name do not collide
+func (pop *TrainedModelPopulator) GetName() string {
+	if len(pop.Name) > 0 {
+		return pop.Name
+	}
+	return brdgutil.SanitizeName(fmt.Sprintf("%s_%s_model", pop.TrainedModel.Namespace, pop.TrainedModel.Name))
+}
+
+func (pop *TrainedModelPopulator) GetNamespace() string {
+	return pop.Namespace
+}
+
+func (pop *TrainedModelPopulator) GetSystem() string {
+	return pop.System
+}
+
+func (pop *TrainedModelPopulator) GetDescription() string {
+	return fmt.Sprintf("KServe TrainedModel %s:%s served by %s", pop.TrainedModel.Namespace, pop.TrainedModel.Name, pop.TrainedModel.Spec.InferenceService)
+}
+
+// GetLinks returns the inference URL of the model, which KServe sets once the InferenceService has loaded it
+func (pop *TrainedModelPopulator) GetLinks() []backstage.EntityLink {
+	links := []backstage.EntityLink{}
+	if pop.TrainedModel.Status.URL != nil {
+		links = append(links, backstage.EntityLink{
+			URL:   pop.TrainedModel.Status.URL.String(),
+			Title: backstage.LINK_API_URL,
+			Icon:  backstage.LINK_ICON_WEBASSET,
+			Type:  backstage.LINK_TYPE_WEBSITE,
+		})
+	}
+	return links
+}
+
+// GetTags returns the framework of the model and the scheme of its storage URI, like the tags of an InferenceService
+func (pop *TrainedModelPopulator) GetTags() []string {
+	model := pop.TrainedModel.Spec.Model
+	values := []string{model.Framework}
+	if scheme, _, found := strings.Cut(model.StorageURI, "://"); found {
+		values = append(values, "storage-"+scheme)
+	}
+	tags := []string{}
+	for _, value := range values {
+		if tag := catalog.SanitizeTag(value); len(tag) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (pop *TrainedModelPopulator) GetAnnotations() map[string]string {
+	model := pop.TrainedModel.Spec.Model
+	return map[string]string{
+		TRAINED_MODEL_FRAMEWORK_ANNOTATION:   model.Framework,
+		TRAINED_MODEL_STORAGE_URI_ANNOTATION: model.StorageURI,
+		TRAINED_MODEL_MEMORY_ANNOTATION:      model.Memory.String(),
+	}
+}
+
+func (pop *TrainedModelPopulator) GetProvidedAPIs() []string {
+	return []string{}
+}
+
+func (pop *TrainedModelPopulator) GetDependencyOf() []string {
+	if len(pop.Host) == 0 {
+		return []string{}
+	}
+	return []string{"component:" + pop.Host}
+}
+
+func (pop *TrainedModelPopulator) GetTechdocRef() string {
+	return "resource/"
+}
+
+// GetDisplayName is the name the Resource has when it is not named by the Namer, like the display names of the
+// entities of an InferenceService
+func (pop *TrainedModelPopulator) GetDisplayName() string {
+	return (&TrainedModelPopulator{TrainedModel: pop.TrainedModel}).GetName()
+}
@@ -0,0 +1,341 @@
+package kserve
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	serverapiv1beta1 "github.com/kserve/kserve/pkg/apis/serving/v1beta1"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/bridge"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DEFAULT_WATCH_RESYNC is how often every InferenceService is regenerated even without a change, and compared with
+	// the content of the sink, which repairs files or ConfigMap keys changed or removed by someone else
+	DEFAULT_WATCH_RESYNC = 10 * time.Minute
+
+	// inferenceServiceDescription is the description the bridge gives the Component of an InferenceService, from which
+	// the watch tells the content it stored from the content stored by others
+	inferenceServiceDescription = "KServe instance "
+)
+
+// sink is where the watch stores the catalog-info.yaml content of each InferenceService, keyed by namespace and name;
+// Get returns no content for a key which is not there
+type sink interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Keys(ctx context.Context) ([]string, error)
+	Put(ctx context.Context, key string, content []byte) error
+	Remove(ctx context.Context, key string) error
+}
+
+// dirSink writes each key to <dir>/<key>.yaml
+type dirSink struct {
+	dir string
+}
+
+func (s *dirSink) Get(_ context.Context, key string) ([]byte, error) {
+	buf, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return buf, err
+}
+
+// Keys skips the hidden temporary files of Put
+func (s *dirSink) Keys(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for _, entry := range entries {
+		key, ok := strings.CutSuffix(entry.Name(), ".yaml")
+		if ok && entry.Type().IsRegular() && !strings.HasPrefix(key, ".") {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (s *dirSink) Put(_ context.Context, key string, content []byte) error {
+	// write to a temporary file first so a reader, like a Git sync, never sees a partially written file
+	tmp, err := os.CreateTemp(s.dir, "."+key+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (s *dirSink) Remove(_ context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *dirSink) path(key string) string {
+	return filepath.Join(s.dir, key+".yaml")
+}
+
+// bridgeSink stores each key in the content ConfigMap the bridge serves the catalog-info.yaml files from
+type bridgeSink struct {
+	cfg *config.Config
+}
+
+func (s *bridgeSink) Get(ctx context.Context, key string) ([]byte, error) {
+	return bridge.GetContent(ctx, s.cfg, key)
+}
+
+func (s *bridgeSink) Keys(ctx context.Context) ([]string, error) {
+	content, err := bridge.ListContent(ctx, s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(content)), nil
+}
+
+func (s *bridgeSink) Put(ctx context.Context, key string, content []byte) error {
+	return bridge.AddContent(ctx, s.cfg, key, content)
+}
+
+func (s *bridgeSink) Remove(ctx context.Context, key string) error {
+	return bridge.RemoveContent(ctx, s.cfg, key)
+}
+
+// watcher regenerates the entities of an InferenceService whenever it is added or updated, and removes them when
+// it is deleted
+type watcher struct {
+	ctx       context.Context
+	cfg       *config.Config
+	owners    *NamespaceOwners
+	namer     *catalog.Namer
+	runtimes  *Runtimes
+	lifecycle string
+	// names limits the InferenceServices watched; all of them in the namespace are watched when empty
+	names  []string
+	sink   sink
+	errOut io.Writer
+	// written is the content last stored for each key, so status only updates do not rewrite it; the event handlers
+	// are called one at a time, so no lock is needed
+	written map[string][]byte
+}
+
+// Run removes the keys of the InferenceServices deleted while the watch was not running, and then watches the
+// InferenceServices until the context is canceled
+func (w *watcher) Run(resync time.Duration) error {
+	isClient := w.cfg.ServingClient.InferenceServices(w.cfg.Namespace)
+	isl, err := isClient.List(w.ctx, metav1.ListOptions{})
+	if err != nil {
+		return util.NewKubeError(fmt.Errorf("inference service retrieval error for %s: %w", w.cfg.Namespace, err))
+	}
+	err = w.prune(isl.Items)
+	if err != nil {
+		return err
+	}
+
+	lw := &cache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			return isClient.List(ctx, options)
+		},
+		WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			return isClient.Watch(ctx, options)
+		},
+	}
+	informer := cache.NewSharedIndexInformer(lw, &serverapiv1beta1.InferenceService{}, resync, cache.Indexers{})
+	_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { w.update(obj, false) },
+		UpdateFunc: func(old, obj interface{}) {
+			// the informer resyncs by calling the update handler with the same object, and so the same version
+			oldMeta, oldErr := meta.Accessor(old)
+			newMeta, newErr := meta.Accessor(obj)
+			w.update(obj, oldErr == nil && newErr == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion())
+		},
+		DeleteFunc: w.remove,
+	})
+	if err != nil {
+		return err
+	}
+
+	go informer.RunWithContext(w.ctx)
+	if !cache.WaitForCacheSync(w.ctx.Done(), informer.HasSynced) {
+		if w.ctx.Err() != nil {
+			return nil
+		}
+		return util.NewKubeError(fmt.Errorf("inference service watch in %s did not sync", w.cfg.Namespace))
+	}
+	<-w.ctx.Done()
+	return nil
+}
+
+// prune removes the keys of the sink which the watch stored for watched InferenceServices in the namespace, but which
+// are not of one of isl; the other keys of the sink, like those of a model registry, or those added with
+// add-bridge-content, are left alone, even when they start with the namespace
+func (w *watcher) prune(isl []serverapiv1beta1.InferenceService) error {
+	keys, err := w.sink.Keys(w.ctx)
+	if err != nil {
+		return util.NewError(util.ExitError, fmt.Errorf("listing the entities already stored: %w", err))
+	}
+	live := map[string]bool{}
+	for _, is := range isl {
+		live[w.key(is.Namespace, is.Name)] = true
+	}
+	prefix := bridge.ContentKeyPrefix(w.cfg.Namespace)
+	for _, key := range keys {
+		if live[key] || !strings.HasPrefix(key, prefix) || !w.watchedKey(key) {
+			continue
+		}
+		content, err := w.sink.Get(w.ctx, key)
+		if err != nil {
+			return util.NewError(util.ExitError, fmt.Errorf("reading the entities stored for %s: %w", key, err))
+		}
+		if !w.stored(key, content) {
+			continue
+		}
+		err = w.sink.Remove(w.ctx, key)
+		if err != nil {
+			return util.NewError(util.ExitError, fmt.Errorf("removing the entities for %s: %w", key, err))
+		}
+		fmt.Fprintf(w.errOut, "Removed %s\n", key)
+	}
+	return nil
+}
+
+// update regenerates the entities of obj, and stores them when they differ from what was last stored, or, on a
+// resync, from what the sink has
+func (w *watcher) update(obj interface{}, resync bool) {
+	is, ok := obj.(*serverapiv1beta1.InferenceService)
+	if !ok || !w.watched(is.Name) {
+		return
+	}
+	key := w.key(is.Namespace, is.Name)
+	owner, err := w.owners.Owner(w.ctx, is.Namespace)
+	if err != nil {
+		fmt.Fprintf(w.errOut, "Error finding the owner of %s/%s: %s\n", is.Namespace, is.Name, err.Error())
+		return
+	}
+	runtime, err := w.runtimes.Get(w.ctx, is)
+	if err != nil {
+		fmt.Fprintf(w.errOut, "Error finding the runtime of %s/%s: %s\n", is.Namespace, is.Name, err.Error())
+		return
+	}
+	buf := &bytes.Buffer{}
+	err = CallBackstagePrinters(owner, w.lifecycle, "", w.namer, runtime, false, is, buf)
+	if err != nil {
+		fmt.Fprintf(w.errOut, "Error generating the entities for %s/%s: %s\n", is.Namespace, is.Name, err.Error())
+		return
+	}
+	last, ok := w.written[key]
+	if resync {
+		last, err = w.sink.Get(w.ctx, key)
+		if err != nil {
+			fmt.Fprintf(w.errOut, "Error reading the entities stored for %s/%s: %s\n", is.Namespace, is.Name, err.Error())
+			return
+		}
+		ok = last != nil
+	}
+	if ok && bytes.Equal(last, buf.Bytes()) {
+		w.written[key] = buf.Bytes()
+		return
+	}
+	err = w.sink.Put(w.ctx, key, buf.Bytes())
+	if err != nil {
+		fmt.Fprintf(w.errOut, "Error storing the entities for %s/%s: %s\n", is.Namespace, is.Name, err.Error())
+		return
+	}
+	w.written[key] = buf.Bytes()
+	fmt.Fprintf(w.errOut, "Updated %s/%s\n", is.Namespace, is.Name)
+}
+
+func (w *watcher) remove(obj interface{}) {
+	nsName, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(nsName)
+	if err != nil || !w.watched(name) {
+		return
+	}
+	key := w.key(namespace, name)
+	err = w.sink.Remove(w.ctx, key)
+	if err != nil {
+		fmt.Fprintf(w.errOut, "Error removing the entities for %s/%s: %s\n", namespace, name, err.Error())
+		return
+	}
+	delete(w.written, key)
+	w.namer.Release(namespace + "/" + name)
+	fmt.Fprintf(w.errOut, "Removed %s/%s\n", namespace, name)
+}
+
+func (w *watcher) watched(name string) bool {
+	return len(w.names) == 0 || slices.Contains(w.names, name)
+}
+
+// watchedKey is whether key is the key of one of the names watched, which all keys are when no names are given
+func (w *watcher) watchedKey(key string) bool {
+	return len(w.names) == 0 || slices.ContainsFunc(w.names, func(name string) bool {
+		return key == w.key(w.cfg.Namespace, name)
+	})
+}
+
+// stored is whether content is what the watch stores under key, which has the Component of the InferenceService
+// whose key is key
+func (w *watcher) stored(key string, content []byte) bool {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+	for {
+		doc, err := reader.Read()
+		if err != nil {
+			return false
+		}
+		entity := struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Description string `json:"description"`
+			} `json:"metadata"`
+		}{}
+		if yaml.Unmarshal(doc, &entity) != nil || entity.Kind != "Component" {
+			continue
+		}
+		nsName, ok := strings.CutPrefix(entity.Metadata.Description, inferenceServiceDescription)
+		namespace, name, found := strings.Cut(nsName, ":")
+		if ok && found && namespace == w.cfg.Namespace && w.key(namespace, name) == key {
+			return true
+		}
+	}
+}
+
+// key is the <namespace>_<name> key the bridge serves the content of the InferenceService under, sanitized like
+// add-bridge-content sanitizes it; the namespace and name of an InferenceService always make a valid key
+func (w *watcher) key(namespace, name string) string {
+	key, _ := bridge.ContentKey(namespace, name)
+	return key
+}
@@ -0,0 +1,173 @@
+package kserve
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	serverapiv1beta1 "github.com/kserve/kserve/pkg/apis/serving/v1beta1"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+// syncBuffer lets the test read the progress lines while the watch is writing them
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.NewConfig()
+	setupConfig(cfg, []serverapiv1beta1.InferenceService{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "InferSvc-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "not-watched"}},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	owners, err := catalog.NewOwners(ctx, cfg, "Owner")
+	common.AssertError(t, err)
+	namer, err := catalog.NewNamer(&cfg.Entities)
+	common.AssertError(t, err)
+	progress := &syncBuffer{}
+	w := &watcher{
+		ctx:       ctx,
+		cfg:       cfg,
+		owners:    &NamespaceOwners{cfg: cfg, owners: owners},
+		namer:     namer,
+		runtimes:  &Runtimes{cfg: cfg},
+		lifecycle: "Lifecycle",
+		names:     []string{"InferSvc-1"},
+		sink:      &dirSink{dir: dir},
+		errOut:    progress,
+		written:   map[string][]byte{},
+	}
+	done := make(chan error)
+	go func() { done <- w.Run(DEFAULT_WATCH_RESYNC) }()
+
+	file := filepath.Join(dir, metav1.NamespaceDefault+"_infersvc-1.yaml")
+	waitFor(t, "the initial file", func() bool {
+		buf, err := os.ReadFile(file)
+		return err == nil && string(buf) == urlNotSet
+	})
+
+	// an update regenerates the file
+	is, _ := cfg.ServingClient.InferenceServices(metav1.NamespaceDefault).Get(ctx, "InferSvc-1", metav1.GetOptions{})
+	is.Status.URL = &apis.URL{Scheme: "https", Host: "kserve.com"}
+	_, _ = cfg.ServingClient.InferenceServices(metav1.NamespaceDefault).Update(ctx, is, metav1.UpdateOptions{})
+	waitFor(t, "the updated file", func() bool {
+		buf, err := os.ReadFile(file)
+		return err == nil && string(buf) == urlSet
+	})
+
+	// a delete removes the file
+	_ = cfg.ServingClient.InferenceServices(metav1.NamespaceDefault).Delete(ctx, "InferSvc-1", metav1.DeleteOptions{})
+	waitFor(t, "the file to be removed", func() bool {
+		_, err := os.Stat(file)
+		return os.IsNotExist(err) && strings.Contains(progress.String(), "Removed")
+	})
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, "Updated default/InferSvc-1\nUpdated default/InferSvc-1\nRemoved default/InferSvc-1\n", progress.String())
+	entries, _ := os.ReadDir(dir)
+	common.AssertEqual(t, 0, len(entries))
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestWatchPruneAndResync(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		metav1.NamespaceDefault + "_deleted.yaml": "kind: Component\nmetadata:\n  description: KServe instance default:deleted\n",
+		metav1.NamespaceDefault + "_v1.yaml":      "kind: Component\nmetadata:\n  description: added with add-bridge-content\n",
+		metav1.NamespaceDefault + "_stale.yaml":   "kind: Component\nmetadata:\n  description: KServe instance default:deleted\n",
+		"other_InferSvc-1.yaml":                   "kind: Component\nmetadata:\n  description: KServe instance other:InferSvc-1\n",
+		"README.md":                               "not a key",
+	} {
+		common.AssertError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	cfg := config.NewConfig()
+	setupConfig(cfg, []serverapiv1beta1.InferenceService{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "InferSvc-1"}},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	owners, err := catalog.NewOwners(ctx, cfg, "Owner")
+	common.AssertError(t, err)
+	namer, err := catalog.NewNamer(&cfg.Entities)
+	common.AssertError(t, err)
+	progress := &syncBuffer{}
+	w := &watcher{
+		ctx:       ctx,
+		cfg:       cfg,
+		owners:    &NamespaceOwners{cfg: cfg, owners: owners},
+		namer:     namer,
+		runtimes:  &Runtimes{cfg: cfg},
+		lifecycle: "Lifecycle",
+		sink:      &dirSink{dir: dir},
+		errOut:    progress,
+		written:   map[string][]byte{},
+	}
+	done := make(chan error)
+	go func() { done <- w.Run(time.Second) }()
+
+	// the file of the InferenceService deleted while the watch was not running is removed at startup, but not the
+	// files of the namespace the watch did not store, like one added with add-bridge-content
+	file := filepath.Join(dir, metav1.NamespaceDefault+"_infersvc-1.yaml")
+	waitFor(t, "the initial file", func() bool {
+		buf, err := os.ReadFile(file)
+		return err == nil && string(buf) == urlNotSet
+	})
+	_, err = os.Stat(filepath.Join(dir, metav1.NamespaceDefault+"_deleted.yaml"))
+	common.AssertEqual(t, true, os.IsNotExist(err))
+	for _, name := range []string{"_v1.yaml", "_stale.yaml"} {
+		_, err = os.Stat(filepath.Join(dir, metav1.NamespaceDefault+name))
+		common.AssertError(t, err)
+	}
+
+	// a resync restores the file changed by someone else
+	common.AssertError(t, os.WriteFile(file, []byte("changed"), 0644))
+	waitFor(t, "the restored file", func() bool {
+		buf, err := os.ReadFile(file)
+		return err == nil && string(buf) == urlNotSet
+	})
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, "Removed default_deleted\nUpdated default/InferSvc-1\nUpdated default/InferSvc-1\n", progress.String())
+	entries, _ := os.ReadDir(dir)
+	common.AssertEqual(t, 5, len(entries))
+}
@@ -1,17 +1,21 @@
 package kubeflowmodelregistry
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/kubeflow/model-registry/pkg/openapi"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
 	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/kubeflowmodelregistry"
-	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/config"
-	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
-	butil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	brdgutil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
 	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
 	"github.com/spf13/cobra"
-	"k8s.io/klog/v2"
 )
 
 const (
@@ -24,6 +28,24 @@ $ %s new-model kubeflow <Owner> <Lifecycle> <args...>
 # This will set the URL, Token, and Skip TLS when accessing Kubeflow
 $ %s new-model kubeflow <Owner> <Lifecycle> --model-metadata-url=https://my-kubeflow.com --model-metadata-token=my-token --model-metadata-skip-tls=true
 
+# This will trust the CA in the 'ca.crt' key of the 'model-registry-ca' Secret in the 'rhoai-model-registries' namespace,
+# and present a client certificate, when accessing Kubeflow
+$ %s new-model kubeflow <Owner> <Lifecycle> --model-metadata-url=https://my-kubeflow.com --model-metadata-ca-ref=secret:rhoai-model-registries/model-registry-ca --model-metadata-client-cert-file=tls.crt --model-metadata-client-key-file=tls.key
+
+# This will authenticate to Kubeflow as the OpenShift user currently logged in with 'oc login', or with the token stored in
+# the 'token' key of the 'model-registry-token' Secret in the 'rhoai-model-registries' namespace, so the token does not
+# end up in the shell history
+$ %s new-model kubeflow <Owner> <Lifecycle> --model-metadata-url=https://my-kubeflow.com --model-metadata-token-from-kubeconfig
+$ %s new-model kubeflow <Owner> <Lifecycle> --model-metadata-url=https://my-kubeflow.com --model-metadata-token-secret=rhoai-model-registries/model-registry-token
+
+# This will send up to 10 requests to Kubeflow at once, listing 500 items per page, for registries with many models
+$ %s new-model kubeflow <Owner> <Lifecycle> --concurrency=10 --page-size=500
+
+# This form keeps running, and every 5 minutes pushes the model versions which changed since the last push, based on their
+# 'lastUpdateTimeSinceEpoch', to the bridge's location service, and removes the ones which are gone.  What was pushed is
+# kept in a state file, so restarts do not push unchanged model versions again.  Use Ctrl-C to stop it.
+$ %s new-model kubeflow <Owner> <Lifecycle> --poll=5m --bridge-url=http://localhost:9090
+
 # This form will pull in only the RegisteredModels with the specified IDs '1' and '2' and the ModelVersion, ModelArtifact, and InferenceService
 # artifacts that are linked to those RegisteredModels in order to build Catalog Component, Resource, and API Entities.
 $ %s new-model kubeflow <Owner> <Lifecycle> 1 2 
@@ -34,6 +56,12 @@ $ %s new-model kubeflow <Owner> <Lifecycle> 1 2
 )
 
 func NewCmd(cfg *config.Config) *cobra.Command {
+	concurrency := DEFAULT_CONCURRENCY
+	pageSize := DEFAULT_PAGE_SIZE
+	poll := time.Duration(0)
+	bridgeURL := ""
+	bridgeToken := ""
+	stateFile := ""
 	cmd := &cobra.Command{
 		Use:     "kubeflow",
 		Aliases: []string{"kf"},
@@ -44,10 +72,7 @@ func NewCmd(cfg *config.Config) *cobra.Command {
 			ids := []string{}
 
 			if len(args) < 2 {
-				err := fmt.Errorf("need to specify an Owner and Lifecycle setting")
-				klog.Errorf("%s", err.Error())
-				klog.Flush()
-				return err
+				return util.NewUsageError("need to specify an Owner and Lifecycle setting")
 			}
 			owner := args[0]
 			lifecycle := args[1]
@@ -55,44 +80,325 @@ func NewCmd(cfg *config.Config) *cobra.Command {
 			if len(args) > 2 {
 				ids = args[2:]
 			}
+			if concurrency < 1 || pageSize < 1 {
+				return util.NewUsageError("--concurrency and --page-size must be at least 1")
+			}
 
-			kfmr := kubeflowmodelregistry.SetupKubeflowRESTClient(cfg)
-
-			// _, _, err := kubeflowmodelregistry.LoopOverKFMR(owner, lifecycle, ids, cmd.OutOrStdout(), kfmr, nil)
-			rms, mvs, mas, err := kubeflowmodelregistry.LoopOverKFMR(ids, kfmr)
+			if poll < 0 {
+				return util.NewUsageError("--poll cannot be negative")
+			}
+			if poll == 0 && (len(bridgeURL) > 0 || len(bridgeToken) > 0 || len(stateFile) > 0) {
+				return util.NewUsageError("--bridge-url, --bridge-token, and --state-file can only be used with --poll")
+			}
+			if poll > 0 && len(bridgeURL) == 0 {
+				return util.NewUsageError("--poll needs the --bridge-url of the bridge's location service")
+			}
+			if err := catalog.ValidateEntityOptions(&cfg.Entities, poll > 0); err != nil {
+				return err
+			}
+			owners, err := catalog.NewOwners(cmd.Context(), cfg, owner)
+			if err != nil {
+				return err
+			}
+			namer, err := catalog.NewNamer(&cfg.Entities)
 			if err != nil {
-				klog.Errorf("%s", err.Error())
-				klog.Flush()
 				return err
 			}
-			var isl []openapi.InferenceService
-			isl, err = kfmr.ListInferenceServices()
+
+			kfmr := SetupKubeflowRESTClient(cmd.Context(), cfg)
+			if poll > 0 {
+				if len(stateFile) == 0 {
+					stateFile, err = DefaultSyncStatePath(cfg.StoreURL, bridgeURL, ids)
+					if err != nil {
+						return util.NewError(util.ExitError, fmt.Errorf("finding the default --state-file: %w", err))
+					}
+				}
+				bridge := SetupBridgeLocationRESTClient(cmd.Context(), cfg, bridgeURL, bridgeToken)
+				return NewSyncer(cmd.Context(), kfmr, bridge, owners, namer, lifecycle, ids, concurrency, pageSize, stateFile, cmd.ErrOrStderr()).Run(poll)
+			}
+			walker := NewWalker(cmd.Context(), kfmr, concurrency, pageSize)
+			rms, err := walker.Walk(ids)
 			if err != nil {
-				klog.Errorf("%s", err.Error())
-				klog.Flush()
 				return err
 			}
-			for _, rm := range rms {
-				mva, ok := mvs[butil.SanitizeName(rm.Name)]
-				if !ok {
-					klog.Errorf("could not find the model versions for registered model %s with sanitized name %s", rm.Name, butil.SanitizeName(rm.Name))
-					continue
+			if cfg.Entities.Systems {
+				err = PrintSystems(owners, &cfg.Entities, rms, walker, cmd.OutOrStdout())
+				if err != nil {
+					return err
 				}
-				maa, ok2 := mas[butil.SanitizeName(rm.Name)]
-				if !ok2 {
-					klog.Errorf("could not find the model artifact array for registered model %s with santizied name %s", rm.Name, butil.SanitizeName(rm.Name))
-					continue
+			}
+			// registered models without versions print nothing, so the separators follow what was printed
+			printed := 0
+			for i, rmw := range rms {
+				rmOwner, err := ModelOwner(owners, &rms[i].RegisteredModel)
+				if err != nil {
+					return err
 				}
-				for _, mv := range mva {
-					for _, i := range isl {
-						err = kubeflowmodelregistry.CallBackstagePrinters(cmd.Context(), owner, lifecycle, &rm, &mv, maa[mv.GetId()], &i, nil, kfmr, nil, cmd.OutOrStdout(), types.CatalogInfoYamlFormat)
+				for j := range rmw.Versions {
+					if printed > 0 {
+						catalog.PrintDocumentSeparator(cmd.OutOrStdout())
+					}
+					printed++
+					err = PrintModelVersion(cmd.Context(), rmOwner, lifecycle, cfg.Entities.Systems, namer, &rms[i], &rmw.Versions[j], kfmr, walker, cmd.OutOrStdout())
+					if err != nil {
+						return err
 					}
 				}
 			}
-			return err
+			return nil
 
 		},
 	}
 
+	cmd.Flags().IntVar(&concurrency, "concurrency", DEFAULT_CONCURRENCY,
+		"The maximum number of requests to the Kubeflow Model Registry in flight at once.")
+	cmd.Flags().IntVar(&pageSize, "page-size", DEFAULT_PAGE_SIZE,
+		"The number of items requested per page when listing registered models, model versions, model artifacts, and inference services.")
+	cmd.Flags().DurationVar(&poll, "poll", 0,
+		"Keep running, and every interval, like 5m, push the model versions which changed to the bridge, and remove the ones which are gone.")
+	cmd.Flags().StringVar(&bridgeURL, "bridge-url", "",
+		"With --poll, the URL of the bridge's location service the model versions are pushed to.")
+	cmd.Flags().StringVar(&bridgeToken, "bridge-token", "",
+		"With --poll, the bearer token sent to the bridge's location service.")
+	cmd.Flags().StringVar(&stateFile, "state-file", "",
+		"With --poll, the file recording what was pushed to the bridge, so a restart only pushes what changed; defaults to a file per registry and bridge under the user's config directory.")
+	cmd.Flags().StringVar(&(cfg.Entities.OwnerProperty), "owner-property", cfg.Entities.OwnerProperty,
+		"The custom property of each registered model whose string value, when set, is the owner of its entities instead of <Owner>, like 'Owner'; the kind of the owner defaults to group.")
+
 	return cmd
 }
+
+// PrintSystems prints the Domain of opts, when set, and a System for each serving environment the model versions of rms
+// are deployed to, in the Backstage namespace of opts.  Each System is owned by the owner ModelOwner gives the
+// registered models deployed to it, when they all have the same one, and the Domain, like the Systems of registered
+// models with different owners, by the default owner.
+func PrintSystems(owners *catalog.Owners, opts *config.EntityOptions, rms []RegisteredModelWalk, walker *Walker, writer io.Writer) error {
+	if len(opts.Domain) > 0 {
+		err := catalog.PrintDomain(opts.Domain, opts.Namespace, owners.Default, writer)
+		if err != nil {
+			return err
+		}
+	}
+	systems := map[string]*openapi.ServingEnvironment{}
+	systemOwners := map[string]string{}
+	for i := range rms {
+		rmOwner, err := ModelOwner(owners, &rms[i].RegisteredModel)
+		if err != nil {
+			return err
+		}
+		for _, mvw := range rms[i].Versions {
+			for _, is := range mvw.InferenceServices {
+				se, err := walker.ServingEnvironment(is.ServingEnvironmentId)
+				if err != nil {
+					return err
+				}
+				name := SystemName(se)
+				systems[name] = se
+				owner, ok := systemOwners[name]
+				switch {
+				case !ok:
+					systemOwners[name] = rmOwner
+				case owner != rmOwner:
+					systemOwners[name] = owners.Default
+				}
+			}
+		}
+	}
+	names := make([]string, 0, len(systems))
+	for name := range systems {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		description := systems[name].GetDescription()
+		if len(description) == 0 {
+			description = "The models deployed to the " + systems[name].GetName() + " serving environment of the Kubeflow Model Registry"
+		}
+		err := catalog.PrintSystem(name, opts.Namespace, description, systemOwners[name], opts.Domain, writer)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ModelOwner returns the owner of the entities of the versions of rm, which the string value of its --owner-property
+// custom property names when set
+func ModelOwner(owners *catalog.Owners, rm *openapi.RegisteredModel) (string, error) {
+	value := ""
+	if len(owners.Property()) > 0 {
+		if v, ok := rm.GetCustomProperties()[owners.Property()]; ok && v.MetadataStringValue != nil {
+			value = v.MetadataStringValue.GetStringValue()
+		}
+	}
+	return owners.Resolve(value)
+}
+
+// SystemName returns the name of the System of the models deployed to se
+func SystemName(se *openapi.ServingEnvironment) string {
+	return brdgutil.SanitizeName(se.GetName())
+}
+
+// PrintModelVersion prints the entities of a model version from a Walk, once for each of its inference services, or
+// once without the links of an inference service when it is not deployed.  When systems is set, the entities of each
+// inference service are part of the System of its serving environment.  The entities are named by namer.
+func PrintModelVersion(ctx context.Context, owner, lifecycle string, systems bool, namer *catalog.Namer, rmw *RegisteredModelWalk, mvw *ModelVersionWalk, kfmr *kubeflowmodelregistry.KubeFlowRESTClientWrapper, walker *Walker, writer io.Writer) error {
+	for i, is := range inferenceServices(mvw) {
+		if i > 0 {
+			catalog.PrintDocumentSeparator(writer)
+		}
+		system := ""
+		if systems && is != nil {
+			se, err := walker.ServingEnvironment(is.ServingEnvironmentId)
+			if err != nil {
+				return err
+			}
+			system = SystemName(se)
+		}
+		err := CallBackstagePrinters(ctx, owner, lifecycle, system, namer, &rmw.RegisteredModel, &mvw.ModelVersion, mvw.Artifacts, is, kfmr, walker, writer)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inferenceServices returns the inference services of mvw, or a nil one when it is not deployed
+func inferenceServices(mvw *ModelVersionWalk) []*openapi.InferenceService {
+	if len(mvw.InferenceServices) == 0 {
+		return []*openapi.InferenceService{nil}
+	}
+	isl := []*openapi.InferenceService{}
+	for i := range mvw.InferenceServices {
+		isl = append(isl, &mvw.InferenceServices[i])
+	}
+	return isl
+}
+
+// CallBackstagePrinters mirrors the catalog-info.yaml format handling of the bridge's
+// kubeflowmodelregistry.CallBackstagePrinters, but prints the API with catalog.PrintAPI so it is labeled as AI related.
+// When walker is provided, the links of the Component and API are built with its cached serving environments and KServe
+// InferenceServices.  The entities are named by namer, and are part of system when it is set.
+func CallBackstagePrinters(ctx context.Context, owner, lifecycle, system string, namer *catalog.Namer, rm *openapi.RegisteredModel, mv *openapi.ModelVersion, mas []openapi.ModelArtifact, is *openapi.InferenceService, kfmr *kubeflowmodelregistry.KubeFlowRESTClientWrapper, walker *Walker, writer io.Writer) error {
+	compPop := kubeflowmodelregistry.ComponentPopulator{}
+	compPop.Owner = owner
+	compPop.Lifecycle = lifecycle
+	compPop.Kfmr = kfmr
+	compPop.RegisteredModel = rm
+	compPop.ModelVersion = mv
+	compPop.ModelArtifacts = mas
+	compPop.InferenceService = is
+	compPop.Ctx = ctx
+	var compPrinter backstage.ComponentPopulator = &compPop
+	if walker != nil {
+		compPrinter = &componentPopulator{ComponentPopulator: &compPop, walker: walker}
+	}
+
+	resPop := kubeflowmodelregistry.ResourcePopulator{}
+	resPop.Owner = owner
+	resPop.Lifecycle = lifecycle
+	resPop.Kfmr = kfmr
+	resPop.RegisteredModel = rm
+	resPop.CommonPopulator.ModelVersion = mv
+	resPop.ModelVersion = mv
+	resPop.ModelArtifacts = mas
+	resPop.Ctx = ctx
+
+	apiPop := kubeflowmodelregistry.ApiPopulator{}
+	apiPop.Owner = owner
+	apiPop.Lifecycle = lifecycle
+	apiPop.Kfmr = kfmr
+	apiPop.RegisteredModel = rm
+	apiPop.ModelVersion = mv
+	apiPop.InferenceService = is
+	apiPop.Ctx = ctx
+	var apiPrinter backstage.APIPopulator = &apiPop
+	if walker != nil {
+		apiPrinter = &apiPopulator{ApiPopulator: &apiPop, walker: walker}
+	}
+
+	me, err := NameModel(namer, rm, mv, is, walker, catalog.ModelEntities{System: system, Component: compPop.GetName(), Resource: resPop.GetName(), API: apiPop.GetName()})
+	if err != nil {
+		return err
+	}
+	err = catalog.PrintComponent(catalog.ComponentAs(compPrinter, &me), writer)
+	if err != nil {
+		return err
+	}
+	err = catalog.PrintResource(catalog.ResourceAs(&resPop, &me), writer)
+	if err != nil {
+		return err
+	}
+	return catalog.PrintAPI(catalog.APIAs(apiPrinter, &me), writer)
+}
+
+// NameModel names the entities of mv of rm, as deployed by is when set, with namer.  The model of a name is the
+// inference service, but, as with the bridge, the versions of a registered model share its Component and API, and the
+// inference services of a model version share its Resource, so those are keyed by the registered model and the model
+// version.  defaults are the names given by the populators of the bridge.
+func NameModel(namer *catalog.Namer, rm *openapi.RegisteredModel, mv *openapi.ModelVersion, is *openapi.InferenceService, walker *Walker, defaults catalog.ModelEntities) (catalog.ModelEntities, error) {
+	model := rm.GetId() + "/" + mv.GetId()
+	shared := catalog.SharedKeys{Component: rm.GetId(), Resource: model, API: rm.GetId()}
+	data := catalog.EntityNameData{Name: rm.GetName(), Version: mv.GetName()}
+	if is != nil {
+		model += "/" + is.GetId()
+		if walker != nil {
+			se, err := walker.ServingEnvironment(is.ServingEnvironmentId)
+			if err != nil {
+				return defaults, err
+			}
+			data.Namespace = se.GetName()
+		}
+	}
+	return namer.NameShared(model, shared, data, defaults)
+}
+
+// NameModelVersion names the entities of a model version from a Walk, for each of its inference services, like
+// PrintModelVersion does, but without printing them, so their names are kept from other models
+func NameModelVersion(namer *catalog.Namer, rmw *RegisteredModelWalk, mvw *ModelVersionWalk, walker *Walker) error {
+	compPop := kubeflowmodelregistry.ComponentPopulator{}
+	compPop.RegisteredModel = &rmw.RegisteredModel
+	resPop := kubeflowmodelregistry.ResourcePopulator{}
+	resPop.ModelVersion = &mvw.ModelVersion
+	apiPop := kubeflowmodelregistry.ApiPopulator{}
+	apiPop.RegisteredModel = &rmw.RegisteredModel
+	defaults := catalog.ModelEntities{Component: compPop.GetName(), Resource: resPop.GetName(), API: apiPop.GetName()}
+	for _, is := range inferenceServices(mvw) {
+		_, err := NameModel(namer, &rmw.RegisteredModel, &mvw.ModelVersion, is, walker, defaults)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// componentPopulator and apiPopulator replace the links of the bridge's populators, which fetch the serving environment
+// and KServe InferenceService each time, with ones built from the Walker's caches
+type componentPopulator struct {
+	*kubeflowmodelregistry.ComponentPopulator
+	walker *Walker
+}
+
+func (pop *componentPopulator) GetLinks() []backstage.EntityLink {
+	links := pop.walker.linksFromInferenceServices(&pop.CommonPopulator)
+	for _, ma := range pop.ModelArtifacts {
+		if ma.Uri != nil {
+			links = append(links, backstage.EntityLink{
+				URL:   *ma.Uri,
+				Title: ma.GetDescription(),
+				Icon:  backstage.LINK_ICON_WEBASSET,
+				Type:  backstage.LINK_TYPE_WEBSITE,
+			})
+		}
+	}
+	return links
+}
+
+type apiPopulator struct {
+	*kubeflowmodelregistry.ApiPopulator
+	walker *Walker
+}
+
+func (pop *apiPopulator) GetLinks() []backstage.EntityLink {
+	return pop.walker.linksFromInferenceServices(&pop.CommonPopulator)
+}
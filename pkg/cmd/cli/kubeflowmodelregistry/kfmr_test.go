@@ -1,13 +1,21 @@
 package kubeflowmodelregistry
 
 import (
-	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/config"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
 	cobra2 "github.com/redhat-ai-dev/model-catalog-bridge/test/cobra"
 	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
 	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/kfmr"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
 	"github.com/spf13/cobra"
-	"strings"
-	"testing"
 )
 
 func TestNewCmd(t *testing.T) {
@@ -18,8 +26,8 @@ func TestNewCmd(t *testing.T) {
 		generatesError bool
 		generatesHelp  bool
 		errorStr       string
-		// we do output compare in chunks as ranges over the components status map are non-deterministic wrt order
-		outStr []string
+		// the output is compared byte for byte, as the lists in the entities, and the entities themselves, are sorted
+		outStr string
 	}{
 		{
 			args:          []string{"--help"},
@@ -35,17 +43,27 @@ func TestNewCmd(t *testing.T) {
 			generatesError: true,
 			errorStr:       "need to specify an Owner and Lifecycle setting",
 		},
+		{
+			args:           []string{"Owner", "Lifecycle", "--poll=5m"},
+			generatesError: true,
+			errorStr:       "--poll needs the --bridge-url of the bridge's location service",
+		},
+		{
+			args:           []string{"Owner", "Lifecycle", "--bridge-url=http://localhost:9090"},
+			generatesError: true,
+			errorStr:       "--bridge-url, --bridge-token, and --state-file can only be used with --poll",
+		},
 		{
 			args:   []string{"Owner", "Lifecycle"},
-			outStr: []string{listOutput},
+			outStr: listOutput,
 		},
 		{
 			args:   []string{"Owner", "Lifecycle", "1"},
-			outStr: []string{listOutput},
+			outStr: listOutput,
 		},
 	} {
-		cfg := &config.Config{}
-		kfmr.SetupKubeflowTestRESTClient(ts, cfg)
+		cfg := config.NewConfig()
+		kfmr.SetupKubeflowTestRESTClient(ts, cfg.Config)
 		cmd := NewCmd(cfg)
 		subCmd, stdout, stderr, err := cobra2.ExecuteCommandC(cmd, tc.args...)
 		switch {
@@ -57,13 +75,83 @@ func TestNewCmd(t *testing.T) {
 			t.Errorf("unexpected error output for '%s'- got '%s' but expected '%s'", strings.Join(tc.args, " "), stderr, tc.errorStr)
 		case tc.generatesHelp && !testHelpOK(stdout, subCmd):
 			t.Errorf("unexpected help output for '%s' - got '%s' but expected '%s'", strings.Join(tc.args, " "), stdout, subCmd.Long)
-		case err == nil && !tc.generatesError:
-			for _, str := range tc.outStr {
-				common.AssertLineCompare(t, stdout, str, 0)
+		case err == nil && !tc.generatesError && !tc.generatesHelp:
+			common.AssertEqual(t, tc.outStr, stdout)
+		}
+
+	}
+}
+
+func TestNewCmdOutputIsStable(t *testing.T) {
+	ts := kfmr.CreateGetServerWithMixInferenceMultiModel(t)
+	defer ts.Close()
+	var first string
+	for i := 0; i < 5; i++ {
+		cfg := config.NewConfig()
+		kfmr.SetupKubeflowTestRESTClient(ts, cfg.Config)
+		// the version of each registered model is named v1
+		cfg.Entities.OnCollision = catalog.CollisionSuffix
+		_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if i == 0 {
+			first = stdout
+			continue
+		}
+		common.AssertEqual(t, first, stdout)
+	}
+	// the Component, Resource, and API of each of the 3 registered models are separate YAML documents, in name order
+	common.AssertEqual(t, 8, strings.Count(first, "\n---\n"))
+	granite := strings.Index(first, "name: granite-31-8b-lab-v1-140")
+	code := strings.Index(first, "name: granite-8b-code-instruct-140")
+	mnist := strings.Index(first, "name: mnist")
+	if granite < 0 || granite > code || code > mnist {
+		t.Errorf("the registered models were not printed in name order:\n%s", first)
+	}
+}
+
+// createVersionsServer serves the registered models with the IDs of versions, in ID order, each with the model versions
+// of its value, which are named v<ID of the version>
+func createVersionsServer(versions map[string][]string) *httptest.Server {
+	return common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, rest.KFMR_BASE_URI)
+		switch {
+		case path == rest.LIST_REG_MODEL_URI:
+			items := []string{}
+			for _, id := range slices.Sorted(maps.Keys(versions)) {
+				items = append(items, `{"id":"`+id+`","name":"model-`+id+`","state":"LIVE"}`)
+			}
+			_, _ = w.Write([]byte(`{"items":[` + strings.Join(items, ",") + `],"nextPageToken":"","pageSize":0,"size":0}`))
+		case strings.HasSuffix(path, "/versions"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, rest.LIST_REG_MODEL_URI+"/"), "/versions")
+			items := []string{}
+			for _, mvID := range versions[id] {
+				items = append(items, `{"id":"`+mvID+`","name":"v`+mvID+`","registeredModelId":"`+id+`","state":"LIVE"}`)
 			}
+			_, _ = w.Write([]byte(`{"items":[` + strings.Join(items, ",") + `],"nextPageToken":"","pageSize":0,"size":0}`))
+		case strings.HasSuffix(path, "/artifacts"), path == rest.LIST_INFERENCE_SERVICES_URI:
+			_, _ = w.Write([]byte(`{"items":[],"nextPageToken":"","pageSize":0,"size":0}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
 		}
+	})
+}
 
+func TestSeparators(t *testing.T) {
+	// the first and third registered models have no versions, so print nothing
+	ts := createVersionsServer(map[string][]string{"1": {}, "2": {"20"}, "3": {}, "4": {"40"}})
+	defer ts.Close()
+	cfg := config.NewConfig()
+	kfmr.SetupKubeflowTestRESTClient(ts, cfg.Config)
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
 	}
+	common.AssertEqual(t, true, strings.HasPrefix(stdout, "apiVersion: "))
+	common.AssertEqual(t, 5, strings.Count(stdout, "---\n"))
+	common.AssertEqual(t, 6, strings.Count(stdout, "apiVersion: "))
 }
 
 func testHelpOK(stdout string, cmd *cobra.Command) bool {
@@ -90,8 +178,8 @@ metadata:
   - foo-bar
 spec:
   dependsOn:
-  - resource:v1
   - api:model-1-v1-artifact
+  - resource:v1
   lifecycle: Lifecycle
   owner: user:Owner
   profile:
@@ -125,6 +213,8 @@ metadata:
   annotations:
     backstage.io/techdocs-ref: api/
   description: dummy model 1
+  labels:
+    rhdh.modelcatalog.io/api-type: model-service-api
   name: model-1
 spec:
   definition: no-definition-yet
@@ -137,3 +227,135 @@ spec:
   type: unknown
 `
 )
+
+func TestSystems(t *testing.T) {
+	ts := kfmr.CreateGetServerWithMixInferenceMultiModel(t)
+	defer ts.Close()
+	cfg := config.NewConfig()
+	kfmr.SetupKubeflowTestRESTClient(ts, cfg.Config)
+	// the version of each registered model is named v1
+	cfg.Entities = config.EntityOptions{Systems: true, OnCollision: catalog.CollisionSuffix}
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	// the System of the serving environment comes first, and only the entities of the deployed model are part of it
+	common.AssertEqual(t, true, strings.HasPrefix(stdout, `apiVersion: backstage.io/v1alpha1
+kind: System
+metadata:
+  description: The models deployed to the ggmtest serving environment of the Kubeflow
+    Model Registry
+  name: ggmtest
+spec:
+  owner: user:Owner
+---
+`))
+	common.AssertEqual(t, 1, strings.Count(stdout, "kind: System\n"))
+	common.AssertEqual(t, 3, strings.Count(stdout, "\n  system: ggmtest\n"))
+
+	// the System is owned like the deployed model, which is the only one with the _lastModified custom property
+	cfg.Entities.OwnerMap = map[string]string{"2025-04-11T18:17:52.979Z": "group:default/mnist-team"}
+	_, stdout, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--owner-property=_lastModified")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, true, strings.HasPrefix(stdout, `apiVersion: backstage.io/v1alpha1
+kind: System
+metadata:
+  description: The models deployed to the ggmtest serving environment of the Kubeflow
+    Model Registry
+  name: ggmtest
+spec:
+  owner: group:default/mnist-team
+---
+`))
+	common.AssertEqual(t, 4, strings.Count(stdout, "\n  owner: group:default/mnist-team\n"))
+	cfg.Entities.OwnerMap = nil
+
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--poll=5m", "--bridge-url=http://localhost:9090")
+	if err == nil || !strings.Contains(err.Error(), "--systems cannot be used with --watch or --poll") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestOwnerProperty(t *testing.T) {
+	ts := kfmr.CreateGetServer(t)
+	defer ts.Close()
+	cfg := config.NewConfig()
+	kfmr.SetupKubeflowTestRESTClient(ts, cfg.Config)
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "group:default/ml-platform", "Lifecycle")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, 3, strings.Count(stdout, "\n  owner: group:default/ml-platform\n"))
+
+	// model-1 has a 'foo' custom property of 'bar', which is mapped to a Group unless --owner-map says otherwise
+	_, stdout, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--owner-property=foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, 3, strings.Count(stdout, "\n  owner: group:bar\n"))
+	cfg.Entities.OwnerMap = map[string]string{"bar": "user:jdoe"}
+	_, stdout, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle", "--owner-property=foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, 3, strings.Count(stdout, "\n  owner: user:jdoe\n"))
+
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "component:Owner", "Lifecycle")
+	if err == nil || !strings.Contains(err.Error(), "has to be a user or group") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDefaultNameCollisions(t *testing.T) {
+	// the registered models "model-a b" and "model-ab" are both named model-ab once sanitized
+	ts := createVersionsServer(map[string][]string{"a b": {"1"}, "ab": {"2"}})
+	defer ts.Close()
+	cfg := config.NewConfig()
+	kfmr.SetupKubeflowTestRESTClient(ts, cfg.Config)
+	_, _, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle")
+	if util.GetExitCode(err) != int(util.ExitConflict) || !strings.Contains(err.Error(), `a b and ab have the same component name "model-ab"`) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+
+	cfg.Entities.OnCollision = catalog.CollisionSuffix
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	// the Component and API of the second registered model are suffixed, and its Resource is a dependency of its Component
+	common.AssertEqual(t, 2, strings.Count(stdout, "\n  name: model-ab\n"))
+	common.AssertEqual(t, 2, strings.Count(stdout, "\n  name: model-ab-2\n"))
+	common.AssertEqual(t, 2, strings.Count(stdout, "\n  - component:model-ab-2\n"))
+}
+
+func TestNameTemplates(t *testing.T) {
+	// the versions of a registered model share its Component and API, so templating them is not a collision
+	ts := createVersionsServer(map[string][]string{"1": {"10", "11"}, "2": {"20"}})
+	defer ts.Close()
+	cfg := config.NewConfig()
+	kfmr.SetupKubeflowTestRESTClient(ts, cfg.Config)
+	cfg.Entities = config.EntityOptions{NameTemplates: map[string]string{
+		"component": "{{.Name}}-server",
+		"resource":  "{{.Name}}-{{.Version}}",
+		"api":       "{{.Name}}-api",
+	}}
+	_, stdout, _, err := cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, 2, strings.Count(stdout, "\n  name: model-1-server\n"))
+	common.AssertEqual(t, 2, strings.Count(stdout, "\n  name: model-1-api\n"))
+	common.AssertEqual(t, 1, strings.Count(stdout, "\n  name: model-1-v10\n"))
+	common.AssertEqual(t, 1, strings.Count(stdout, "\n  name: model-1-v11\n"))
+	common.AssertEqual(t, 1, strings.Count(stdout, "\n  name: model-2-server\n"))
+	common.AssertEqual(t, 2, strings.Count(stdout, "\n  - resource:model-1-v1"))
+
+	// but the model versions cannot share a Resource
+	cfg.Entities.NameTemplates["resource"] = "{{.Kind}}"
+	_, _, _, err = cobra2.ExecuteCommandC(NewCmd(cfg), "Owner", "Lifecycle")
+	if util.GetExitCode(err) != int(util.ExitConflict) || !strings.Contains(err.Error(), `gives 1/10 and 1/11 the same name "resource"`) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package kubeflowmodelregistry
+
+import (
+	"context"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/kubeflowmodelregistry"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/server/location/client"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+)
+
+// SetupKubeflowRESTClient wraps the bridge's kubeflowmodelregistry.SetupKubeflowRESTClient, replacing its TLS settings,
+// which only allow for skipping TLS, with the CA bundles and client certificates for the CLI when they are provided,
+// and applying the timeout and retry policy of the CLI.  As the bridge makes the requests, ctx is attached to them by
+// middleware, which is only added when the bridge creates a new REST client.
+func SetupKubeflowRESTClient(ctx context.Context, cfg *config.Config) *kubeflowmodelregistry.KubeFlowRESTClientWrapper {
+	created := cfg.KubeflowRESTClient == nil
+	kfmr := kubeflowmodelregistry.SetupKubeflowRESTClient(cfg.Config)
+	if cfg.StoreTLS.TLSConfig != nil {
+		kfmr.RESTClient.SetTLSClientConfig(cfg.StoreTLS.TLSConfig)
+	}
+	cfg.Requests.Apply(kfmr.RESTClient)
+	if created {
+		kfmr.RESTClient.OnBeforeRequest(func(_ *resty.Client, r *resty.Request) error {
+			if r.Context() == context.Background() {
+				r.SetContext(ctx)
+			}
+			return nil
+		})
+	}
+	return kfmr
+}
+
+// SetupBridgeLocationRESTClient wraps the bridge's client.SetupBridgeLocationRESTClient, applying the timeout and retry
+// policy of the CLI, and attaching ctx to the requests, which the bridge makes without one
+func SetupBridgeLocationRESTClient(ctx context.Context, cfg *config.Config, hostURL, token string) *client.BridgeLocationRESTClient {
+	bridge := client.SetupBridgeLocationRESTClient(hostURL, token)
+	cfg.Requests.Apply(bridge.RESTClient)
+	bridge.RESTClient.OnBeforeRequest(func(_ *resty.Client, r *resty.Request) error {
+		if r.Context() == context.Background() {
+			r.SetContext(ctx)
+		}
+		return nil
+	})
+	return bridge
+}
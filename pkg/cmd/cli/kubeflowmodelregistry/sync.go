@@ -0,0 +1,280 @@
+package kubeflowmodelregistry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/kubeflowmodelregistry"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/server/location/client"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/bridge"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+)
+
+// SyncState is what the last sync pushed to the bridge, keyed by import key, so a restarted sync only pushes the
+// model versions which changed in the meantime
+type SyncState struct {
+	Models map[string]SyncedModel `json:"models"`
+}
+
+// SyncedModel is a model version pushed to the bridge
+type SyncedModel struct {
+	RegisteredModelID string `json:"registeredModelId"`
+	ModelVersionID    string `json:"modelVersionId"`
+	// Fingerprint is built from the lastUpdateTimeSinceEpoch of the registered model, model version, and inference
+	// services, along with the settings used to build and name the entities, so any change to them pushes the model
+	// version again
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Syncer periodically walks the registry like the bridge does with its POLLING_INTERVAL, upserting the model versions
+// which changed through the bridge's location service, and removing the ones which are gone
+type Syncer struct {
+	ctx         context.Context
+	kfmr        *kubeflowmodelregistry.KubeFlowRESTClientWrapper
+	bridge      *client.BridgeLocationRESTClient
+	owners      *catalog.Owners
+	namer       *catalog.Namer
+	lifecycle   string
+	ids         []string
+	concurrency int
+	pageSize    int
+	statePath   string
+	// errOut gets a line for each model version upserted or removed, and for each error, none of which stop the sync
+	errOut io.Writer
+
+	state *SyncState
+}
+
+func NewSyncer(ctx context.Context, kfmr *kubeflowmodelregistry.KubeFlowRESTClientWrapper, bridge *client.BridgeLocationRESTClient, owners *catalog.Owners, namer *catalog.Namer, lifecycle string, ids []string, concurrency, pageSize int, statePath string, errOut io.Writer) *Syncer {
+	return &Syncer{
+		ctx:         ctx,
+		kfmr:        kfmr,
+		bridge:      bridge,
+		owners:      owners,
+		namer:       namer,
+		lifecycle:   lifecycle,
+		ids:         ids,
+		concurrency: concurrency,
+		pageSize:    pageSize,
+		statePath:   statePath,
+		errOut:      errOut,
+	}
+}
+
+// DefaultSyncStatePath returns a path, under the 'bac' directory of the user's config directory, per registry, bridge,
+// and set of registered model IDs, so syncs of different registries or models do not remove each other's model versions
+func DefaultSyncStatePath(storeURL, bridgeURL string, ids []string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(storeURL + " " + bridgeURL + " " + strings.Join(ids, ",")))
+	return filepath.Join(dir, util.ApplicationName, "kubeflow-sync-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// Run syncs right away, and then every poll interval, until the context is canceled
+func (s *Syncer) Run(poll time.Duration) error {
+	err := s.load()
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for {
+		s.Sync()
+		select {
+		case <-s.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync walks the registry once, pushing the changes to the bridge and saving the state
+func (s *Syncer) Sync() {
+	if s.state == nil {
+		s.state = &SyncState{Models: map[string]SyncedModel{}}
+	}
+	walker := NewWalker(s.ctx, s.kfmr, s.concurrency, s.pageSize)
+	rms, err := walker.Walk(s.ids)
+	if err != nil {
+		// nothing is removed when the walk fails, as the model versions not seen may well still be there
+		if s.ctx.Err() == nil {
+			fmt.Fprintf(s.errOut, "Error walking the Kubeflow Model Registry: %s\n", err.Error())
+		}
+		return
+	}
+
+	changed := false
+	seen := map[string]bool{}
+	for i := range rms {
+		for j := range rms[i].Versions {
+			rmw, mvw := &rms[i], &rms[i].Versions[j]
+			key, err := bridge.ContentKey(rmw.RegisteredModel.Name, mvw.ModelVersion.Name)
+			if err != nil {
+				fmt.Fprintf(s.errOut, "Error pushing %s:%s: %s\n", rmw.RegisteredModel.Name, mvw.ModelVersion.Name, err.Error())
+				continue
+			}
+			seen[key] = true
+			// the names of every model version seen are claimed anew on each pass, whether or not it changed, so
+			// those of the inference services which are gone are released, and, after a restart, those of the
+			// model versions which are not pushed again are still kept from the other models
+			s.namer.Release(rmw.RegisteredModel.GetId() + "/" + mvw.ModelVersion.GetId())
+			err = NameModelVersion(s.namer, rmw, mvw, walker)
+			if err != nil {
+				fmt.Fprintf(s.errOut, "Error naming %s: %s\n", key, err.Error())
+				continue
+			}
+			owner, err := ModelOwner(s.owners, &rmw.RegisteredModel)
+			if err != nil {
+				fmt.Fprintf(s.errOut, "Error finding the owner of %s: %s\n", key, err.Error())
+				continue
+			}
+			fingerprint := s.fingerprint(owner, rmw, mvw)
+			if s.state.Models[key].Fingerprint == fingerprint {
+				continue
+			}
+			err = s.upsert(key, owner, rmw, mvw, walker)
+			if err != nil {
+				fmt.Fprintf(s.errOut, "Error upserting %s: %s\n", key, err.Error())
+				continue
+			}
+			s.state.Models[key] = SyncedModel{
+				RegisteredModelID: rmw.RegisteredModel.GetId(),
+				ModelVersionID:    mvw.ModelVersion.GetId(),
+				Fingerprint:       fingerprint,
+			}
+			changed = true
+			fmt.Fprintf(s.errOut, "Upserted %s\n", key)
+		}
+	}
+
+	keys := make([]string, 0, len(s.state.Models))
+	for key := range s.state.Models {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		err = s.remove(key)
+		if err != nil {
+			fmt.Fprintf(s.errOut, "Error removing %s: %s\n", key, err.Error())
+			continue
+		}
+		s.namer.Release(s.state.Models[key].RegisteredModelID + "/" + s.state.Models[key].ModelVersionID)
+		delete(s.state.Models, key)
+		changed = true
+		fmt.Fprintf(s.errOut, "Removed %s\n", key)
+	}
+
+	if changed {
+		err = s.save()
+		if err != nil {
+			fmt.Fprintf(s.errOut, "Error saving the sync state to %s: %s\n", s.statePath, err.Error())
+		}
+	}
+}
+
+func (s *Syncer) fingerprint(owner string, rmw *RegisteredModelWalk, mvw *ModelVersionWalk) string {
+	parts := []string{owner, s.lifecycle, s.namer.Settings(), rmw.RegisteredModel.GetLastUpdateTimeSinceEpoch(), mvw.ModelVersion.GetLastUpdateTimeSinceEpoch()}
+	for _, is := range mvw.InferenceServices {
+		parts = append(parts, is.GetId()+":"+is.GetLastUpdateTimeSinceEpoch())
+	}
+	return strings.Join(parts, "|")
+}
+
+// lastUpdate returns the latest lastUpdateTimeSinceEpoch of the registered model, model version, and inference services
+func lastUpdate(rmw *RegisteredModelWalk, mvw *ModelVersionWalk) string {
+	times := []string{rmw.RegisteredModel.GetLastUpdateTimeSinceEpoch(), mvw.ModelVersion.GetLastUpdateTimeSinceEpoch()}
+	for _, is := range mvw.InferenceServices {
+		times = append(times, is.GetLastUpdateTimeSinceEpoch())
+	}
+	var latest int64
+	for _, t := range times {
+		if epoch, err := strconv.ParseInt(t, 10, 64); err == nil && epoch > latest {
+			latest = epoch
+		}
+	}
+	return strconv.FormatInt(latest, 10)
+}
+
+func (s *Syncer) upsert(key, owner string, rmw *RegisteredModelWalk, mvw *ModelVersionWalk, walker *Walker) error {
+	buf := &bytes.Buffer{}
+	err := PrintModelVersion(s.ctx, owner, s.lifecycle, false, s.namer, rmw, mvw, s.kfmr, walker, buf)
+	if err != nil {
+		return err
+	}
+	rc, _, err := s.bridge.UpsertModel(key, &rest.PostBody{Body: buf.Bytes(), LastUpdateTimeSinceEpoch: lastUpdate(rmw, mvw)})
+	if err != nil {
+		return util.NewTransportError(err)
+	}
+	if rc < http.StatusOK || rc >= http.StatusMultipleChoices {
+		return util.NewHTTPError(rc, fmt.Errorf("the bridge returned status code %d", rc))
+	}
+	return nil
+}
+
+func (s *Syncer) remove(key string) error {
+	rc, _, err := s.bridge.RemoveModel(key)
+	if err != nil {
+		return util.NewTransportError(err)
+	}
+	// a model version the bridge no longer has is as good as removed
+	if rc == http.StatusNotFound || (rc >= http.StatusOK && rc < http.StatusMultipleChoices) {
+		return nil
+	}
+	return util.NewHTTPError(rc, fmt.Errorf("the bridge returned status code %d", rc))
+}
+
+func (s *Syncer) load() error {
+	s.state = &SyncState{Models: map[string]SyncedModel{}}
+	buf, err := os.ReadFile(s.statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return util.NewError(util.ExitError, fmt.Errorf("reading the sync state %s: %w", s.statePath, err))
+	}
+	err = json.Unmarshal(buf, s.state)
+	if err != nil {
+		return util.NewValidationError("invalid sync state %s; remove it to push every model version again: %s", s.statePath, err.Error())
+	}
+	if s.state.Models == nil {
+		s.state.Models = map[string]SyncedModel{}
+	}
+	return nil
+}
+
+// save writes the state to a temporary file first, so a sync stopped while saving does not leave a truncated state
+func (s *Syncer) save() error {
+	err := os.MkdirAll(filepath.Dir(s.statePath), 0700)
+	if err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(s.state, "", "    ")
+	if err != nil {
+		return err
+	}
+	tmp := s.statePath + ".tmp"
+	err = os.WriteFile(tmp, buf, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.statePath)
+}
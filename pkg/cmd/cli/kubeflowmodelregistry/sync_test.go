@@ -0,0 +1,211 @@
+package kubeflowmodelregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	brdgrest "github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/kfmr"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+)
+
+func TestSyncer(t *testing.T) {
+	lock := sync.Mutex{}
+	// the registered models in the registry, by ID, with their lastUpdateTimeSinceEpoch
+	models := map[string]string{"1": "1000", "2": "2000"}
+	// what the bridge was sent, and whether it fails the upserts
+	upserts := map[string]brdgrest.PostBody{}
+	removes := []string{}
+	failUpserts := false
+	ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, brdgrest.KFMR_BASE_URI)
+		switch {
+		case r.URL.Path == util.UpsertURI:
+			if failUpserts {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			body := brdgrest.PostBody{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			upserts[r.URL.Query().Get(util.KeyQueryParam)] = body
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == util.RemoveURI:
+			removes = append(removes, r.URL.Query().Get(util.KeyQueryParam))
+		case path == brdgrest.LIST_REG_MODEL_URI:
+			items := []string{}
+			for _, id := range []string{"1", "2"} {
+				if lastUpdate, ok := models[id]; ok {
+					items = append(items, `{"id":"`+id+`","name":"model-`+id+`","state":"LIVE","lastUpdateTimeSinceEpoch":"`+lastUpdate+`"}`)
+				}
+			}
+			_, _ = w.Write([]byte(`{"items":[` + strings.Join(items, ",") + `],"nextPageToken":"","pageSize":0,"size":0}`))
+		case strings.HasSuffix(path, "/versions"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, brdgrest.LIST_REG_MODEL_URI+"/"), "/versions")
+			_, _ = w.Write([]byte(`{"items":[{"id":"` + id + `0","name":"v1","registeredModelId":"` + id + `","state":"LIVE","lastUpdateTimeSinceEpoch":"1500"}],"nextPageToken":"","pageSize":0,"size":1}`))
+		case strings.HasSuffix(path, "/artifacts"), path == brdgrest.LIST_INFERENCE_SERVICES_URI:
+			_, _ = w.Write([]byte(`{"items":[],"nextPageToken":"","pageSize":0,"size":0}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+
+	cfg := config.NewConfig()
+	cfg.Requests.Retries = 0
+	kfmr.SetupKubeflowTestRESTClient(ts, cfg.Config)
+	// the version of each registered model is named v1
+	cfg.Entities.OnCollision = catalog.CollisionSuffix
+	client := SetupKubeflowRESTClient(context.Background(), cfg)
+	bridge := SetupBridgeLocationRESTClient(context.Background(), cfg, ts.URL, "bridge-token")
+	owners, err := catalog.NewOwners(context.Background(), cfg, "Owner")
+	common.AssertError(t, err)
+	namer, err := catalog.NewNamer(&cfg.Entities)
+	common.AssertError(t, err)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	newSyncer := func(out *strings.Builder) *Syncer {
+		s := NewSyncer(context.Background(), client, bridge, owners, namer, "Lifecycle", nil, 2, 10, statePath, out)
+		if err := s.load(); err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		return s
+	}
+
+	// the first sync pushes every model version, with the latest of the lastUpdateTimeSinceEpoch
+	out := &strings.Builder{}
+	newSyncer(out).Sync()
+	common.AssertEqual(t, "Upserted model-1_v1\nUpserted model-2_v1\n", out.String())
+	common.AssertEqual(t, "1500", upserts["model-1_v1"].LastUpdateTimeSinceEpoch)
+	common.AssertEqual(t, "2000", upserts["model-2_v1"].LastUpdateTimeSinceEpoch)
+	if !strings.Contains(string(upserts["model-1_v1"].Body), "name: model-1") {
+		t.Errorf("unexpected upsert body: %s", string(upserts["model-1_v1"].Body))
+	}
+
+	// a restarted sync does not push the unchanged model versions again
+	upserts = map[string]brdgrest.PostBody{}
+	out = &strings.Builder{}
+	s := newSyncer(out)
+	s.Sync()
+	common.AssertEqual(t, "", out.String())
+	common.AssertEqual(t, 0, len(upserts))
+
+	// a changed model is pushed again and a deleted one is removed
+	lock.Lock()
+	models["2"] = "3000"
+	delete(models, "1")
+	lock.Unlock()
+	out.Reset()
+	s.Sync()
+	common.AssertEqual(t, "Upserted model-2_v1\nRemoved model-1_v1\n", out.String())
+	common.AssertEqual(t, "3000", upserts["model-2_v1"].LastUpdateTimeSinceEpoch)
+	common.AssertEqual(t, []string{"model-1_v1"}, removes)
+
+	// a failed push is not recorded, so it is tried again by the next sync
+	lock.Lock()
+	models["2"] = "4000"
+	failUpserts = true
+	lock.Unlock()
+	out.Reset()
+	s.Sync()
+	if !strings.HasPrefix(out.String(), "Error upserting model-2_v1: ") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+	lock.Lock()
+	failUpserts = false
+	lock.Unlock()
+	out = &strings.Builder{}
+	newSyncer(out).Sync()
+	common.AssertEqual(t, "Upserted model-2_v1\n", out.String())
+	common.AssertEqual(t, "4000", upserts["model-2_v1"].LastUpdateTimeSinceEpoch)
+}
+
+func TestSyncerNames(t *testing.T) {
+	lock := sync.Mutex{}
+	// the registered models in the registry, by ID, each with a v1 model version
+	models := []string{"1"}
+	upserts := map[string]string{}
+	ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, brdgrest.KFMR_BASE_URI)
+		switch {
+		case r.URL.Path == util.UpsertURI:
+			body := brdgrest.PostBody{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			upserts[r.URL.Query().Get(util.KeyQueryParam)] = string(body.Body)
+			w.WriteHeader(http.StatusCreated)
+		case path == brdgrest.LIST_REG_MODEL_URI:
+			items := []string{}
+			for _, id := range models {
+				items = append(items, `{"id":"`+id+`","name":"model-`+id+`","state":"LIVE","lastUpdateTimeSinceEpoch":"1000"}`)
+			}
+			_, _ = w.Write([]byte(`{"items":[` + strings.Join(items, ",") + `],"nextPageToken":"","pageSize":0,"size":0}`))
+		case strings.HasSuffix(path, "/versions"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, brdgrest.LIST_REG_MODEL_URI+"/"), "/versions")
+			_, _ = w.Write([]byte(`{"items":[{"id":"` + id + `0","name":"v1","registeredModelId":"` + id + `","state":"LIVE","lastUpdateTimeSinceEpoch":"1500"}],"nextPageToken":"","pageSize":0,"size":1}`))
+		case strings.HasSuffix(path, "/artifacts"), path == brdgrest.LIST_INFERENCE_SERVICES_URI:
+			_, _ = w.Write([]byte(`{"items":[],"nextPageToken":"","pageSize":0,"size":0}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+
+	cfg := config.NewConfig()
+	cfg.Requests.Retries = 0
+	kfmr.SetupKubeflowTestRESTClient(ts, cfg.Config)
+	client := SetupKubeflowRESTClient(context.Background(), cfg)
+	bridge := SetupBridgeLocationRESTClient(context.Background(), cfg, ts.URL, "bridge-token")
+	owners, err := catalog.NewOwners(context.Background(), cfg, "Owner")
+	common.AssertError(t, err)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	// each syncer has a namer of its own, as a restarted sync does
+	newSyncer := func(out *strings.Builder, templates map[string]string) *Syncer {
+		namer, err := catalog.NewNamer(&config.EntityOptions{NameTemplates: templates})
+		common.AssertError(t, err)
+		s := NewSyncer(context.Background(), client, bridge, owners, namer, "Lifecycle", nil, 2, 10, statePath, out)
+		if err := s.load(); err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		return s
+	}
+	byVersion := map[string]string{"resource": "model-{{.Version}}"}
+
+	out := &strings.Builder{}
+	newSyncer(out, byVersion).Sync()
+	common.AssertEqual(t, "Upserted model-1_v1\n", out.String())
+	if !strings.Contains(upserts["model-1_v1"], "name: model-v1") {
+		t.Errorf("unexpected upsert body: %s", upserts["model-1_v1"])
+	}
+
+	// after a restart, the unchanged model version is not pushed again, but its names are still kept from a new one
+	lock.Lock()
+	models = append(models, "2")
+	lock.Unlock()
+	out.Reset()
+	newSyncer(out, byVersion).Sync()
+	if !strings.HasPrefix(out.String(), "Error naming model-2_v1: the --name-template for resource gives 1/10 and 2/20 the same name \"model-v1\"") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+	_, ok := upserts["model-2_v1"]
+	common.AssertEqual(t, false, ok)
+
+	// a change to the naming settings pushes every model version again
+	out.Reset()
+	newSyncer(out, map[string]string{"resource": "{{.Name}}-{{.Version}}"}).Sync()
+	common.AssertEqual(t, "Upserted model-1_v1\nUpserted model-2_v1\n", out.String())
+	if !strings.Contains(upserts["model-2_v1"], "name: model-2-v1") {
+		t.Errorf("unexpected upsert body: %s", upserts["model-2_v1"])
+	}
+}
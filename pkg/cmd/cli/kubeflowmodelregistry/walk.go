@@ -0,0 +1,339 @@
+package kubeflowmodelregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	serverv1beta1 "github.com/kserve/kserve/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/model-registry/pkg/openapi"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
+	brdgkserve "github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/kserve"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/kubeflowmodelregistry"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	butil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DEFAULT_CONCURRENCY = 4
+	DEFAULT_PAGE_SIZE   = 100
+)
+
+// RegisteredModelWalk is a registered model with its model versions, in the order the registry lists them
+type RegisteredModelWalk struct {
+	RegisteredModel openapi.RegisteredModel
+	Versions        []ModelVersionWalk
+}
+
+// ModelVersionWalk is a model version with its model artifacts and the Kubeflow inference services deploying it
+type ModelVersionWalk struct {
+	ModelVersion      openapi.ModelVersion
+	Artifacts         []openapi.ModelArtifact
+	InferenceServices []openapi.InferenceService
+}
+
+// Walker replaces the bridge's LoopOverKFMR, which fetches the registered models, model versions, and model artifacts
+// one at a time, and lists the inference services again for each model version.  Walker pages through the lists of
+// the registry, fetches with up to Concurrency requests in flight, and lists the inference services once.  It also
+// caches the serving environments and KServe InferenceServices looked up while building the links of the entities, so
+// a Walker is meant for a single run.
+type Walker struct {
+	kfmr *kubeflowmodelregistry.KubeFlowRESTClientWrapper
+	ctx  context.Context
+	// Concurrency is the maximum number of requests to the registry in flight
+	Concurrency int
+	// PageSize is the number of items requested per page of a list
+	PageSize int
+
+	lock        sync.Mutex
+	servingEnvs map[string]*openapi.ServingEnvironment
+	kisByName   map[types.NamespacedName]*serverv1beta1.InferenceService
+	kisList     []serverv1beta1.InferenceService
+	kisListed   bool
+}
+
+func NewWalker(ctx context.Context, kfmr *kubeflowmodelregistry.KubeFlowRESTClientWrapper, concurrency, pageSize int) *Walker {
+	return &Walker{
+		kfmr:        kfmr,
+		ctx:         ctx,
+		Concurrency: concurrency,
+		PageSize:    pageSize,
+		servingEnvs: map[string]*openapi.ServingEnvironment{},
+		kisByName:   map[types.NamespacedName]*serverv1beta1.InferenceService{},
+	}
+}
+
+// listPage is the shape shared by the list responses of the registry
+type listPage[T any] struct {
+	Items         []T    `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// Walk fetches the registered models with the given IDs, or all of them when no IDs are given, along with their model
+// versions, model artifacts, and inference services.  Archived registered models and model versions are skipped, as
+// with the bridge.  The result is in the order of ids, or, when listing all of them, sorted by registered model name,
+// with the model versions sorted by name and the inference services by ID, regardless of the order the requests
+// complete in.
+func (w *Walker) Walk(ids []string) ([]RegisteredModelWalk, error) {
+	rms, err := w.registeredModels(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		slices.SortStableFunc(rms, func(a, b openapi.RegisteredModel) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+	walks := make([]RegisteredModelWalk, 0, len(rms))
+	for _, rm := range rms {
+		if rm.State != nil && *rm.State == openapi.REGISTEREDMODELSTATE_ARCHIVED {
+			klog.V(4).Infof("Walk skipping archived registered model %s", rm.Name)
+			continue
+		}
+		walks = append(walks, RegisteredModelWalk{RegisteredModel: rm})
+	}
+
+	// each stage runs in its own group, as a task that added tasks to a group with a limit could block forever
+	g, ctx := w.group()
+	var isl []openapi.InferenceService
+	g.Go(func() error {
+		var listErr error
+		isl, listErr = list[openapi.InferenceService](ctx, w, rest.LIST_INFERENCE_SERVICES_URI)
+		return listErr
+	})
+	for i := range walks {
+		g.Go(func() error {
+			rmID := walks[i].RegisteredModel.GetId()
+			mvs, listErr := list[openapi.ModelVersion](ctx, w, fmt.Sprintf(rest.LIST_VERSIONS_OFF_REG_MODELS_URI, rmID))
+			if listErr != nil {
+				return listErr
+			}
+			for _, mv := range mvs {
+				if mv.State != nil && *mv.State == openapi.MODELVERSIONSTATE_ARCHIVED {
+					klog.V(4).Infof("Walk skipping archived model version %s", mv.Name)
+					continue
+				}
+				walks[i].Versions = append(walks[i].Versions, ModelVersionWalk{ModelVersion: mv})
+			}
+			slices.SortStableFunc(walks[i].Versions, func(a, b ModelVersionWalk) int {
+				return strings.Compare(a.ModelVersion.Name, b.ModelVersion.Name)
+			})
+			return nil
+		})
+	}
+	err = g.Wait()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(isl, func(a, b openapi.InferenceService) int {
+		return strings.Compare(a.GetId(), b.GetId())
+	})
+
+	g, ctx = w.group()
+	for i := range walks {
+		rmID := walks[i].RegisteredModel.GetId()
+		for j := range walks[i].Versions {
+			mvw := &walks[i].Versions[j]
+			g.Go(func() error {
+				mas, listErr := list[openapi.ModelArtifact](ctx, w, fmt.Sprintf(rest.LIST_ARTFIACTS_OFF_VERSIONS_URI, mvw.ModelVersion.GetId()))
+				if listErr != nil {
+					return listErr
+				}
+				// like the bridge, fall back to the artifacts listed with the ID of the registered model
+				if len(mas) == 0 {
+					mas, listErr = list[openapi.ModelArtifact](ctx, w, fmt.Sprintf(rest.LIST_ARTFIACTS_OFF_VERSIONS_URI, rmID))
+					if listErr != nil {
+						return listErr
+					}
+				}
+				mvw.Artifacts = mas
+				return nil
+			})
+			// only include inference services that correspond to this model version
+			for _, is := range isl {
+				if is.ModelVersionId != nil && is.GetModelVersionId() == mvw.ModelVersion.GetId() {
+					mvw.InferenceServices = append(mvw.InferenceServices, is)
+				}
+			}
+		}
+	}
+	err = g.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return walks, nil
+}
+
+func (w *Walker) registeredModels(ids []string) ([]openapi.RegisteredModel, error) {
+	if len(ids) == 0 {
+		return list[openapi.RegisteredModel](w.ctx, w, rest.LIST_REG_MODEL_URI)
+	}
+	rms := make([]openapi.RegisteredModel, len(ids))
+	g, ctx := w.group()
+	for i, id := range ids {
+		g.Go(func() error {
+			return w.get(ctx, fmt.Sprintf(rest.GET_REG_MODEL_URI, id), nil, &rms[i])
+		})
+	}
+	return rms, g.Wait()
+}
+
+func (w *Walker) group() (*errgroup.Group, context.Context) {
+	g, ctx := errgroup.WithContext(w.ctx)
+	if w.Concurrency > 0 {
+		g.SetLimit(w.Concurrency)
+	}
+	return g, ctx
+}
+
+// list pages through the registry list at uri, following the nextPageToken of each page
+func list[T any](ctx context.Context, w *Walker, uri string) ([]T, error) {
+	items := []T{}
+	params := map[string]string{}
+	if w.PageSize > 0 {
+		params["pageSize"] = strconv.Itoa(w.PageSize)
+	}
+	seen := map[string]bool{}
+	for {
+		page := &listPage[T]{}
+		err := w.get(ctx, uri, params, page)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		// guard against a registry that hands back a token it already returned, rather than looping forever
+		if len(page.NextPageToken) == 0 || len(page.Items) == 0 || seen[page.NextPageToken] {
+			return items, nil
+		}
+		seen[page.NextPageToken] = true
+		params["nextPageToken"] = page.NextPageToken
+	}
+}
+
+// get sends a GET for uri, relative to the root of the registry REST API, and unmarshals the response into v; an empty
+// body, which the registry returns when there is nothing to list, leaves v as is
+func (w *Walker) get(ctx context.Context, uri string, params map[string]string, v interface{}) error {
+	u := w.kfmr.RootRegistryURL + uri
+	resp, err := w.kfmr.RESTClient.R().SetContext(ctx).SetAuthToken(w.kfmr.Token).SetQueryParams(params).Get(u)
+	if err != nil {
+		return util.NewTransportError(err)
+	}
+	rc := resp.StatusCode()
+	if rc != 200 {
+		return util.NewHTTPError(rc, fmt.Errorf("get for %s rc %d body %s", u, rc, resp.String()))
+	}
+	klog.V(4).Infof("get for %s returned ok", u)
+	if len(resp.Body()) == 0 {
+		return nil
+	}
+	err = json.Unmarshal(resp.Body(), v)
+	if err != nil {
+		return fmt.Errorf("json unmarshall error for %s: %s", resp.String(), err.Error())
+	}
+	return nil
+}
+
+// ServingEnvironment returns the serving environment with the given ID, fetching it at most once per Walker
+func (w *Walker) ServingEnvironment(id string) (*openapi.ServingEnvironment, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if se, ok := w.servingEnvs[id]; ok {
+		return se, nil
+	}
+	se := &openapi.ServingEnvironment{}
+	err := w.get(w.ctx, fmt.Sprintf(rest.GET_SERVING_ENV_URI, id), nil, se)
+	if err != nil {
+		return nil, err
+	}
+	w.servingEnvs[id] = se
+	return se, nil
+}
+
+// KServeInferenceService returns the KServe InferenceService with the given namespace and name, fetching it at most
+// once per Walker, or nil when there is no KServe client
+func (w *Walker) KServeInferenceService(namespace, name string) (*serverv1beta1.InferenceService, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	if kis, ok := w.kisByName[key]; ok {
+		return kis, nil
+	}
+	if w.kfmr.Config == nil || w.kfmr.Config.ServingClient == nil {
+		return nil, nil
+	}
+	kis, err := w.kfmr.Config.ServingClient.InferenceServices(namespace).Get(w.ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	w.kisByName[key] = kis
+	return kis, nil
+}
+
+// KServeInferenceServiceFor returns the KServe InferenceService labeled with the given registered model and model
+// version IDs, for when the registry has no inference service for the model version.  The KServe InferenceServices
+// are listed at most once per Walker.
+func (w *Walker) KServeInferenceServiceFor(rmID, mvID string) *serverv1beta1.InferenceService {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if !w.kisListed && w.kfmr.Config != nil && w.kfmr.Config.ServingClient != nil {
+		isList, err := w.kfmr.Config.ServingClient.InferenceServices(metav1.NamespaceAll).List(w.ctx, metav1.ListOptions{})
+		if err != nil {
+			klog.Errorf("list all inferenceservices error: %s", err.Error())
+			return nil
+		}
+		w.kisList = isList.Items
+		w.kisListed = true
+	}
+	for i := range w.kisList {
+		if butil.KServeInferenceServiceMapping(rmID, mvID, &w.kisList[i]) {
+			return &w.kisList[i]
+		}
+	}
+	return nil
+}
+
+// linksFromInferenceServices mirrors the bridge's CommonPopulator.GetLinksFromInferenceServices, looking up the
+// serving environment and KServe InferenceService with the Walker's caches
+func (w *Walker) linksFromInferenceServices(pop *kubeflowmodelregistry.CommonPopulator) []backstage.EntityLink {
+	// if for some reason kserve/kubeflow reconciliation is not working and there are no kubeflow inference services,
+	// match up based on the registered model / model version IDs
+	if pop.InferenceService == nil {
+		if pop.Kis == nil {
+			pop.Kis = w.KServeInferenceServiceFor(pop.RegisteredModel.GetId(), pop.ModelVersion.GetId())
+		}
+		if pop.Kis == nil {
+			return []backstage.EntityLink{}
+		}
+		return (&brdgkserve.CommonPopulator{InferSvc: pop.Kis}).GetLinks()
+	}
+
+	if pop.InferenceService.RegisteredModelId != pop.RegisteredModel.GetId() {
+		return []backstage.EntityLink{}
+	}
+	state, ok := pop.InferenceService.GetDesiredStateOk()
+	if !ok || *state != openapi.INFERENCESERVICESTATE_DEPLOYED {
+		return []backstage.EntityLink{}
+	}
+	if pop.Kis == nil {
+		se, err := w.ServingEnvironment(pop.InferenceService.ServingEnvironmentId)
+		if err != nil {
+			klog.Errorf("links for inference service %s: %s", pop.InferenceService.GetId(), err.Error())
+			return []backstage.EntityLink{}
+		}
+		pop.Kis, err = w.KServeInferenceService(se.GetName(), pop.InferenceService.GetRuntime())
+		if err != nil {
+			klog.Errorf("links for inference service %s: %s", pop.InferenceService.GetId(), err.Error())
+			return []backstage.EntityLink{}
+		}
+	}
+	return (&brdgkserve.CommonPopulator{InferSvc: pop.Kis}).GetLinks()
+}
@@ -0,0 +1,126 @@
+package kubeflowmodelregistry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/kfmr"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+)
+
+func TestWalker(t *testing.T) {
+	lock := sync.Mutex{}
+	requests := map[string]int{}
+	inFlight, maxInFlight := 0, 0
+	ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		requests[r.URL.Path]++
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		lock.Unlock()
+		defer func() {
+			lock.Lock()
+			inFlight--
+			lock.Unlock()
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, rest.KFMR_BASE_URI)
+		switch {
+		case path == rest.LIST_REG_MODEL_URI:
+			// two pages, where the registered model on the first page has the slowest responses
+			if r.URL.Query().Get("nextPageToken") == "page-2" {
+				_, _ = w.Write([]byte(`{"items":[` + registeredModel("2") + `,` + registeredModel("3") + `],"nextPageToken":"","pageSize":2,"size":2}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"items":[` + registeredModel("1") + `],"nextPageToken":"page-2","pageSize":2,"size":1}`))
+		case path == fmt.Sprintf(rest.GET_REG_MODEL_URI, "404"):
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasPrefix(path, rest.LIST_REG_MODEL_URI+"/") && strings.HasSuffix(path, "/versions"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, rest.LIST_REG_MODEL_URI+"/"), "/versions")
+			if id == "1" {
+				time.Sleep(50 * time.Millisecond)
+			}
+			_, _ = w.Write([]byte(`{"items":[{"id":"` + id + `0","name":"v1","registeredModelId":"` + id + `","state":"LIVE"},{"id":"` + id + `1","name":"v0","registeredModelId":"` + id + `","state":"ARCHIVED"}],"nextPageToken":"","pageSize":0,"size":2}`))
+		case strings.HasPrefix(path, rest.LIST_REG_MODEL_URI+"/"):
+			_, _ = w.Write([]byte(registeredModel(strings.TrimPrefix(path, rest.LIST_REG_MODEL_URI+"/"))))
+		case strings.HasSuffix(path, "/artifacts"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, "/model_versions/"), "/artifacts")
+			_, _ = w.Write([]byte(`{"items":[{"id":"` + id + `","name":"artifact-` + id + `","uri":"https://example.com/` + id + `"}],"nextPageToken":"","pageSize":0,"size":1}`))
+		case path == rest.LIST_INFERENCE_SERVICES_URI:
+			_, _ = w.Write([]byte(`{"items":[{"desiredState":"DEPLOYED","id":"100","modelVersionId":"30","registeredModelId":"3","runtime":"model-3","servingEnvironmentId":"7"},{"desiredState":"DEPLOYED","id":"101","modelVersionId":"30","registeredModelId":"3","runtime":"model-3-canary","servingEnvironmentId":"7"}],"nextPageToken":"","pageSize":0,"size":2}`))
+		case strings.HasPrefix(path, "/serving_environments/"):
+			_, _ = w.Write([]byte(common.MultiModelServingEnvironmentGet))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+
+	cfg := config.NewConfig()
+	kfmr.SetupKubeflowTestRESTClient(ts, cfg.Config)
+	client := SetupKubeflowRESTClient(context.Background(), cfg)
+
+	walker := NewWalker(context.Background(), client, 2, 2)
+	walks, err := walker.Walk(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, 3, len(walks))
+	for i, rmw := range walks {
+		id := fmt.Sprintf("%d", i+1)
+		common.AssertEqual(t, id, rmw.RegisteredModel.GetId())
+		common.AssertEqual(t, 1, len(rmw.Versions))
+		common.AssertEqual(t, id+"0", rmw.Versions[0].ModelVersion.GetId())
+		common.AssertEqual(t, 1, len(rmw.Versions[0].Artifacts))
+		common.AssertEqual(t, "artifact-"+id+"0", rmw.Versions[0].Artifacts[0].GetName())
+	}
+	common.AssertEqual(t, 0, len(walks[0].Versions[0].InferenceServices))
+	common.AssertEqual(t, 2, len(walks[2].Versions[0].InferenceServices))
+	common.AssertEqual(t, 2, requests[rest.KFMR_BASE_URI+rest.LIST_REG_MODEL_URI])
+	common.AssertEqual(t, 1, requests[rest.KFMR_BASE_URI+rest.LIST_INFERENCE_SERVICES_URI])
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 requests in flight but got %d", maxInFlight)
+	}
+
+	// the serving environment shared by the inference services is fetched once for all the entities printed
+	namer, err := catalog.NewNamer(&config.EntityOptions{})
+	common.AssertError(t, err)
+	for _, is := range walks[2].Versions[0].InferenceServices {
+		err = CallBackstagePrinters(context.Background(), "Owner", "Lifecycle", "", namer, &walks[2].RegisteredModel, &walks[2].Versions[0].ModelVersion, walks[2].Versions[0].Artifacts, &is, client, walker, &strings.Builder{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+	}
+	common.AssertEqual(t, 1, requests[rest.KFMR_BASE_URI+fmt.Sprintf(rest.GET_SERVING_ENV_URI, "7")])
+
+	// the IDs given are fetched in order
+	walks, err = NewWalker(context.Background(), client, 2, 2).Walk([]string{"3", "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	common.AssertEqual(t, 2, len(walks))
+	common.AssertEqual(t, "3", walks[0].RegisteredModel.GetId())
+	common.AssertEqual(t, "1", walks[1].RegisteredModel.GetId())
+
+	_, err = NewWalker(context.Background(), client, 2, 2).Walk([]string{"1", "404"})
+	common.AssertEqual(t, int(util.ExitNotFound), util.GetExitCode(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewWalker(ctx, client, 2, 2).Walk(nil)
+	common.AssertEqual(t, int(util.ExitInterrupted), util.GetExitCode(err))
+}
+
+func registeredModel(id string) string {
+	return `{"id":"` + id + `","name":"model-` + id + `","state":"LIVE"}`
+}
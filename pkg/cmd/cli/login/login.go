@@ -0,0 +1,34 @@
+package login
+
+import (
+	"strings"
+
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/auth"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+const loginExample = `
+# Log in to the identity provider RHDH is configured with, using the OIDC device authorization grant, and cache
+# the tokens in the 'bac' directory of the user's config directory
+$ %s login --backstage-oidc-issuer=https://keycloak.example.com/realms/rhdh --backstage-oidc-client-id=bac
+
+# Subsequent commands then access Backstage with the cached identity, refreshing the tokens as needed
+$ %s get components --backstage-auth=oidc --backstage-oidc-issuer=https://keycloak.example.com/realms/rhdh --backstage-oidc-client-id=bac
+`
+
+func NewCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "login",
+		Long:    "login authenticates the current user with the OIDC identity provider of Backstage, using the device authorization grant, and caches refreshable tokens for use with '--backstage-auth=oidc'",
+		Example: strings.ReplaceAll(loginExample, "%s", util.ApplicationName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return util.NewUsageError("login does not take any arguments")
+			}
+			return auth.Login(cmd.Context(), cfg.BackstageAuth, cmd.ErrOrStderr())
+		},
+	}
+	return cmd
+}
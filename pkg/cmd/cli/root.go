@@ -1,16 +1,18 @@
 package cli
 
 import (
-	"context"
-	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/cli/backstage"
-	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/server/location/client"
-	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/config"
+	"fmt"
 	brdgutil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/bridge"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/catalog"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/doctor"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/graph"
 	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/kserve"
 	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/kubeflowmodelregistry"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/cmd/cli/login"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/config"
 	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
 	"github.com/spf13/cobra"
-	"k8s.io/klog/v2"
 	"net/url"
 	"os"
 	"strconv"
@@ -47,16 +49,59 @@ $ %s get [location|components|resources|apis|entities] [args...]
 # is associated with the URL provided when the user runs the 'import-model' command.  You also can see this ID when you
 # view the locations from the Backstage UI.
 $ %s delete-model <location id>
+
+# The 'doctor catalog' command scans the AI related entities in the Backstage Catalog for orphans, broken references,
+# unresolved owners, missing locations, and entity errors, and reports the problems found as JSON.
+$ %s doctor catalog
+
+# The 'login' command authenticates with the OIDC identity provider of Backstage and caches refreshable tokens, so
+# the other commands can access Backstage as the current user with '--backstage-auth=oidc'
+$ %s login --backstage-oidc-issuer=<issuer url> --backstage-oidc-client-id=<client id>
+
+# Any of the commands can look up the Backstage and Model Registry URLs from the Routes, or Ingresses on Kubernetes,
+# in the cluster rather than requiring --backstage-url and --model-metadata-url; the URLs used are printed to stderr
+$ %s get components --discover
+$ %s new-model kubeflow <owner> <lifecycle> --discover --discover-namespaces=rhdh,rhoai-model-registries
+`
+
+	exitCodes = `
+Exit codes:
+  0    success
+  1    general error
+  2    usage error; missing or invalid arguments or flags
+  3    authentication or authorization error with a backend
+  4    the requested item was not found
+  5    conflict with an existing item
+  6    a backend is unavailable or could not be reached
+  7    validation error; content was rejected by the CLI or a backend
+  130  interrupted, i.e. with Ctrl-C
 `
 
 	newModelExample = `
 # Access a supported backend for AI Model metadata and generate Backstage Catalog Entity YAML for that metadata
 $ %s new-model kserve [args]
+
+# Also generate a System for each data science project, part of the 'ai-platform' Domain, so the Backstage system
+# diagram groups the models by where they are served
+$ %s new-model kserve <owner> <lifecycle> --systems --domain=ai-platform
+
+# The owner is a user by default, but can be any User or Group entity reference, which --verify-owner checks is in the
+# Backstage catalog
+$ %s new-model kserve group:default/ml-platform <lifecycle> --verify-owner
+
+# Own the entities of each model by the Group named in the 'team' label of its namespace, or in the 'Owner' custom
+# property of its registered model, mapping the 'ds' value to the 'data-science' Group
+$ %s new-model kserve <owner> <lifecycle> --owner-label=team --owner-map=ds=group:data-science
+$ %s new-model kubeflow <owner> <lifecycle> --owner-property=Owner
+
+# Put the entities in the 'ai' Backstage namespace, and name the Resource of each model version after the serving
+# environment, registered model, and version, so the models of different clusters and teams do not collide
+$ %s new-model kubeflow <owner> <lifecycle> --entity-namespace=ai --name-template='resource={{.Namespace}}-{{.Name}}-{{.Version}}' --on-collision=suffix
 `
 
 	getExample = `
 # Access the Backstage Catalog for Entities related to AI Models
-$ %s get <locations|components|resources|apis|entities> [args...]
+$ %s get <locations|components|resources|apis|entities|graph> [args...]
 `
 
 	deleteModelExample = `
@@ -75,6 +120,22 @@ $ %s import-model <url>
 
 # Set the additional URL for the Backstage instance, the authentication token, and Skip-TLS settings 
 $ %s import-model <url> --backstage-url=https://my-rhdh.com --backstage-token=my-token --backstage-skip-tls=true
+
+# Rather than skipping TLS, trust the CA that signed the certificate of the Backstage instance, either from a local file
+# or from a Secret or ConfigMap in the cluster, like the service CA bundle OpenShift injects into ConfigMaps
+$ %s import-model <url> --backstage-url=https://my-rhdh.com --backstage-token=my-token --backstage-ca-file=/path/to/ca.crt
+$ %s import-model <url> --backstage-url=https://my-rhdh.com --backstage-token=my-token --backstage-ca-ref=configmap:rhdh/my-service-ca:service-ca.crt
+
+# Read the authentication token from the 'token' key of the 'backstage-token' Secret in the 'rhdh' namespace rather
+# than providing it on the command line
+$ %s import-model <url> --backstage-url=https://my-rhdh.com --backstage-token-secret=rhdh/backstage-token
+
+# Authenticate with a JWT signed by a local private key, whose public key is in the JWKS of a Backstage
+# 'jwks' external access entry with the issuer 'bac' and audience 'backstage'
+$ %s import-model <url> --backstage-url=https://my-rhdh.com --backstage-auth=jwt --backstage-jwt-key-file=/path/to/key.pem
+
+# Authenticate as yourself after running 'login'
+$ %s import-model <url> --backstage-url=https://my-rhdh.com --backstage-auth=oidc --backstage-oidc-issuer=https://keycloak.example.com/realms/rhdh --backstage-oidc-client-id=bac
 `
 
 	getEntitiesExample = `
@@ -114,6 +175,13 @@ $ %s get components genai vllm --use-params-as-tags=true
 
 # Retrieve a set of Components which have any of the provided list of tags
 $ %s get components gen-ai --use-params-as-tags=true --use-any-subset=true
+
+# Retrieve the first 10 AI Components owned by the 'ml-platform' group in the 'production' lifecycle, sorted by name, and
+# only include the name and tags fields
+$ %s get components --owner=group:ml-platform --lifecycle=production --limit=10 --order-field=metadata.name,asc --fields=metadata.name,metadata.tags
+
+# Retrieve the AI Components in the 'ai' Backstage namespace that match the 'granite' full text search term
+$ %s get components --entity-namespace=ai --full-text=granite
 `
 
 	getResourcesExample = `
@@ -132,13 +200,23 @@ $ %s get resources genai vllm --use-params-as-tags=true
 
 # Retrieve a set of AI Resources which have any of the provided list of tags
 $ %s get resources gen-ai --use-params-as-tags=true --use-any-subset=true
+
+# Retrieve the first 10 AI Resources owned by the 'ml-platform' group in the 'production' lifecycle, sorted by name, and
+# only include the name and tags fields
+$ %s get resources --owner=group:ml-platform --lifecycle=production --limit=10 --order-field=metadata.name,asc --fields=metadata.name,metadata.tags
+
+# Retrieve the AI Resources in the 'ai' Backstage namespace that match the 'granite' full text search term
+$ %s get resources --entity-namespace=ai --full-text=granite
 `
 
 	getApisExample = `
 # Retrieve the Backstage Catalog for APIs related to AI Models, where being AI related is determined by the 
-# 'type' being set to 'model-service-api'
+# 'rhdh.modelcatalog.io/api-type' label being set to 'model-service-api'
 $ %s get apis [args...]
 
+# Retrieve all the APIs in the Backstage Catalog, regardless if AI related
+$ %s get apis --all
+
 # Set the URL for the Backstage, the authentication token, and Skip-TLS settings
 $ %s get locations --backstage-url=https://my-rhdh.com --backstage-token=my-token --backstage-skip-tls=true
 
@@ -150,20 +228,44 @@ $ %s get apis genai vllm --use-params-as-tags=true
 
 # Retrieve a set of AI APIs which have any of the provided list of tags
 $ %s get apis gen-ai --use-params-as-tags=true --use-any-subset=true
+
+# Retrieve the first 10 AI APIs owned by the 'ml-platform' group in the 'production' lifecycle, sorted by name, and
+# only include the name and tags fields
+$ %s get apis --owner=group:ml-platform --lifecycle=production --limit=10 --order-field=metadata.name,asc --fields=metadata.name,metadata.tags
+
+# Retrieve the AI APIs in the 'ai' Backstage namespace that match the 'granite' full text search term
+$ %s get apis --entity-namespace=ai --full-text=granite
 `
 )
 
 // NewCmd create a new root command, linking together all sub-commands organized by groups.
 func NewCmd() *cobra.Command {
-	cfg := &config.Config{}
+	cfg := config.NewConfig()
+	queryOpts := &catalog.QueryOptions{}
 	bkstgAI := &cobra.Command{
 		Use:     util.ApplicationName,
-		Long:    "Backstage AI is a command line tool that facilitates management of AI related Entities in the Backstage Catalog.",
+		Long:    "Backstage AI is a command line tool that facilitates management of AI related Entities in the Backstage Catalog.\n" + exitCodes,
 		Example: strings.ReplaceAll(bkstgAIExample, "%s", util.ApplicationName),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return util.NewUsageError("unknown command %q for %q", args[0], cmd.CommandPath())
+			}
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			cmd.Help()
 		},
+		// the URLs, CA bundles, client certificates, and tokens are resolved up front so problems with them are
+		// reported before any requests are made; discovered URLs are reported on stderr to keep stdout parsable
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return cfg.Resolve(cmd.Context(), cmd.ErrOrStderr())
+		},
+		// errors are printed once by cobra, without the usage, and mapped to an exit code by the caller
+		SilenceUsage: true,
 	}
+	bkstgAI.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return util.NewError(util.ExitUsage, err)
+	})
 
 	cfg.Kubeconfig = os.Getenv("KUBECONFIG")
 	cfg.BackstageURL = os.Getenv("BACKSTAGE_URL")
@@ -172,6 +274,27 @@ func NewCmd() *cobra.Command {
 	cfg.StoreURL = os.Getenv("MODEL_METADATA_URL")
 	cfg.StoreToken = os.Getenv("MODEL_METADATA_TOKEN")
 	cfg.StoreSkipTLS, _ = strconv.ParseBool(os.Getenv("METADATA_MODEL_SKIP_TLS"))
+	cfg.BackstageTLS.CAFile = os.Getenv("BACKSTAGE_CA_FILE")
+	cfg.BackstageTLS.CARef = os.Getenv("BACKSTAGE_CA_REF")
+	cfg.BackstageTLS.CertFile = os.Getenv("BACKSTAGE_CLIENT_CERT_FILE")
+	cfg.BackstageTLS.KeyFile = os.Getenv("BACKSTAGE_CLIENT_KEY_FILE")
+	cfg.StoreTLS.CAFile = os.Getenv("MODEL_METADATA_CA_FILE")
+	cfg.StoreTLS.CARef = os.Getenv("MODEL_METADATA_CA_REF")
+	cfg.StoreTLS.CertFile = os.Getenv("MODEL_METADATA_CLIENT_CERT_FILE")
+	cfg.StoreTLS.KeyFile = os.Getenv("MODEL_METADATA_CLIENT_KEY_FILE")
+	cfg.BackstageTokenOpts.Secret = os.Getenv("BACKSTAGE_TOKEN_SECRET")
+	cfg.StoreTokenOpts.Secret = os.Getenv("MODEL_METADATA_TOKEN_SECRET")
+	cfg.StoreTokenOpts.FromKubeconfig, _ = strconv.ParseBool(os.Getenv("MODEL_METADATA_TOKEN_FROM_KUBECONFIG"))
+	if mode := os.Getenv("BACKSTAGE_AUTH"); len(mode) > 0 {
+		cfg.BackstageAuth.Mode = mode
+	}
+	cfg.BackstageAuth.JWTKeyFile = os.Getenv("BACKSTAGE_JWT_KEY_FILE")
+	cfg.BackstageAuth.OIDCIssuer = os.Getenv("BACKSTAGE_OIDC_ISSUER")
+	cfg.BackstageAuth.OIDCClientID = os.Getenv("BACKSTAGE_OIDC_CLIENT_ID")
+	cfg.Discover.Enabled, _ = strconv.ParseBool(os.Getenv("DISCOVER_URLS"))
+	if ns := os.Getenv("DISCOVER_NAMESPACES"); len(ns) > 0 {
+		cfg.Discover.Namespaces = strings.Split(ns, ",")
+	}
 	cfg.Namespace = brdgutil.GetCurrentProject()
 
 	bkstgAI.PersistentFlags().StringVar(&(cfg.Kubeconfig), "kubeconfig", cfg.Kubeconfig,
@@ -182,14 +305,68 @@ func NewCmd() *cobra.Command {
 		"The URL used for accessing the Backstage Catalog REST API.")
 	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageToken), "backstage-token", cfg.BackstageToken,
 		"The bearer authorization token used for accessing the Backstage Catalog REST API.")
-	bkstgAI.PersistentFlags().BoolVar(&(cfg.BackstageSkipTLS), "backstage-skip-tls", cfg.StoreSkipTLS,
-		"Whether to skip use of TLS when accessing the Backstage Catalog REST API.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageTokenOpts.Secret), "backstage-token-secret", cfg.BackstageTokenOpts.Secret,
+		"A '[namespace/]name[:key]' reference to a Secret containing the bearer token for the Backstage Catalog REST API; the key defaults to 'token'.  Takes precedence over --backstage-token.")
+	bkstgAI.PersistentFlags().BoolVar(&(cfg.BackstageSkipTLS), "backstage-skip-tls", cfg.BackstageSkipTLS,
+		"Whether to skip use of TLS when accessing the Backstage Catalog REST API.  Ignored when a CA is provided.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageTLS.CAFile), "backstage-ca-file", cfg.BackstageTLS.CAFile,
+		"Path to a PEM encoded CA bundle to trust, along with the system roots, when accessing the Backstage Catalog REST API.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageTLS.CARef), "backstage-ca-ref", cfg.BackstageTLS.CARef,
+		"A '<secret|configmap>:[namespace/]name[:key]' reference to a PEM encoded CA bundle to trust when accessing the Backstage Catalog REST API; the key defaults to 'ca.crt'.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageTLS.CertFile), "backstage-client-cert-file", cfg.BackstageTLS.CertFile,
+		"Path to a PEM encoded client certificate for mutual TLS with the Backstage Catalog REST API.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageTLS.KeyFile), "backstage-client-key-file", cfg.BackstageTLS.KeyFile,
+		"Path to the PEM encoded key for the Backstage client certificate.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageAuth.Mode), "backstage-auth", cfg.BackstageAuth.Mode,
+		"How to authenticate with the Backstage Catalog REST API: 'token' sends --backstage-token as is, 'static' sends it as a Backstage external access static key, 'jwt' sends JWTs signed with --backstage-jwt-key-file, and 'oidc' sends the tokens cached by 'login'.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageAuth.JWTKeyFile), "backstage-jwt-key-file", cfg.BackstageAuth.JWTKeyFile,
+		"Path to the PEM encoded RSA, ECDSA, or Ed25519 private key used to sign JWTs for the 'jwt' auth mode; Backstage needs the matching public key in the JWKS of a 'jwks' external access entry.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageAuth.JWTKeyID), "backstage-jwt-key-id", cfg.BackstageAuth.JWTKeyID,
+		"The 'kid' header of the signed JWTs; defaults to the RFC 7638 thumbprint of the public key.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageAuth.JWTIssuer), "backstage-jwt-issuer", cfg.BackstageAuth.JWTIssuer,
+		"The 'iss' claim of the signed JWTs, which must match the issuer of the 'jwks' external access entry.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageAuth.JWTSubject), "backstage-jwt-subject", cfg.BackstageAuth.JWTSubject,
+		"The 'sub' claim of the signed JWTs.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageAuth.JWTAudience), "backstage-jwt-audience", cfg.BackstageAuth.JWTAudience,
+		"The 'aud' claim of the signed JWTs, which must match the audience of the 'jwks' external access entry.")
+	bkstgAI.PersistentFlags().DurationVar(&(cfg.BackstageAuth.JWTLifetime), "backstage-jwt-lifetime", cfg.BackstageAuth.JWTLifetime,
+		"How long each signed JWT is valid for; a new JWT is signed shortly before the current one expires.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageAuth.OIDCIssuer), "backstage-oidc-issuer", cfg.BackstageAuth.OIDCIssuer,
+		"The issuer URL of the OIDC identity provider Backstage is configured with, for 'login' and the 'oidc' auth mode.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.BackstageAuth.OIDCClientID), "backstage-oidc-client-id", cfg.BackstageAuth.OIDCClientID,
+		"The OIDC client ID, which must allow the device authorization grant, for 'login' and the 'oidc' auth mode.")
+	bkstgAI.PersistentFlags().StringSliceVar(&(cfg.BackstageAuth.OIDCScopes), "backstage-oidc-scopes", cfg.BackstageAuth.OIDCScopes,
+		"The scopes requested by 'login'; 'offline_access' is needed for the tokens to be refreshed without logging in again.")
 	bkstgAI.PersistentFlags().StringVar(&(cfg.StoreURL), "model-metadata-url", cfg.StoreURL,
 		"The URL used for accessing the external source for Model Metadata.")
 	bkstgAI.PersistentFlags().StringVar(&(cfg.StoreToken), "model-metadata-token", cfg.StoreToken,
 		"The bearer authorization token used for accessing the external source for Model Metadata.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.StoreTokenOpts.Secret), "model-metadata-token-secret", cfg.StoreTokenOpts.Secret,
+		"A '[namespace/]name[:key]' reference to a Secret containing the bearer token for the external source for Model Metadata; the key defaults to 'token'.  Takes precedence over --model-metadata-token.")
+	bkstgAI.PersistentFlags().BoolVar(&(cfg.StoreTokenOpts.FromKubeconfig), "model-metadata-token-from-kubeconfig", cfg.StoreTokenOpts.FromKubeconfig,
+		"Use the bearer token of the current kubeconfig context, i.e. the logged in OpenShift user, for the external source for Model Metadata.  Takes precedence over --model-metadata-token.")
 	bkstgAI.PersistentFlags().BoolVar(&(cfg.StoreSkipTLS), "model-metadata-skip-tls", cfg.StoreSkipTLS,
-		"Whether to skip use of TLS when accessing the external source for Model Metadata.")
+		"Whether to skip use of TLS when accessing the external source for Model Metadata.  Ignored when a CA is provided.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.StoreTLS.CAFile), "model-metadata-ca-file", cfg.StoreTLS.CAFile,
+		"Path to a PEM encoded CA bundle to trust, along with the system roots, when accessing the external source for Model Metadata.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.StoreTLS.CARef), "model-metadata-ca-ref", cfg.StoreTLS.CARef,
+		"A '<secret|configmap>:[namespace/]name[:key]' reference to a PEM encoded CA bundle to trust when accessing the external source for Model Metadata; the key defaults to 'ca.crt'.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.StoreTLS.CertFile), "model-metadata-client-cert-file", cfg.StoreTLS.CertFile,
+		"Path to a PEM encoded client certificate for mutual TLS with the external source for Model Metadata.")
+	bkstgAI.PersistentFlags().StringVar(&(cfg.StoreTLS.KeyFile), "model-metadata-client-key-file", cfg.StoreTLS.KeyFile,
+		"Path to the PEM encoded key for the Model Metadata client certificate.")
+	bkstgAI.PersistentFlags().DurationVar(&(cfg.Requests.Timeout), "request-timeout", cfg.Requests.Timeout,
+		"The time limit for each attempt of a request to Backstage, the external source for Model Metadata, or Kubernetes; 0 means no limit.")
+	bkstgAI.PersistentFlags().IntVar(&(cfg.Requests.Retries), "retries", cfg.Requests.Retries,
+		"The number of times a request to Backstage or the external source for Model Metadata is retried after a connection failure, or a 429 or 5xx response; 0 disables retries.")
+	bkstgAI.PersistentFlags().DurationVar(&(cfg.Requests.RetryWait), "retry-wait", cfg.Requests.RetryWait,
+		"The initial wait between retries, which doubles, with jitter, on each retry.")
+	bkstgAI.PersistentFlags().DurationVar(&(cfg.Requests.RetryMaxWait), "retry-max-wait", cfg.Requests.RetryMaxWait,
+		"The maximum wait between retries, including waits requested by a Retry-After header.")
+	bkstgAI.PersistentFlags().BoolVar(&(cfg.Discover.Enabled), "discover", cfg.Discover.Enabled,
+		"Look up the Backstage and Model Registry URLs not otherwise provided from the labeled Routes, Ingresses, or Services in the cluster.")
+	bkstgAI.PersistentFlags().StringSliceVar(&(cfg.Discover.Namespaces), "discover-namespaces", cfg.Discover.Namespaces,
+		"The namespaces to search, in order, when discovering URLs; all namespaces are searched when not set.")
 
 	newModel := &cobra.Command{
 		Use:     "new-model",
@@ -201,6 +378,21 @@ func NewCmd() *cobra.Command {
 		},
 	}
 
+	newModel.PersistentFlags().BoolVar(&(cfg.Entities.Systems), "systems", cfg.Entities.Systems,
+		"Also generate a System entity for each namespace of the KServe InferenceServices, or each serving environment of the Kubeflow Model Registry, and make the model entities part of it.")
+	newModel.PersistentFlags().StringVar(&(cfg.Entities.Domain), "domain", cfg.Entities.Domain,
+		"With --systems, also generate a Domain entity with this name, which the Systems are part of.")
+	newModel.PersistentFlags().StringVar(&(cfg.Entities.Namespace), "entity-namespace", cfg.Entities.Namespace,
+		"The Backstage namespace of the generated entities; they are in the 'default' namespace when not set.")
+	newModel.PersistentFlags().StringToStringVar(&(cfg.Entities.NameTemplates), "name-template", cfg.Entities.NameTemplates,
+		"A Go template naming the entities of a kind, like 'resource={{.Namespace}}-{{.Name}}-{{.Version}}', for the component, resource, or api kinds; the template can use .Namespace, .Name, .Version, and .Kind.")
+	newModel.PersistentFlags().StringVar(&(cfg.Entities.OnCollision), "on-collision", catalog.CollisionError,
+		"What happens when a --name-template gives the entities of two models the same name: 'error', or 'suffix', which adds '-2', '-3', etc. to the later ones.")
+	newModel.PersistentFlags().BoolVar(&(cfg.Entities.VerifyOwner), "verify-owner", cfg.Entities.VerifyOwner,
+		"Check that the owner of the entities, and each owner mapped with --owner-label or --owner-property, is a User or Group in the Backstage catalog.")
+	newModel.PersistentFlags().StringToStringVar(&(cfg.Entities.OwnerMap), "owner-map", cfg.Entities.OwnerMap,
+		"Map the values of --owner-label or --owner-property to owners, like 'team-a=group:default/ml-platform'; values not mapped are the owner themselves.")
+
 	newModel.AddCommand(kserve.NewCmd(cfg))
 	newModel.AddCommand(kubeflowmodelregistry.NewCmd(cfg))
 
@@ -218,11 +410,16 @@ func NewCmd() *cobra.Command {
 		Long:    "delete-model removes the Backstage Catalog for Entities corresponding to the provided location ID",
 		Aliases: []string{"delete", "dm", "del", "d", "delete-models"},
 		Example: strings.ReplaceAll(deleteModelExample, "%s", util.ApplicationName),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				klog.Error("ERROR: delete-model requires a location ID")
+				return util.NewUsageError("delete-model requires a location ID")
+			}
+			err := catalog.SetupCatalogRESTClient(cmd.Context(), cfg).DeleteLocation(args[0])
+			if err != nil {
+				return err
 			}
-			brdgutil.ProcessOutput(backstage.SetupBackstageRESTClient(cfg).DeleteLocation(args[0]))
+			fmt.Fprintf(cmd.OutOrStdout(), "Backstage location %s deleted\n", args[0])
+			return nil
 		},
 	}
 	importModel := &cobra.Command{
@@ -230,97 +427,31 @@ func NewCmd() *cobra.Command {
 		Long:    "import-model updates the Backstage Catalog with Entities contained in the provided location URL",
 		Aliases: []string{"post", "im", "p", "i", "import-models"},
 		Example: strings.ReplaceAll(importModelExample, "%s", util.ApplicationName),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				klog.Error("ERROR: import-model requires a location URL")
-				klog.Flush()
-				return
+				return util.NewUsageError("import-model requires a location URL")
 			}
 			u, uerr := url.Parse(args[0])
 			if uerr != nil {
-				klog.Errorf("ERROR: import-model requires a valid location URL: %s", uerr.Error())
-				klog.Flush()
-				return
+				return util.NewUsageError("import-model requires a valid location URL: %s", uerr.Error())
 			}
 			switch u.Scheme {
 			case "http":
 				fallthrough
 			case "https":
-				bkstgREST := backstage.SetupBackstageRESTClient(cfg)
+				bkstgREST := catalog.SetupCatalogRESTClient(cmd.Context(), cfg)
 				retJSON, err := bkstgREST.ImportLocation(args[0])
 				if err != nil {
-					brdgutil.ProcessOutput("", err)
+					return err
 				}
-				brdgutil.ProcessOutput(bkstgREST.PrintImportLocation(retJSON))
-				return
+				str, err := bkstgREST.PrintImportLocation(retJSON)
+				if err != nil {
+					return util.NewError(util.ExitValidation, err)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), str)
+				return nil
 			default:
-				klog.Errorf("ERROR: import-model only supports http and https prototype scheme URLs")
-			}
-
-		},
-	}
-
-	startBridge := &cobra.Command{
-		Use:     "start-bridge",
-		Aliases: []string{"sb"},
-		Long:    "start-bridge launches a REST API based service and K8s controller that serves as a normalization tier between Backstage and various AI model metadata systems.",
-		Example: "",
-		Run: func(cmd *cobra.Command, args []string) {
-			ctx := context.Background()
-			artifacts := client.NewArtifacts(ctx /*[]byte{},*/, cfg)
-			err := artifacts.Delete()
-			if err != nil {
-				klog.Errorf("ERROR: import-model: %s", err.Error())
-				klog.Flush()
-				return
-			}
-			err = artifacts.Create()
-			if err != nil {
-				klog.Errorf("ERROR: import-model: %s", err.Error())
-				klog.Flush()
-				return
-			}
-			err = artifacts.Ready()
-			if err != nil {
-				klog.Errorf("ERROR: import-model: %s", err.Error())
-				klog.Flush()
-				return
-			}
-
-		},
-	}
-
-	addBridgeContent := &cobra.Command{
-		Use:     "add-bridge-content",
-		Aliases: []string{"abc"},
-		Long:    "add-bridge-content updates the set of catalog-info.yaml files the bridge's REST API will return.",
-		// remember k8s CM keys can only contain alphanumerics and the '.', '-', and '_' symbols ... mention this in the help
-		Example: "",
-		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) < 3 {
-				klog.Errorf("ERROR: need 'model-source string parameter, model-version string parameter, and local catalog-info.yaml file parameter")
-			}
-			u, uerr := url.Parse(args[2])
-			if uerr != nil {
-				klog.Errorf("ERROR: add-bridge-content given invalid catalog-info.yaml file location URL: %s", uerr.Error())
-				klog.Flush()
-				return
-			}
-			filePath := u.Path
-			var content []byte
-			var fileErr error
-			content, fileErr = os.ReadFile(filePath)
-			if fileErr != nil {
-				klog.Errorf("ERROR: add-bridge-content problem reading file %s: %s", filePath, fileErr.Error())
-				klog.Flush()
-				return
-			}
-			ctx := context.Background()
-			artifacts := client.NewArtifacts(ctx, cfg)
-			err := artifacts.AddContent(args[0]+"_"+args[1], content)
-			if err != nil {
-				klog.Errorf("ERROR: add-bridge-content problem adding content: %s", err.Error())
-				klog.Flush()
+				return util.NewUsageError("import-model only supports http and https prototype scheme URLs")
 			}
 		},
 	}
@@ -329,8 +460,10 @@ func NewCmd() *cobra.Command {
 	bkstgAI.AddCommand(queryModel)
 	bkstgAI.AddCommand(deleteModel)
 	bkstgAI.AddCommand(importModel)
-	bkstgAI.AddCommand(startBridge)
-	bkstgAI.AddCommand(addBridgeContent)
+	bkstgAI.AddCommand(bridge.NewCmd(cfg))
+	bkstgAI.AddCommand(bridge.NewLegacyCmds(cfg)...)
+	bkstgAI.AddCommand(doctor.NewCmd(cfg))
+	bkstgAI.AddCommand(login.NewCmd(cfg))
 
 	queryModel.AddCommand(&cobra.Command{
 		Use:     "entities",
@@ -338,11 +471,7 @@ func NewCmd() *cobra.Command {
 		Aliases: []string{"e", "entity"},
 		Example: strings.ReplaceAll(getEntitiesExample, "%s", util.ApplicationName),
 		RunE: func(cmd *cobra.Command, args []string) error {
-
-			str, err := backstage.SetupBackstageRESTClient(cfg).ListEntities()
-			brdgutil.ProcessOutput(str, err)
-			return err
-
+			return catalog.SetupCatalogRESTClient(cmd.Context(), cfg).ListEntities(cmd.OutOrStdout())
 		},
 	})
 
@@ -352,9 +481,7 @@ func NewCmd() *cobra.Command {
 		Aliases: []string{"l", "location"},
 		Example: strings.ReplaceAll(getLocationsExample, "%s", util.ApplicationName),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			str, err := backstage.SetupBackstageRESTClient(cfg).GetLocations(args...)
-			brdgutil.ProcessOutput(str, err)
-			return err
+			return catalog.SetupCatalogRESTClient(cmd.Context(), cfg).GetLocations(cmd.OutOrStdout(), args...)
 		},
 	})
 
@@ -364,9 +491,7 @@ func NewCmd() *cobra.Command {
 		Aliases: []string{"c", "component"},
 		Example: strings.ReplaceAll(getComponentsExample, "%s", util.ApplicationName),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			str, err := backstage.SetupBackstageRESTClient(cfg).GetComponent(args...)
-			brdgutil.ProcessOutput(str, err)
-			return err
+			return catalog.SetupCatalogRESTClient(cmd.Context(), cfg).GetComponents(cmd.OutOrStdout(), queryOpts, args...)
 		},
 	})
 
@@ -376,28 +501,56 @@ func NewCmd() *cobra.Command {
 		Aliases: []string{"r", "resource"},
 		Example: strings.ReplaceAll(getResourcesExample, "%s", util.ApplicationName),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			str, err := backstage.SetupBackstageRESTClient(cfg).GetResource(args...)
-			brdgutil.ProcessOutput(str, err)
-			return err
+			return catalog.SetupCatalogRESTClient(cmd.Context(), cfg).GetResources(cmd.OutOrStdout(), queryOpts, args...)
 		},
 	})
 
-	queryModel.AddCommand(&cobra.Command{
+	getAPIs := &cobra.Command{
 		Use:     "apis",
 		Long:    "apis retrieves the AI related Backstage Catalog APIS",
 		Aliases: []string{"a", "api"},
 		Example: strings.ReplaceAll(getApisExample, "%s", util.ApplicationName),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			str, err := backstage.SetupBackstageRESTClient(cfg).GetAPI(args...)
-			brdgutil.ProcessOutput(str, err)
-			return err
+			return catalog.SetupCatalogRESTClient(cmd.Context(), cfg).GetAPIs(cmd.OutOrStdout(), queryOpts, args...)
 		},
-	})
+	}
+	getAPIs.Flags().BoolVar(&(queryOpts.All), "all", queryOpts.All,
+		"Retrieve all APIs in the Backstage Catalog, not just those labeled as AI related")
+	queryModel.AddCommand(getAPIs)
+	queryModel.AddCommand(graph.NewCmd(cfg, queryOpts))
 
 	queryModel.PersistentFlags().BoolVar(&(cfg.ParamsAsTags), "use-params-as-tags", cfg.ParamsAsTags,
 		"Use any additional parameters as tag identifiers")
 	queryModel.PersistentFlags().BoolVar(&(cfg.AnySubsetWorks), "allow-tags-subset", cfg.AnySubsetWorks,
 		"When set with 'use-params-as-tags', this just requires the tags provided to be set, but allows for additional tags to be set")
-
+	queryModel.PersistentFlags().StringVar(&(queryOpts.Owner), "owner", queryOpts.Owner,
+		"Only retrieve entities owned by this entity reference; 'user' is assumed when no kind is specified")
+	queryModel.PersistentFlags().StringVar(&(queryOpts.Lifecycle), "lifecycle", queryOpts.Lifecycle,
+		"Only retrieve entities with this lifecycle")
+	queryModel.PersistentFlags().StringVar(&(queryOpts.Namespace), "entity-namespace", queryOpts.Namespace,
+		"Only retrieve entities in this Backstage Catalog namespace")
+	queryModel.PersistentFlags().IntVar(&(queryOpts.Limit), "limit", queryOpts.Limit,
+		"The maximum number of entities to retrieve; 0 means all entities are retrieved")
+	queryModel.PersistentFlags().StringSliceVar(&(queryOpts.Fields), "fields", queryOpts.Fields,
+		"Comma separated list of the entity fields to retrieve, for example 'metadata.name,spec.owner'")
+	queryModel.PersistentFlags().StringArrayVar(&(queryOpts.OrderFields), "order-field", queryOpts.OrderFields,
+		"Field to sort the entities by, with an optional ',asc' or ',desc' suffix; can be specified multiple times")
+	queryModel.PersistentFlags().StringVar(&(queryOpts.FullText), "full-text", queryOpts.FullText,
+		"Only retrieve entities matching this full text search term")
+
+	wrapArgs(bkstgAI)
 	return bkstgAI
 }
+
+// wrapArgs maps the errors of the positional argument validators of cmd and its sub-commands to ExitUsage, as cobra's
+// built-in validators, like cobra.MaximumNArgs, return plain errors
+func wrapArgs(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			return util.NewError(util.ExitUsage, validate(cmd, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		wrapArgs(sub)
+	}
+}
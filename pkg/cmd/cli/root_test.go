@@ -2,6 +2,7 @@ package cli
 
 import (
 	cobra2 "github.com/redhat-ai-dev/model-catalog-bridge/test/cobra"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
 	"github.com/spf13/cobra"
 	"strings"
 	"testing"
@@ -16,6 +17,7 @@ func TestNewCmd(t *testing.T) {
 		generatesHelp  bool
 		errorStr       string
 		outStr         string
+		exitCode       util.ExitCode
 	}{
 		{
 			args:          []string{"new-model"},
@@ -100,6 +102,91 @@ func TestNewCmd(t *testing.T) {
 			args:          []string{"get", "help", "entities"},
 			generatesHelp: true,
 		},
+		{
+			args:           []string{"get", "components"},
+			generatesError: true,
+			errorStr:       "unsupported protocol scheme",
+			exitCode:       util.ExitUnavailable,
+		},
+		{
+			args:           []string{"foo"},
+			generatesError: true,
+			errorStr:       "unknown command \"foo\"",
+			exitCode:       util.ExitUsage,
+		},
+		{
+			args:           []string{"get", "components", "--no-such-flag"},
+			generatesError: true,
+			errorStr:       "unknown flag: --no-such-flag",
+			exitCode:       util.ExitUsage,
+		},
+		{
+			args:           []string{"get", "graph", "foo", "bar"},
+			generatesError: true,
+			errorStr:       "accepts at most 1 arg(s), received 2",
+			exitCode:       util.ExitUsage,
+		},
+		{
+			args:           []string{"delete-model"},
+			generatesError: true,
+			errorStr:       "delete-model requires a location ID",
+			exitCode:       util.ExitUsage,
+		},
+		{
+			args:           []string{"import-model", "ftp://foo"},
+			generatesError: true,
+			errorStr:       "import-model only supports http and https prototype scheme URLs",
+			exitCode:       util.ExitUsage,
+		},
+		{
+			args:           []string{"add-bridge-content", "foo", "bar"},
+			generatesError: true,
+			errorStr:       "need 'model-source string parameter",
+			exitCode:       util.ExitUsage,
+		},
+		{
+			args:           []string{"add-bridge-content", "foo", "bar", "/no/such/catalog-info.yaml"},
+			generatesError: true,
+			errorStr:       "add-bridge-content problem reading file /no/such/catalog-info.yaml",
+			exitCode:       util.ExitValidation,
+		},
+		{
+			args:           []string{"bridge", "content", "add", "foo", "bar", "/no/such/catalog-info.yaml"},
+			generatesError: true,
+			errorStr:       "add problem reading file /no/such/catalog-info.yaml",
+			exitCode:       util.ExitValidation,
+		},
+		{
+			args:           []string{"bridge", "content", "get"},
+			generatesError: true,
+			errorStr:       "need the key of the content to print",
+			exitCode:       util.ExitUsage,
+		},
+		{
+			args:           []string{"bridge", "start", "--timeout=1m"},
+			generatesError: true,
+			errorStr:       "--timeout and --skip-tls can only be used with --wait",
+			exitCode:       util.ExitUsage,
+		},
+		{
+			args:           []string{"bridge", "start", "-o", "yaml"},
+			generatesError: true,
+			errorStr:       "--output can only be used with --dry-run",
+			exitCode:       util.ExitUsage,
+		},
+		// flag settings persist across invocations of the same command, so these are last
+		{
+			args:           []string{"get", "components", "--backstage-ca-file", "/no/such/ca.crt"},
+			generatesError: true,
+			errorStr:       "reading CA file /no/such/ca.crt",
+			exitCode:       util.ExitValidation,
+		},
+		{
+			args:           []string{"get", "components", "--backstage-ca-file", "", "--backstage-client-cert-file", "/no/such/tls.crt"},
+			generatesError: true,
+			errorStr:       "both a client certificate and key are required for mutual TLS",
+			exitCode:       util.ExitUsage,
+		},
 	} {
 		subCmd, stdout, stderr, err := cobra2.ExecuteCommandC(cmd, tc.args...)
 		switch {
@@ -109,6 +196,10 @@ func TestNewCmd(t *testing.T) {
 			t.Errorf("error generated unexpectedly for '%s': %s", strings.Join(tc.args, " "), err.Error())
 		case err != nil && tc.generatesError && !strings.Contains(stderr, tc.errorStr):
 			t.Errorf("unexpected error output for '%s'- got '%s' but expected '%s'", strings.Join(tc.args, " "), stderr, tc.errorStr)
+		case err != nil && tc.exitCode != util.ExitOK && util.GetExitCode(err) != int(tc.exitCode):
+			t.Errorf("unexpected exit code for '%s' - got %d but expected %d", strings.Join(tc.args, " "), util.GetExitCode(err), tc.exitCode)
+		case err != nil && strings.Count(stderr, tc.errorStr) != 1:
+			t.Errorf("error should be printed exactly once for '%s': %s", strings.Join(tc.args, " "), stderr)
 		case tc.generatesHelp && !testHelpOK(stdout, subCmd):
 			t.Errorf("unexpected help output for '%s' - got '%s' but expected '%s'", strings.Join(tc.args, " "), stdout, subCmd.Long)
 		}
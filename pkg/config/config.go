@@ -0,0 +1,154 @@
+package config
+
+import (
+	"context"
+	"io"
+
+	servingv1alpha1 "github.com/kserve/kserve/pkg/client/clientset/versioned/typed/serving/v1alpha1"
+	routev1 "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	brdgconfig "github.com/redhat-ai-dev/model-catalog-bridge/pkg/config"
+	brdgutil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/auth"
+	"golang.org/x/oauth2"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	networkingv1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	"k8s.io/client-go/rest"
+)
+
+// Config extends the bridge's config.Config with the settings only the CLI uses; the bridge's settings are promoted
+// so the two can be used interchangeably, and the embedded Config is passed whenever a bridge API is called
+type Config struct {
+	*brdgconfig.Config
+
+	// TLS related
+	BackstageTLS TLSOptions
+	StoreTLS     TLSOptions
+
+	// Token related
+	BackstageTokenOpts TokenOptions
+	StoreTokenOpts     TokenOptions
+
+	// Backstage auth related; BackstageTokenSource is built from BackstageAuth and the resolved BackstageToken
+	BackstageAuth        *auth.Options
+	BackstageTokenSource oauth2.TokenSource
+
+	// Timeout and retry policy for the REST clients
+	Requests RequestOptions
+
+	// URL discovery related
+	Discover DiscoverOptions
+
+	// Entity generation related
+	Entities EntityOptions
+
+	// CoreClient is used for reading the Secrets and ConfigMaps referenced by other settings; it is built from the
+	// kubeconfig on first use if not set
+	CoreClient corev1.CoreV1Interface
+	// RouteClient and NetworkingClient are used for URL discovery and for exposing the bridge; they are built from the
+	// kubeconfig if not set
+	RouteClient      routev1.RouteV1Interface
+	NetworkingClient networkingv1.NetworkingV1Interface
+	// AppsClient is used for deploying the bridge; it is built from the kubeconfig on first use if not set
+	AppsClient appsv1.AppsV1Interface
+	// ServingAlphaClient complements the bridge's ServingClient with the KServe v1alpha1 kinds, like ServingRuntimes;
+	// it is built from the kubeconfig on first use if not set
+	ServingAlphaClient servingv1alpha1.ServingV1alpha1Interface
+}
+
+func NewConfig() *Config {
+	return &Config{Config: &brdgconfig.Config{}, BackstageAuth: auth.NewOptions(), Requests: NewRequestOptions()}
+}
+
+// Resolve completes the settings which are derived from the cluster, files, Secrets, ConfigMaps, or the kubeconfig,
+// writing any URLs discovered to out
+func (c *Config) Resolve(ctx context.Context, out io.Writer) error {
+	err := c.DiscoverURLs(ctx, out)
+	if err != nil {
+		return err
+	}
+	err = c.ResolveTLS(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.ResolveTokens(ctx)
+	if err != nil {
+		return err
+	}
+	return c.ResolveBackstageAuth(ctx)
+}
+
+// GetCoreClient returns the CoreClient, building it from the kubeconfig if needed
+func (c *Config) GetCoreClient() (corev1.CoreV1Interface, error) {
+	if c.CoreClient != nil {
+		return c.CoreClient, nil
+	}
+	restCfg, err := c.GetK8sConfig()
+	if err != nil {
+		return nil, err
+	}
+	c.CoreClient, err = corev1.NewForConfig(restCfg)
+	return c.CoreClient, err
+}
+
+// GetAppsClient returns the AppsClient, building it from the kubeconfig if needed
+func (c *Config) GetAppsClient() (appsv1.AppsV1Interface, error) {
+	if c.AppsClient != nil {
+		return c.AppsClient, nil
+	}
+	restCfg, err := c.GetK8sConfig()
+	if err != nil {
+		return nil, err
+	}
+	c.AppsClient, err = appsv1.NewForConfig(restCfg)
+	return c.AppsClient, err
+}
+
+// GetRouteClient returns the RouteClient, building it from the kubeconfig if needed
+func (c *Config) GetRouteClient() (routev1.RouteV1Interface, error) {
+	if c.RouteClient != nil {
+		return c.RouteClient, nil
+	}
+	restCfg, err := c.GetK8sConfig()
+	if err != nil {
+		return nil, err
+	}
+	c.RouteClient, err = routev1.NewForConfig(restCfg)
+	return c.RouteClient, err
+}
+
+// GetNetworkingClient returns the NetworkingClient, building it from the kubeconfig if needed
+func (c *Config) GetNetworkingClient() (networkingv1.NetworkingV1Interface, error) {
+	if c.NetworkingClient != nil {
+		return c.NetworkingClient, nil
+	}
+	restCfg, err := c.GetK8sConfig()
+	if err != nil {
+		return nil, err
+	}
+	c.NetworkingClient, err = networkingv1.NewForConfig(restCfg)
+	return c.NetworkingClient, err
+}
+
+// GetServingAlphaClient returns the ServingAlphaClient, building it from the kubeconfig if needed
+func (c *Config) GetServingAlphaClient() (servingv1alpha1.ServingV1alpha1Interface, error) {
+	if c.ServingAlphaClient != nil {
+		return c.ServingAlphaClient, nil
+	}
+	restCfg, err := c.GetK8sConfig()
+	if err != nil {
+		return nil, err
+	}
+	c.ServingAlphaClient, err = servingv1alpha1.NewForConfig(restCfg)
+	return c.ServingAlphaClient, err
+}
+
+// GetK8sConfig returns the REST config from the bridge's brdgutil.GetK8sConfig with the request timeout for the CLI
+func (c *Config) GetK8sConfig() (*rest.Config, error) {
+	restCfg, err := brdgutil.GetK8sConfig(c.Config)
+	if err != nil {
+		return nil, err
+	}
+	restCfg.Timeout = c.Requests.Timeout
+	return restCfg, nil
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	routev1 "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	networkingv1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+)
+
+var (
+	// BackstageSelectors are the labels the RHDH operator, the RHDH Helm chart, and the upstream Backstage Helm chart
+	// set on the Route or Ingress for Backstage
+	BackstageSelectors = []string{
+		"rhdh.redhat.com/app",
+		"app.kubernetes.io/name in (backstage,developer-hub)",
+	}
+	// ModelRegistrySelectors are the labels the RHOAI / Kubeflow model registry operator sets on the Route and Service
+	// for a Model Registry
+	ModelRegistrySelectors = []string{
+		"component=model-registry",
+		"app.kubernetes.io/component=model-registry",
+	}
+)
+
+// DiscoverOptions control looking up the Backstage and Model Registry URLs from the cluster when they are not provided
+type DiscoverOptions struct {
+	Enabled bool
+	// Namespaces are searched in order; all namespaces are searched when empty
+	Namespaces []string
+}
+
+// DiscoveredURL is a candidate URL along with the Kubernetes object it was built from
+type DiscoveredURL struct {
+	URL    string
+	Source string
+}
+
+type discoverer struct {
+	routes     routev1.RouteV1Interface
+	ingresses  networkingv1.NetworkingV1Interface
+	services   corev1.CoreV1Interface
+	namespaces []string
+	// noRoutes is set once the Route API is found to be missing, i.e. on Kubernetes rather than OpenShift
+	noRoutes bool
+}
+
+// DiscoverURLs sets the Backstage and model metadata store URLs which have not been provided from the Routes, or
+// Ingresses where Routes are not available, labeled as Backstage and Model Registry instances, writing what was
+// resolved to out.  For the Model Registry, the Service is used when it is not exposed outside the cluster.
+func (c *Config) DiscoverURLs(ctx context.Context, out io.Writer) error {
+	if !c.Discover.Enabled || (len(c.BackstageURL) > 0 && len(c.StoreURL) > 0) {
+		return nil
+	}
+	d, err := c.newDiscoverer()
+	if err != nil {
+		return err
+	}
+	for _, target := range []struct {
+		name      string
+		flag      string
+		url       *string
+		selectors []string
+		services  bool
+	}{
+		{"Backstage", "--backstage-url", &c.BackstageURL, BackstageSelectors, false},
+		{"Model Registry", "--model-metadata-url", &c.StoreURL, ModelRegistrySelectors, true},
+	} {
+		if len(*target.url) > 0 {
+			continue
+		}
+		candidates, err := d.find(ctx, target.selectors, target.services)
+		if err != nil {
+			return util.NewKubeError(fmt.Errorf("error discovering the %s URL, try limiting the search with --discover-namespaces or set %s: %w", target.name, target.flag, err))
+		}
+		if len(candidates) == 0 {
+			fmt.Fprintf(out, "No %s instance found in the cluster; set %s\n", target.name, target.flag)
+			continue
+		}
+		if len(candidates) > 1 {
+			fmt.Fprintf(out, "Found %d %s instances, use %s to select a different one:\n", len(candidates), target.name, target.flag)
+			for _, candidate := range candidates {
+				fmt.Fprintf(out, "  %s from %s\n", candidate.URL, candidate.Source)
+			}
+		}
+		*target.url = candidates[0].URL
+		fmt.Fprintf(out, "Using %s URL %s from %s\n", target.name, candidates[0].URL, candidates[0].Source)
+	}
+	return nil
+}
+
+func (c *Config) newDiscoverer() (*discoverer, error) {
+	d := &discoverer{namespaces: c.Discover.Namespaces}
+	if len(d.namespaces) == 0 {
+		d.namespaces = []string{metav1.NamespaceAll}
+	}
+	var err error
+	d.services, err = c.GetCoreClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	d.routes, err = c.GetRouteClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	d.ingresses, err = c.GetNetworkingClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	return d, nil
+}
+
+// find returns the candidates from Routes, then Ingresses, then Services if services is set, stopping at the first
+// kind of object with a match; candidates are ordered by the namespace hints, then by namespace and name, so the
+// choice is stable
+func (d *discoverer) find(ctx context.Context, selectors []string, services bool) ([]DiscoveredURL, error) {
+	finders := []func(context.Context, string, string) ([]DiscoveredURL, error){d.fromRoutes, d.fromIngresses}
+	if services {
+		finders = append(finders, d.fromServices)
+	}
+	for _, finder := range finders {
+		candidates := []DiscoveredURL{}
+		seen := map[string]bool{}
+		for _, ns := range d.namespaces {
+			found := []DiscoveredURL{}
+			for _, selector := range selectors {
+				list, err := finder(ctx, ns, selector)
+				if err != nil {
+					return nil, err
+				}
+				for _, candidate := range list {
+					if !seen[candidate.Source] {
+						seen[candidate.Source] = true
+						found = append(found, candidate)
+					}
+				}
+			}
+			sort.Slice(found, func(i, j int) bool {
+				return found[i].Source < found[j].Source
+			})
+			candidates = append(candidates, found...)
+		}
+		if len(candidates) > 0 {
+			return candidates, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *discoverer) fromRoutes(ctx context.Context, ns, selector string) ([]DiscoveredURL, error) {
+	if d.noRoutes {
+		return nil, nil
+	}
+	list, err := d.routes.Routes(ns).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if apierrors.IsNotFound(err) {
+		d.noRoutes = true
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	candidates := []DiscoveredURL{}
+	for _, route := range list.Items {
+		host := route.Spec.Host
+		// an unset host is generated by the router and only reported in the status
+		for i := 0; len(host) == 0 && i < len(route.Status.Ingress); i++ {
+			host = route.Status.Ingress[i].Host
+		}
+		if len(host) == 0 {
+			continue
+		}
+		scheme := "http"
+		if route.Spec.TLS != nil {
+			scheme = "https"
+		}
+		candidates = append(candidates, DiscoveredURL{
+			URL:    scheme + "://" + host + strings.TrimSuffix(route.Spec.Path, "/"),
+			Source: fmt.Sprintf("Route %s/%s", route.Namespace, route.Name),
+		})
+	}
+	return candidates, nil
+}
+
+func (d *discoverer) fromIngresses(ctx context.Context, ns, selector string) ([]DiscoveredURL, error) {
+	list, err := d.ingresses.Ingresses(ns).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	candidates := []DiscoveredURL{}
+	for _, ingress := range list.Items {
+		tlsHosts := map[string]bool{}
+		for _, tls := range ingress.Spec.TLS {
+			for _, host := range tls.Hosts {
+				tlsHosts[host] = true
+			}
+		}
+		for _, rule := range ingress.Spec.Rules {
+			if len(rule.Host) == 0 {
+				continue
+			}
+			scheme := "http"
+			if tlsHosts[rule.Host] {
+				scheme = "https"
+			}
+			candidates = append(candidates, DiscoveredURL{
+				URL:    scheme + "://" + rule.Host,
+				Source: fmt.Sprintf("Ingress %s/%s", ingress.Namespace, ingress.Name),
+			})
+			break
+		}
+	}
+	return candidates, nil
+}
+
+// fromServices only considers the 'https-api' and 'http-api' ports the model registry operator defines for the REST API
+func (d *discoverer) fromServices(ctx context.Context, ns, selector string) ([]DiscoveredURL, error) {
+	list, err := d.services.Services(ns).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	candidates := []DiscoveredURL{}
+	for _, svc := range list.Items {
+		ports := map[string]int32{}
+		for _, port := range svc.Spec.Ports {
+			ports[port.Name] = port.Port
+		}
+		for _, scheme := range []string{"https", "http"} {
+			if port, ok := ports[scheme+"-api"]; ok {
+				candidates = append(candidates, DiscoveredURL{
+					URL:    fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d", scheme, svc.Name, svc.Namespace, port),
+					Source: fmt.Sprintf("Service %s/%s", svc.Namespace, svc.Name),
+				})
+				break
+			}
+		}
+	}
+	return candidates, nil
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	routev1 "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	networkingv1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	rhdhRoute = `{"metadata":{"name":"backstage-developer-hub","namespace":"rhdh"},"spec":{"host":"backstage-developer-hub-rhdh.apps.example.com","tls":{"termination":"edge"}}}`
+	devRoute  = `{"metadata":{"name":"backstage-dev","namespace":"dev"},"spec":{"host":"backstage-dev.apps.example.com","tls":{"termination":"edge"}}}`
+	mrRoute   = `{"metadata":{"name":"modelregistry-public-https","namespace":"rhoai-model-registries"},"spec":{"tls":{"termination":"passthrough"}},"status":{"ingress":[{"host":"modelregistry-public-rest.apps.example.com"}]}}`
+	rhdhIng   = `{"metadata":{"name":"backstage","namespace":"rhdh"},"spec":{"tls":[{"hosts":["backstage.example.com"]}],"rules":[{"host":"backstage.example.com"}]}}`
+	mrSvc     = `{"metadata":{"name":"modelregistry-public","namespace":"kubeflow"},"spec":{"ports":[{"name":"grpc-api","port":9090},{"name":"http-api","port":8080}]}}`
+)
+
+func TestDiscoverURLs(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		namespaces   []string
+		backstageURL string
+		// responses are keyed by the request path and label selector
+		responses    map[string]string
+		noRoutes     bool
+		forbidden    bool
+		expectedBkst string
+		expectedMR   string
+		expectedOut  string
+		exitCode     util.ExitCode
+	}{
+		{
+			name: "openshift routes in all namespaces",
+			responses: map[string]string{
+				"/apis/route.openshift.io/v1/routes?rhdh.redhat.com/app":      rhdhRoute,
+				"/apis/route.openshift.io/v1/routes?component=model-registry": mrRoute,
+			},
+			expectedBkst: "https://backstage-developer-hub-rhdh.apps.example.com",
+			expectedMR:   "https://modelregistry-public-rest.apps.example.com",
+			expectedOut: `Using Backstage URL https://backstage-developer-hub-rhdh.apps.example.com from Route rhdh/backstage-developer-hub
+Using Model Registry URL https://modelregistry-public-rest.apps.example.com from Route rhoai-model-registries/modelregistry-public-https
+`,
+		},
+		{
+			name:       "namespace hints order the candidates",
+			namespaces: []string{"rhdh", "dev"},
+			responses: map[string]string{
+				"/apis/route.openshift.io/v1/namespaces/dev/routes?rhdh.redhat.com/app":                                  devRoute,
+				"/apis/route.openshift.io/v1/namespaces/rhdh/routes?app.kubernetes.io/name in (backstage,developer-hub)": rhdhRoute,
+			},
+			expectedBkst: "https://backstage-developer-hub-rhdh.apps.example.com",
+			expectedOut: `Found 2 Backstage instances, use --backstage-url to select a different one:
+  https://backstage-developer-hub-rhdh.apps.example.com from Route rhdh/backstage-developer-hub
+  https://backstage-dev.apps.example.com from Route dev/backstage-dev
+Using Backstage URL https://backstage-developer-hub-rhdh.apps.example.com from Route rhdh/backstage-developer-hub
+No Model Registry instance found in the cluster; set --model-metadata-url
+`,
+		},
+		{
+			name:     "kubernetes ingress and service",
+			noRoutes: true,
+			responses: map[string]string{
+				"/apis/networking.k8s.io/v1/ingresses?app.kubernetes.io/name in (backstage,developer-hub)": rhdhIng,
+				"/api/v1/services?app.kubernetes.io/component=model-registry":                              mrSvc,
+			},
+			expectedBkst: "https://backstage.example.com",
+			expectedMR:   "http://modelregistry-public.kubeflow.svc.cluster.local:8080",
+			expectedOut: `Using Backstage URL https://backstage.example.com from Ingress rhdh/backstage
+Using Model Registry URL http://modelregistry-public.kubeflow.svc.cluster.local:8080 from Service kubeflow/modelregistry-public
+`,
+		},
+		{
+			name:         "provided urls are not replaced",
+			backstageURL: "https://my-rhdh.com",
+			responses: map[string]string{
+				"/apis/route.openshift.io/v1/routes?rhdh.redhat.com/app":      rhdhRoute,
+				"/apis/route.openshift.io/v1/routes?component=model-registry": mrRoute,
+			},
+			expectedBkst: "https://my-rhdh.com",
+			expectedMR:   "https://modelregistry-public-rest.apps.example.com",
+			expectedOut: `Using Model Registry URL https://modelregistry-public-rest.apps.example.com from Route rhoai-model-registries/modelregistry-public-https
+`,
+		},
+		{
+			name:      "listing all namespaces is forbidden",
+			forbidden: true,
+			exitCode:  util.ExitAuth,
+		},
+	} {
+		apiServer := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case tc.forbidden:
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"Forbidden","code":403}`))
+				return
+			case tc.noRoutes && strings.HasPrefix(r.URL.Path, "/apis/route.openshift.io"):
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`404 page not found`))
+				return
+			}
+			item := tc.responses[r.URL.Path+"?"+r.URL.Query().Get("labelSelector")]
+			_, _ = w.Write([]byte(`{"metadata":{},"items":[` + item + `]}`))
+		})
+		restCfg := &rest.Config{Host: apiServer.URL}
+		cfg := NewConfig()
+		cfg.CoreClient, _ = corev1.NewForConfig(restCfg)
+		cfg.RouteClient, _ = routev1.NewForConfig(restCfg)
+		cfg.NetworkingClient, _ = networkingv1.NewForConfig(restCfg)
+		cfg.Discover = DiscoverOptions{Enabled: true, Namespaces: tc.namespaces}
+		cfg.BackstageURL = tc.backstageURL
+		out := &bytes.Buffer{}
+		err := cfg.DiscoverURLs(context.Background(), out)
+		apiServer.Close()
+		if util.GetExitCode(err) != int(tc.exitCode) {
+			t.Errorf("%s: expected exit code %d but got %d: %v", tc.name, tc.exitCode, util.GetExitCode(err), err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		common.AssertEqual(t, tc.expectedBkst, cfg.BackstageURL)
+		common.AssertEqual(t, tc.expectedMR, cfg.StoreURL)
+		common.AssertEqual(t, tc.expectedOut, out.String())
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+// EntityOptions control the Backstage entities new-model generates beyond the Components, Resources, and APIs of each
+// model, how they are named, and who owns them
+type EntityOptions struct {
+	// Systems adds a System entity for each group of models, the namespace of a KServe InferenceService or the serving
+	// environment of a Kubeflow Model Registry inference service, and sets the spec.system of their entities
+	Systems bool
+	// Domain, when set, adds a Domain entity with that name, which the Systems are part of
+	Domain string
+	// Namespace, when set, is the Backstage namespace of the generated entities
+	Namespace string
+	// NameTemplates holds a Go template per entity kind, 'component', 'resource', or 'api', which names the entities of
+	// that kind instead of the populators
+	NameTemplates map[string]string
+	// OnCollision is what happens when a name template gives the entities of two models the same name: 'error' or
+	// 'suffix'
+	OnCollision string
+	// VerifyOwner checks that each owner set on the entities is a User or Group in the Backstage catalog
+	VerifyOwner bool
+	// OwnerLabel, when set, is the label of the namespace of a KServe InferenceService whose value names the owner of
+	// its entities
+	OwnerLabel string
+	// OwnerProperty, when set, is the custom property of a Kubeflow Model Registry registered model whose value names
+	// the owner of its entities
+	OwnerProperty string
+	// OwnerMap maps the values of OwnerLabel or OwnerProperty to owners; values which are not mapped are the owner
+	// themselves, with a default kind of group
+	OwnerMap map[string]string
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	SecretRefKind    = "secret"
+	ConfigMapRefKind = "configmap"
+)
+
+// KeyRef identifies a single key of a Secret or ConfigMap
+type KeyRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Key       string
+}
+
+func (r *KeyRef) String() string {
+	return fmt.Sprintf("%s %s/%s key %s", r.Kind, r.Namespace, r.Name, r.Key)
+}
+
+// ParseKeyRef parses references of the form '[namespace/]name[:key]', using defaultNamespace and defaultKey for the
+// parts which are not specified
+func ParseKeyRef(kind, ref, defaultNamespace, defaultKey string) (*KeyRef, error) {
+	r := &KeyRef{Kind: kind, Namespace: defaultNamespace, Name: ref, Key: defaultKey}
+	if i := strings.LastIndex(r.Name, ":"); i >= 0 {
+		r.Key = r.Name[i+1:]
+		r.Name = r.Name[:i]
+	}
+	if i := strings.Index(r.Name, "/"); i >= 0 {
+		r.Namespace = r.Name[:i]
+		r.Name = r.Name[i+1:]
+	}
+	if len(r.Namespace) == 0 || len(r.Name) == 0 || len(r.Key) == 0 || strings.Contains(r.Name, "/") {
+		return nil, util.NewUsageError("invalid %s reference %q; the format is '[namespace/]name[:key]'", kind, ref)
+	}
+	return r, nil
+}
+
+// ParseTypedKeyRef parses references of the form '<secret|configmap>:[namespace/]name[:key]'
+func ParseTypedKeyRef(ref, defaultNamespace, defaultKey string) (*KeyRef, error) {
+	i := strings.Index(ref, ":")
+	if i < 0 {
+		return nil, util.NewUsageError("invalid reference %q; the format is '<secret|configmap>:[namespace/]name[:key]'", ref)
+	}
+	kind := strings.ToLower(ref[:i])
+	switch kind {
+	case SecretRefKind, ConfigMapRefKind:
+		return ParseKeyRef(kind, ref[i+1:], defaultNamespace, defaultKey)
+	default:
+		return nil, util.NewUsageError("invalid reference %q; only secret and configmap references are supported", ref)
+	}
+}
+
+// ReadKeyRef returns the content of the Secret or ConfigMap key identified by ref
+func (c *Config) ReadKeyRef(ctx context.Context, ref *KeyRef) ([]byte, error) {
+	client, err := c.GetCoreClient()
+	if err != nil {
+		return nil, util.NewKubeError(err)
+	}
+	switch ref.Kind {
+	case SecretRefKind:
+		secret, err := client.Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, util.NewKubeError(fmt.Errorf("reading %s: %w", ref.String(), err))
+		}
+		if data, ok := secret.Data[ref.Key]; ok {
+			return data, nil
+		}
+	case ConfigMapRefKind:
+		cm, err := client.ConfigMaps(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, util.NewKubeError(fmt.Errorf("reading %s: %w", ref.String(), err))
+		}
+		if data, ok := cm.Data[ref.Key]; ok {
+			return []byte(data), nil
+		}
+		if data, ok := cm.BinaryData[ref.Key]; ok {
+			return data, nil
+		}
+	}
+	return nil, util.NewNotFoundError("%s not found", ref.String())
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	DEFAULT_REQUEST_TIMEOUT = 30 * time.Second
+	DEFAULT_RETRIES         = 3
+	DEFAULT_RETRY_WAIT      = 500 * time.Millisecond
+	DEFAULT_RETRY_MAX_WAIT  = 10 * time.Second
+)
+
+// RequestOptions are the timeout and retry policy for the REST clients used to reach Backstage and the model
+// metadata stores
+type RequestOptions struct {
+	// Timeout bounds each attempt of a request; 0 means no timeout
+	Timeout time.Duration
+	// Retries is the number of times a failed request is retried; 0 disables retries
+	Retries int
+	// RetryWait and RetryMaxWait are the bounds of the exponential backoff, with jitter, between attempts; a
+	// Retry-After header from the server is honored up to RetryMaxWait
+	RetryWait    time.Duration
+	RetryMaxWait time.Duration
+}
+
+func NewRequestOptions() RequestOptions {
+	return RequestOptions{
+		Timeout:      DEFAULT_REQUEST_TIMEOUT,
+		Retries:      DEFAULT_RETRIES,
+		RetryWait:    DEFAULT_RETRY_WAIT,
+		RetryMaxWait: DEFAULT_RETRY_MAX_WAIT,
+	}
+}
+
+// Apply sets the timeout and retry policy on client; it replaces, rather than adds to, any previous policy so it can
+// be applied to the same client more than once
+func (o RequestOptions) Apply(client *resty.Client) {
+	client.SetTimeout(o.Timeout)
+	client.SetRetryCount(o.Retries)
+	client.SetRetryWaitTime(o.RetryWait)
+	client.SetRetryMaxWaitTime(o.RetryMaxWait)
+	client.SetRetryAfter(RetryAfter)
+	client.RetryConditions = []resty.RetryConditionFunc{Retryable}
+}
+
+// Retryable returns whether a request should be retried.  Idempotent requests are retried for network failures and
+// timeouts, or for 429 and 5xx responses.  Other requests, like importing a Location, are only retried for the 429,
+// 502, and 503 responses where the backend has not acted on the request.
+func Retryable(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	rc := resp.StatusCode()
+	if !idempotent(resp.Request.Method) {
+		return err == nil && (rc == http.StatusTooManyRequests || rc == http.StatusBadGateway || rc == http.StatusServiceUnavailable)
+	}
+	if err != nil {
+		return transient(err)
+	}
+	return rc == http.StatusTooManyRequests || rc >= http.StatusInternalServerError
+}
+
+// transient returns whether err, from sending a request, is a network failure or timeout worth retrying, as opposed
+// to a problem with the request itself, like an invalid URL
+func transient(err error) bool {
+	// url.Error, which wraps every error from the http.Client, implements net.Error itself, so the error it wraps is
+	// what is checked
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// RetryAfter returns the wait requested by the Retry-After header of resp, in either of its delay-seconds or HTTP-date
+// forms, or 0 to use the exponential backoff when there is no such header
+func RetryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	value := resp.Header().Get("Retry-After")
+	if len(value) == 0 {
+		return 0, nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, nil
+		}
+	}
+	return 0, nil
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
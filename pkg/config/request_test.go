@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestRequestOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		method     string
+		responses  []int
+		retryAfter string
+		delay      time.Duration
+		cancel     bool
+		badURL     bool
+		expectedRC int
+		attempts   int
+		expectErr  bool
+	}{
+		{name: "get retried on 503", method: http.MethodGet, responses: []int{503, 502, 200}, expectedRC: 200, attempts: 3},
+		{name: "get retries exhausted", method: http.MethodGet, responses: []int{500, 500, 500, 500, 500}, expectedRC: 500, attempts: 4},
+		{name: "get not retried on 404", method: http.MethodGet, responses: []int{404, 200}, expectedRC: 404, attempts: 1},
+		{name: "get retried on 429 with retry-after", method: http.MethodGet, responses: []int{429, 200}, retryAfter: "1", expectedRC: 200, attempts: 2},
+		{name: "post retried on 503", method: http.MethodPost, responses: []int{503, 201}, expectedRC: 201, attempts: 2},
+		{name: "post not retried on 500", method: http.MethodPost, responses: []int{500, 201}, expectedRC: 500, attempts: 1},
+		{name: "get timeout retried", method: http.MethodGet, responses: []int{200, 200, 200, 200}, delay: 200 * time.Millisecond, attempts: 4, expectErr: true},
+		{name: "invalid url not retried", method: http.MethodGet, responses: []int{200}, badURL: true, attempts: 0, expectErr: true},
+		{name: "cancelled get not retried", method: http.MethodGet, responses: []int{503, 503}, cancel: true, attempts: 0, expectErr: true},
+	} {
+		// timed out attempts are still being handled when the next one arrives
+		var attempts int32
+		ts := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+			rc := tc.responses[atomic.AddInt32(&attempts, 1)-1]
+			time.Sleep(tc.delay)
+			if len(tc.retryAfter) > 0 {
+				w.Header().Set("Retry-After", tc.retryAfter)
+			}
+			w.WriteHeader(rc)
+		})
+		opts := NewRequestOptions()
+		opts.RetryWait = time.Millisecond
+		// also caps the wait requested by Retry-After so the test stays fast
+		opts.RetryMaxWait = 10 * time.Millisecond
+		if tc.delay > 0 {
+			opts.Timeout = tc.delay / 4
+		}
+		client := resty.New()
+		opts.Apply(client)
+		// applying again must not change the policy
+		opts.Apply(client)
+		ctx, cancel := context.WithCancel(context.Background())
+		if tc.cancel {
+			cancel()
+		}
+		u := ts.URL
+		if tc.badURL {
+			u = "foo"
+		}
+		resp, err := client.R().SetContext(ctx).Execute(tc.method, u)
+		cancel()
+		ts.Close()
+		switch {
+		case tc.expectErr && err == nil:
+			t.Errorf("%s: expected an error", tc.name)
+		case tc.cancel && !errors.Is(err, context.Canceled):
+			t.Errorf("%s: expected a cancelled error but got %v", tc.name, err)
+		case !tc.expectErr && err != nil:
+			t.Errorf("%s: unexpected error: %s", tc.name, err.Error())
+		case !tc.expectErr:
+			common.AssertEqual(t, tc.expectedRC, resp.StatusCode())
+		}
+		common.AssertEqual(t, tc.attempts, int(atomic.LoadInt32(&attempts)))
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected time.Duration
+	}{
+		{value: "", expected: 0},
+		{value: "3", expected: 3 * time.Second},
+		{value: "soon", expected: 0},
+		{value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), expected: 0},
+	} {
+		resp := &resty.Response{RawResponse: &http.Response{Header: http.Header{}}}
+		if len(tc.value) > 0 {
+			resp.RawResponse.Header.Set("Retry-After", tc.value)
+		}
+		got, err := RetryAfter(nil, resp)
+		common.AssertError(t, err)
+		common.AssertEqual(t, tc.expected, got)
+	}
+	// an HTTP-date in the future waits until then
+	resp := &resty.Response{RawResponse: &http.Response{Header: http.Header{"Retry-After": []string{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}}}}
+	got, _ := RetryAfter(nil, resp)
+	if got <= 50*time.Second || got > time.Minute {
+		t.Errorf("expected a wait of about a minute but got %s", got)
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// RHDH_CA_FILE is where the bridge looks for the CA of the Backstage instance when running alongside RHDH; we
+	// continue to trust it when building our own TLS settings
+	RHDH_CA_FILE = "/opt/app-root/src/dynamic-plugins-root/ca.crt"
+
+	// DEFAULT_CA_KEY is the key used for CA references when no key is specified, and matches the key used by
+	// the kube-root-ca.crt ConfigMap and service account token Secrets
+	DEFAULT_CA_KEY = "ca.crt"
+)
+
+// TLSOptions are the settings for verifying, and authenticating to, a backend over TLS
+type TLSOptions struct {
+	// CAFile is a PEM encoded CA bundle to trust in addition to the system roots
+	CAFile string
+	// CARef is a '<secret|configmap>:[namespace/]name[:key]' reference to a PEM encoded CA bundle
+	CARef string
+	// CertFile and KeyFile are the PEM encoded client certificate and key used for mutual TLS
+	CertFile string
+	KeyFile  string
+
+	// TLSConfig is built from the above settings by ResolveTLS, and is nil when none of them are set
+	TLSConfig *tls.Config
+}
+
+func (o *TLSOptions) IsSet() bool {
+	return len(o.CAFile) > 0 || len(o.CARef) > 0 || len(o.CertFile) > 0 || len(o.KeyFile) > 0
+}
+
+// ResolveTLS builds the TLS settings for the Backstage and model metadata store connections from their TLSOptions,
+// reading any referenced files, Secrets, and ConfigMaps
+func (c *Config) ResolveTLS(ctx context.Context) error {
+	var err error
+	c.BackstageTLS.TLSConfig, err = c.buildTLSConfig(ctx, &c.BackstageTLS, c.BackstageSkipTLS, RHDH_CA_FILE)
+	if err != nil {
+		return err
+	}
+	c.StoreTLS.TLSConfig, err = c.buildTLSConfig(ctx, &c.StoreTLS, c.StoreSkipTLS)
+	return err
+}
+
+func (c *Config) buildTLSConfig(ctx context.Context, opts *TLSOptions, skipTLS bool, defaultCAFiles ...string) (*tls.Config, error) {
+	if !opts.IsSet() {
+		return nil, nil
+	}
+	tlsCfg := &tls.Config{}
+
+	if len(opts.CAFile) > 0 || len(opts.CARef) > 0 {
+		rootCAs, _ := x509.SystemCertPool()
+		if rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		for _, f := range defaultCAFiles {
+			if certs, err := os.ReadFile(f); err == nil {
+				rootCAs.AppendCertsFromPEM(certs)
+			}
+		}
+		if len(opts.CAFile) > 0 {
+			certs, err := os.ReadFile(opts.CAFile)
+			if err != nil {
+				return nil, util.NewValidationError("reading CA file %s: %s", opts.CAFile, err.Error())
+			}
+			if !rootCAs.AppendCertsFromPEM(certs) {
+				return nil, util.NewValidationError("no PEM encoded certificates found in CA file %s", opts.CAFile)
+			}
+		}
+		if len(opts.CARef) > 0 {
+			ref, err := ParseTypedKeyRef(opts.CARef, c.Namespace, DEFAULT_CA_KEY)
+			if err != nil {
+				return nil, err
+			}
+			var certs []byte
+			certs, err = c.ReadKeyRef(ctx, ref)
+			if err != nil {
+				return nil, err
+			}
+			if !rootCAs.AppendCertsFromPEM(certs) {
+				return nil, util.NewValidationError("no PEM encoded certificates found in %s", ref.String())
+			}
+		}
+		tlsCfg.RootCAs = rootCAs
+	} else if skipTLS {
+		// only client certificates were provided, so honor the skip TLS setting for verifying the server
+		klog.V(4).Infof("skipping verification of the server certificate")
+		tlsCfg.InsecureSkipVerify = true
+	}
+
+	if len(opts.CertFile) > 0 || len(opts.KeyFile) > 0 {
+		if len(opts.CertFile) == 0 || len(opts.KeyFile) == 0 {
+			return nil, util.NewUsageError("both a client certificate and key are required for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, util.NewValidationError("loading client certificate %s and key %s: %s", opts.CertFile, opts.KeyFile, err.Error())
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
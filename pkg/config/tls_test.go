@@ -0,0 +1,161 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestParseTypedKeyRef(t *testing.T) {
+	for _, tc := range []struct {
+		ref      string
+		expected *KeyRef
+		errorStr string
+	}{
+		{ref: "secret:my-ca", expected: &KeyRef{Kind: SecretRefKind, Namespace: "current", Name: "my-ca", Key: DEFAULT_CA_KEY}},
+		{ref: "configmap:rhdh/my-ca:service-ca.crt", expected: &KeyRef{Kind: ConfigMapRefKind, Namespace: "rhdh", Name: "my-ca", Key: "service-ca.crt"}},
+		{ref: "ConfigMap:my-ca:bundle", expected: &KeyRef{Kind: ConfigMapRefKind, Namespace: "current", Name: "my-ca", Key: "bundle"}},
+		{ref: "my-ca", errorStr: "the format is '<secret|configmap>:[namespace/]name[:key]'"},
+		{ref: "route:my-ca", errorStr: "only secret and configmap references are supported"},
+		{ref: "secret:a/b/c", errorStr: "the format is '[namespace/]name[:key]'"},
+		{ref: "secret:my-ca:", errorStr: "the format is '[namespace/]name[:key]'"},
+	} {
+		got, err := ParseTypedKeyRef(tc.ref, "current", DEFAULT_CA_KEY)
+		switch {
+		case len(tc.errorStr) > 0 && (err == nil || !strings.Contains(err.Error(), tc.errorStr)):
+			t.Errorf("%s: expected error containing %q but got %v", tc.ref, tc.errorStr, err)
+		case len(tc.errorStr) > 0 && util.GetExitCode(err) != int(util.ExitUsage):
+			t.Errorf("%s: expected a usage error but got exit code %d", tc.ref, util.GetExitCode(err))
+		case len(tc.errorStr) == 0 && err != nil:
+			t.Errorf("%s: unexpected error: %s", tc.ref, err.Error())
+		case len(tc.errorStr) == 0:
+			common.AssertEqual(t, tc.expected, got)
+		}
+	}
+}
+
+func TestResolveTLS(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: backend.Certificate().Raw}))
+	caJSON, _ := json.Marshal(caPEM)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caFile, []byte(caPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+	notPEM := filepath.Join(dir, "not-pem.crt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// a stand in for the Kubernetes API server that serves the CA from a ConfigMap
+	apiServer := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/namespaces/rhdh/configmaps/backstage-ca":
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"backstage-ca","namespace":"rhdh"},"data":{"ca.crt":` + string(caJSON) + `}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"NotFound","code":404}`))
+		}
+	})
+	defer apiServer.Close()
+	coreClient, err := corev1.NewForConfig(&rest.Config{Host: apiServer.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		opts     TLSOptions
+		skipTLS  bool
+		exitCode util.ExitCode
+	}{
+		{
+			name: "nothing set leaves the bridge settings alone",
+		},
+		{
+			name: "ca file",
+			opts: TLSOptions{CAFile: caFile},
+		},
+		{
+			name:    "ca file wins over skip tls",
+			opts:    TLSOptions{CAFile: caFile},
+			skipTLS: true,
+		},
+		{
+			name: "ca configmap ref",
+			opts: TLSOptions{CARef: "configmap:rhdh/backstage-ca"},
+		},
+		{
+			name:     "missing ca secret ref",
+			opts:     TLSOptions{CARef: "secret:rhdh/backstage-ca"},
+			exitCode: util.ExitNotFound,
+		},
+		{
+			name:     "missing ca key",
+			opts:     TLSOptions{CARef: "configmap:rhdh/backstage-ca:other.crt"},
+			exitCode: util.ExitNotFound,
+		},
+		{
+			name:     "ca file missing",
+			opts:     TLSOptions{CAFile: filepath.Join(dir, "missing.crt")},
+			exitCode: util.ExitValidation,
+		},
+		{
+			name:     "ca file not pem",
+			opts:     TLSOptions{CAFile: notPEM},
+			exitCode: util.ExitValidation,
+		},
+		{
+			name:     "client cert without key",
+			opts:     TLSOptions{CertFile: caFile},
+			exitCode: util.ExitUsage,
+		},
+	} {
+		cfg := NewConfig()
+		cfg.CoreClient = coreClient
+		cfg.BackstageSkipTLS = tc.skipTLS
+		cfg.BackstageTLS = tc.opts
+		err := cfg.ResolveTLS(context.Background())
+		if util.GetExitCode(err) != int(tc.exitCode) {
+			t.Errorf("%s: expected exit code %d but got %d: %v", tc.name, tc.exitCode, util.GetExitCode(err), err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		tlsCfg := cfg.BackstageTLS.TLSConfig
+		if !tc.opts.IsSet() {
+			if tlsCfg != nil {
+				t.Errorf("%s: no TLS config expected", tc.name)
+			}
+			continue
+		}
+		if tlsCfg.InsecureSkipVerify {
+			t.Errorf("%s: server verification should not be skipped when a CA is provided", tc.name)
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}
+		resp, err := client.Get(backend.URL)
+		if err != nil {
+			t.Errorf("%s: unexpected error accessing the TLS backend: %s", tc.name, err.Error())
+			continue
+		}
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	brdgutil "github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/auth"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+)
+
+// DEFAULT_TOKEN_KEY is the key used for token Secret references when no key is specified, and matches the key used
+// by service account token Secrets
+const DEFAULT_TOKEN_KEY = "token"
+
+var errNoKubeconfigToken = errors.New("the current kubeconfig context does not have a bearer token; log in with 'oc login' first")
+
+// TokenOptions are the alternatives to providing a bearer token directly on the command line
+type TokenOptions struct {
+	// Secret is a '[namespace/]name[:key]' reference to a Secret containing the token
+	Secret string
+	// FromKubeconfig uses the bearer token of the current kubeconfig context, i.e. the logged in OpenShift user
+	FromKubeconfig bool
+}
+
+// ResolveTokens sets the Backstage and model metadata store tokens from their TokenOptions, which take precedence
+// over the tokens set directly by flag or environment variable
+func (c *Config) ResolveTokens(ctx context.Context) error {
+	token, err := c.resolveToken(ctx, &c.BackstageTokenOpts, "backstage")
+	if err != nil {
+		return err
+	}
+	if len(token) > 0 {
+		c.BackstageToken = token
+	}
+	token, err = c.resolveToken(ctx, &c.StoreTokenOpts, "model-metadata")
+	if err != nil {
+		return err
+	}
+	if len(token) > 0 {
+		c.StoreToken = token
+	}
+	return nil
+}
+
+func (c *Config) resolveToken(ctx context.Context, opts *TokenOptions, flagPrefix string) (string, error) {
+	switch {
+	case len(opts.Secret) > 0 && opts.FromKubeconfig:
+		return "", util.NewUsageError("only one of --%s-token-secret and --%s-token-from-kubeconfig can be set", flagPrefix, flagPrefix)
+	case len(opts.Secret) > 0:
+		ref, err := ParseKeyRef(SecretRefKind, opts.Secret, c.Namespace, DEFAULT_TOKEN_KEY)
+		if err != nil {
+			return "", err
+		}
+		var buf []byte
+		buf, err = c.ReadKeyRef(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+		token := strings.TrimSpace(string(buf))
+		if len(token) == 0 {
+			return "", util.NewValidationError("%s is empty", ref.String())
+		}
+		return token, nil
+	case opts.FromKubeconfig:
+		restCfg, err := brdgutil.GetK8sConfig(c.Config)
+		if err != nil {
+			return "", util.NewError(util.ExitAuth, err)
+		}
+		token := strings.TrimSpace(brdgutil.GetCurrentToken(restCfg))
+		if len(token) == 0 {
+			return "", util.NewError(util.ExitAuth, errNoKubeconfigToken)
+		}
+		return token, nil
+	}
+	return "", nil
+}
+
+// ResolveBackstageAuth builds the BackstageTokenSource for the auth mode in BackstageAuth; the CA bundle and client
+// certificate for Backstage, when provided, are also used to reach the OIDC identity provider
+func (c *Config) ResolveBackstageAuth(ctx context.Context) error {
+	if c.BackstageTLS.TLSConfig != nil && c.BackstageAuth.HTTPClient == nil {
+		c.BackstageAuth.HTTPClient = &http.Client{Transport: &http.Transport{TLSClientConfig: c.BackstageTLS.TLSConfig, Proxy: http.ProxyFromEnvironment}}
+	}
+	var err error
+	c.BackstageTokenSource, err = auth.NewTokenSource(ctx, c.BackstageToken, c.BackstageAuth)
+	return err
+}
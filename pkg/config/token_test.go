@@ -0,0 +1,153 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"github.com/redhat-ai-dev/rhdh-ai-catalog-cli/pkg/util"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: %s
+current-context: test
+users:
+- name: with-token
+  user:
+    token: sha256~logged-in-user
+- name: without-token
+  user: {}
+`
+
+func TestResolveTokens(t *testing.T) {
+	// a stand in for the Kubernetes API server that serves the tokens from Secrets
+	apiServer := common.CreateTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		secret := func(name, key, value string) {
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"` + name + `","namespace":"rhdh"},"data":{"` + key + `":"` + base64.StdEncoding.EncodeToString([]byte(value)) + `"}}`))
+		}
+		switch r.URL.Path {
+		case "/api/v1/namespaces/rhdh/secrets/backstage-token":
+			secret("backstage-token", "token", "backstage-secret-token\n")
+		case "/api/v1/namespaces/rhdh/secrets/registry-token":
+			secret("registry-token", "access-token", "registry-secret-token")
+		case "/api/v1/namespaces/rhdh/secrets/empty-token":
+			secret("empty-token", "token", " \n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"NotFound","code":404}`))
+		}
+	})
+	defer apiServer.Close()
+	coreClient, err := corev1.NewForConfig(&rest.Config{Host: apiServer.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	withToken := filepath.Join(dir, "with-token")
+	withoutToken := filepath.Join(dir, "without-token")
+	for file, user := range map[string]string{withToken: "with-token", withoutToken: "without-token"} {
+		if err = os.WriteFile(file, []byte(fmt.Sprintf(testKubeconfig, user)), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name           string
+		kubeconfig     string
+		backstageOpts  TokenOptions
+		storeOpts      TokenOptions
+		backstageToken string
+		storeToken     string
+		exitCode       util.ExitCode
+	}{
+		{
+			name:           "nothing set leaves the flag tokens alone",
+			backstageToken: "flag-token",
+			storeToken:     "flag-token",
+		},
+		{
+			name:           "secrets with the default namespace and key, and an explicit key",
+			backstageOpts:  TokenOptions{Secret: "backstage-token"},
+			storeOpts:      TokenOptions{Secret: "rhdh/registry-token:access-token"},
+			backstageToken: "backstage-secret-token",
+			storeToken:     "registry-secret-token",
+		},
+		{
+			name:           "kubeconfig token",
+			kubeconfig:     withToken,
+			storeOpts:      TokenOptions{FromKubeconfig: true},
+			backstageToken: "flag-token",
+			storeToken:     "sha256~logged-in-user",
+		},
+		{
+			name:       "kubeconfig without a token",
+			kubeconfig: withoutToken,
+			storeOpts:  TokenOptions{FromKubeconfig: true},
+			exitCode:   util.ExitAuth,
+		},
+		{
+			name:      "secret and kubeconfig are mutually exclusive",
+			storeOpts: TokenOptions{Secret: "registry-token", FromKubeconfig: true},
+			exitCode:  util.ExitUsage,
+		},
+		{
+			name:          "missing secret",
+			backstageOpts: TokenOptions{Secret: "rhdh/missing"},
+			exitCode:      util.ExitNotFound,
+		},
+		{
+			name:          "missing key",
+			backstageOpts: TokenOptions{Secret: "backstage-token:other"},
+			exitCode:      util.ExitNotFound,
+		},
+		{
+			name:          "empty token",
+			backstageOpts: TokenOptions{Secret: "empty-token"},
+			exitCode:      util.ExitValidation,
+		},
+		{
+			name:          "bad reference",
+			backstageOpts: TokenOptions{Secret: "a/b/c"},
+			exitCode:      util.ExitUsage,
+		},
+	} {
+		// keep the bridge from falling back to the environment when no token is in the kubeconfig
+		t.Setenv("K8S_TOKEN", "")
+		cfg := NewConfig()
+		cfg.CoreClient = coreClient
+		cfg.Namespace = "rhdh"
+		cfg.Kubeconfig = tc.kubeconfig
+		cfg.BackstageToken = "flag-token"
+		cfg.StoreToken = "flag-token"
+		cfg.BackstageTokenOpts = tc.backstageOpts
+		cfg.StoreTokenOpts = tc.storeOpts
+		err := cfg.ResolveTokens(context.Background())
+		if util.GetExitCode(err) != int(tc.exitCode) {
+			t.Errorf("%s: expected exit code %d but got %d: %v", tc.name, tc.exitCode, util.GetExitCode(err), err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		common.AssertEqual(t, tc.backstageToken, cfg.BackstageToken)
+		common.AssertEqual(t, tc.storeToken, cfg.StoreToken)
+	}
+}
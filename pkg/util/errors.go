@@ -0,0 +1,145 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ExitCode is the documented process return code for each category of error
+type ExitCode int
+
+const (
+	ExitOK ExitCode = iota
+	// ExitError is returned for errors which do not fall into one of the categories below
+	ExitError
+	// ExitUsage is returned for missing or invalid arguments and flags
+	ExitUsage
+	// ExitAuth is returned when a backend rejects the credentials provided
+	ExitAuth
+	// ExitNotFound is returned when a requested item does not exist in a backend
+	ExitNotFound
+	// ExitConflict is returned when a backend reports a conflict with an existing item
+	ExitConflict
+	// ExitUnavailable is returned when a backend cannot be reached or reports it is unavailable
+	ExitUnavailable
+	// ExitValidation is returned when a backend or the CLI rejects the content provided
+	ExitValidation
+
+	// ExitInterrupted is returned when the command is cancelled, i.e. with Ctrl-C, following the shell convention of
+	// 128 plus the SIGINT signal number
+	ExitInterrupted ExitCode = 130
+)
+
+// Error associates an error with the ExitCode the CLI should return for it
+type Error struct {
+	Code ExitCode
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError wraps err with code, leaving err untouched if it already has an ExitCode associated with it
+func NewError(code ExitCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &Error{Code: code, Err: err}
+}
+
+func NewUsageError(format string, args ...interface{}) error {
+	return &Error{Code: ExitUsage, Err: fmt.Errorf(format, args...)}
+}
+
+func NewValidationError(format string, args ...interface{}) error {
+	return &Error{Code: ExitValidation, Err: fmt.Errorf(format, args...)}
+}
+
+func NewNotFoundError(format string, args ...interface{}) error {
+	return &Error{Code: ExitNotFound, Err: fmt.Errorf(format, args...)}
+}
+
+// NewHTTPError wraps err with the ExitCode corresponding to the HTTP status code rc returned by a backend
+func NewHTTPError(rc int, err error) error {
+	switch {
+	case rc == http.StatusUnauthorized || rc == http.StatusForbidden:
+		return NewError(ExitAuth, err)
+	case rc == http.StatusNotFound:
+		return NewError(ExitNotFound, err)
+	case rc == http.StatusConflict:
+		return NewError(ExitConflict, err)
+	case rc == http.StatusBadRequest || rc == http.StatusUnprocessableEntity:
+		return NewError(ExitValidation, err)
+	case rc == http.StatusRequestTimeout || rc == http.StatusTooManyRequests || rc >= 500:
+		return NewError(ExitUnavailable, err)
+	default:
+		return NewError(ExitError, err)
+	}
+}
+
+// NewKubeError wraps err with the ExitCode corresponding to the Kubernetes API status reason of err
+func NewKubeError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err):
+		return NewError(ExitAuth, err)
+	case apierrors.IsNotFound(err):
+		return NewError(ExitNotFound, err)
+	case apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err):
+		return NewError(ExitConflict, err)
+	case apierrors.IsInvalid(err) || apierrors.IsBadRequest(err):
+		return NewError(ExitValidation, err)
+	case apierrors.IsServiceUnavailable(err) || apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) ||
+		apierrors.IsTooManyRequests(err) || apierrors.IsInternalError(err):
+		return NewError(ExitUnavailable, err)
+	default:
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			return NewError(ExitUnavailable, err)
+		}
+		return NewError(ExitError, err)
+	}
+}
+
+// NewTransportError wraps an error from sending a request to a backend, where no response was received, with
+// ExitUnavailable
+func NewTransportError(err error) error {
+	return NewError(ExitUnavailable, err)
+}
+
+// IsNotFound returns whether err is associated with ExitNotFound
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == ExitNotFound
+}
+
+// GetExitCode returns the process return code for err
+func GetExitCode(err error) int {
+	if err == nil {
+		return int(ExitOK)
+	}
+	// cancellation takes precedence, as the errors from requests aborted by it are typed as transport errors
+	if errors.Is(err, context.Canceled) {
+		return int(ExitInterrupted)
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return int(e.Code)
+	}
+	return int(ExitError)
+}
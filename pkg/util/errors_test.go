@@ -0,0 +1,45 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestGetExitCode(t *testing.T) {
+	gr := schema.GroupResource{Group: "serving.kserve.io", Resource: "inferenceservices"}
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected ExitCode
+	}{
+		{name: "nil", err: nil, expected: ExitOK},
+		{name: "untyped", err: errors.New("boom"), expected: ExitError},
+		{name: "usage", err: NewUsageError("bad %s", "arg"), expected: ExitUsage},
+		{name: "wrapped usage", err: fmt.Errorf("context: %w", NewUsageError("bad arg")), expected: ExitUsage},
+		{name: "http 401", err: NewHTTPError(http.StatusUnauthorized, errors.New("401")), expected: ExitAuth},
+		{name: "http 403", err: NewHTTPError(http.StatusForbidden, errors.New("403")), expected: ExitAuth},
+		{name: "http 404", err: NewHTTPError(http.StatusNotFound, errors.New("404")), expected: ExitNotFound},
+		{name: "http 409", err: NewHTTPError(http.StatusConflict, errors.New("409")), expected: ExitConflict},
+		{name: "http 400", err: NewHTTPError(http.StatusBadRequest, errors.New("400")), expected: ExitValidation},
+		{name: "http 503", err: NewHTTPError(http.StatusServiceUnavailable, errors.New("503")), expected: ExitUnavailable},
+		{name: "http 429", err: NewHTTPError(http.StatusTooManyRequests, errors.New("429")), expected: ExitUnavailable},
+		{name: "http 418", err: NewHTTPError(http.StatusTeapot, errors.New("418")), expected: ExitError},
+		{name: "transport", err: NewTransportError(errors.New("connection refused")), expected: ExitUnavailable},
+		{name: "kube not found", err: NewKubeError(fmt.Errorf("get: %w", apierrors.NewNotFound(gr, "foo"))), expected: ExitNotFound},
+		{name: "kube forbidden", err: NewKubeError(apierrors.NewForbidden(gr, "foo", errors.New("no"))), expected: ExitAuth},
+		{name: "kube already exists", err: NewKubeError(apierrors.NewAlreadyExists(gr, "foo")), expected: ExitConflict},
+		{name: "kube unavailable", err: NewKubeError(apierrors.NewServiceUnavailable("down")), expected: ExitUnavailable},
+		{name: "interrupted", err: NewTransportError(fmt.Errorf("get: %w", context.Canceled)), expected: ExitInterrupted},
+		{name: "first code wins", err: NewError(ExitError, NewNotFoundError("missing")), expected: ExitNotFound},
+	} {
+		if got := GetExitCode(tc.err); got != int(tc.expected) {
+			t.Errorf("%s: expected exit code %d but got %d", tc.name, tc.expected, got)
+		}
+	}
+}